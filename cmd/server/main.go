@@ -4,18 +4,30 @@ import (
 	"context"
 	"log"
 
+	"github.com/johnroshan2255/core-service/internal/auth"
+	authmodels "github.com/johnroshan2255/core-service/internal/auth/models"
+	authrepos "github.com/johnroshan2255/core-service/internal/auth/repos"
+	"github.com/johnroshan2255/core-service/internal/certs"
 	"github.com/johnroshan2255/core-service/internal/config"
 	"github.com/johnroshan2255/core-service/internal/database"
+	"github.com/johnroshan2255/core-service/internal/idempotency"
+	documentmodels "github.com/johnroshan2255/core-service/internal/document/models"
 	documentrepos "github.com/johnroshan2255/core-service/internal/document/repos"
 	documentservice "github.com/johnroshan2255/core-service/internal/document/service"
 	documentscheduler "github.com/johnroshan2255/core-service/internal/document/scheduler"
+	"github.com/johnroshan2255/core-service/internal/invoice"
 	"github.com/johnroshan2255/core-service/internal/middleware"
 	"github.com/johnroshan2255/core-service/internal/notification"
+	notificationmodels "github.com/johnroshan2255/core-service/internal/notification/models"
+	notificationrepos "github.com/johnroshan2255/core-service/internal/notification/repos"
+	"github.com/johnroshan2255/core-service/internal/tenant"
 	grpctransport "github.com/johnroshan2255/core-service/internal/transport/grpc/notification"
 	httptransport "github.com/johnroshan2255/core-service/internal/transport/http"
+	usermodels "github.com/johnroshan2255/core-service/internal/user/models"
 	userrepos "github.com/johnroshan2255/core-service/internal/user/repos"
 	userservice "github.com/johnroshan2255/core-service/internal/user/service"
 	"github.com/joho/godotenv"
+	"gorm.io/gorm"
 )
 
 func main() {
@@ -25,55 +37,254 @@ func main() {
 
 	cfg := config.LoadConfig()
 
-	provider := cfg.NotificationProvider
-	if provider == "" {
-		provider = "email"
+	var db *gorm.DB
+	if cfg.DBUrl != "" {
+		var err error
+		db, err = database.InitDB(cfg)
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer database.CloseDB(db)
+
+		// Migrations and backfills span every tenant
+		migrateDB := db.WithContext(tenant.Unscoped(context.Background()))
+		if err := database.PrepareTenantColumn(migrateDB); err != nil {
+			log.Fatalf("Failed to prepare users.tenant_id: %v", err)
+		}
+		if err := database.AutoMigrate(migrateDB,
+			&notificationmodels.NotificationPreference{},
+			&notificationmodels.ChannelPreference{},
+			&notificationmodels.OutboxMessage{},
+			&notificationmodels.DeliveryAttempt{},
+			&notificationmodels.InAppNotification{},
+			&notificationmodels.EmailSuppression{},
+			&notificationmodels.ScheduledNotification{},
+			&documentmodels.Document{},
+			&documentmodels.CalendarFeed{},
+			&authmodels.RefreshToken{},
+			&authmodels.Session{},
+			&authmodels.AccessToken{},
+			&authmodels.ActionToken{},
+			&authmodels.TOTPFactor{},
+			&authmodels.RecoveryCode{},
+			&usermodels.User{},
+			&usermodels.CompanyDetails{},
+			&usermodels.PaymentDetails{},
+			&usermodels.PaymentHistory{},
+			&invoice.Invoice{},
+			&idempotency.Record{},
+		); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+		if err := invoice.MigrateAmountCents(migrateDB); err != nil {
+			log.Fatalf("Failed to migrate invoice amounts: %v", err)
+		}
+		if err := database.BackfillTenantIDs(migrateDB,
+			&notificationmodels.NotificationPreference{},
+			&notificationmodels.ChannelPreference{},
+			&notificationmodels.OutboxMessage{},
+			&notificationmodels.DeliveryAttempt{},
+			&notificationmodels.InAppNotification{},
+			&notificationmodels.ScheduledNotification{},
+			&documentmodels.Document{},
+			&documentmodels.CalendarFeed{},
+			&usermodels.CompanyDetails{},
+			&usermodels.PaymentDetails{},
+			&usermodels.PaymentHistory{},
+			&invoice.Invoice{},
+		); err != nil {
+			log.Fatalf("Failed to backfill tenants: %v", err)
+		}
+	} else {
+		log.Printf("Warning: DBUrl not set. User and Document services will not be available.")
 	}
-	notificationFactory, err := notification.NewFactory(provider)
+
+	notificationFactory, err := notification.NewFactory(notification.FactoryConfig{
+		EmailProviders:   cfg.NotificationProviders,
+		Channels:         cfg.NotificationChannels,
+		SMSProviders:     cfg.NotificationSMSProviders,
+		WebhookProviders: cfg.NotificationWebhookProviders,
+		SMTP: notification.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		},
+		Twilio: notification.TwilioConfig{
+			AccountSID: cfg.TwilioAccountSID,
+			AuthToken:  cfg.TwilioAuthToken,
+			From:       cfg.TwilioFrom,
+			BaseURL:    cfg.TwilioBaseURL,
+		},
+		Breaker: notification.BreakerConfig{
+			Threshold: cfg.NotificationBreakerThreshold,
+			Cooldown:  cfg.NotificationBreakerCooldown,
+		},
+	})
 	if err != nil {
 		log.Fatalf("Failed to create notification factory: %v", err)
 	}
-	notificationService := notificationFactory.NewService()
+
+	var notificationRepo notificationrepos.Repository
+	if db != nil {
+		notificationRepo = notificationrepos.NewGORMRepository(db)
+	}
+	notificationService := notificationFactory.NewService(notificationRepo)
+	if db != nil {
+		notificationService.SetTenantResolver(userrepos.NewGORMRepository(db))
+
+		// Stream subscribers may be connected to any instance
+		relayCtx, stopRelay := context.WithCancel(context.Background())
+		defer stopRelay()
+		notificationService.RelayEvents(relayCtx, notification.NewPostgresRelay(db, cfg.DBUrl))
+	}
+	notificationService.SetMaxAttempts(cfg.NotificationMaxAttempts)
+	notificationService.SetMaxAttachmentBytes(int64(cfg.MaxAttachmentBytes))
+	notificationService.SetUnsubscribe(cfg.UnsubscribeSecret, cfg.PublicBaseURL)
+	if cfg.UnsubscribeSecret == "" || cfg.PublicBaseURL == "" {
+		log.Printf("Warning: UNSUBSCRIBE_SECRET or PUBLIC_BASE_URL not set. Emails will not include unsubscribe links.")
+	}
+
+	if cfg.EventSchemasFile != "" {
+		if err := notificationService.Schemas().LoadFile(cfg.EventSchemasFile); err != nil {
+			log.Fatalf("Failed to load notification event schemas: %v", err)
+		}
+	}
+
+	outboxWorker := notification.NewOutboxWorker(notificationService, cfg.NotificationOutboxWorkers)
+	outboxWorker.Start(context.Background())
+	defer outboxWorker.Stop()
+
+	scheduledDispatcher := notification.NewScheduledDispatcher(notificationService)
+	scheduledDispatcher.Start(context.Background())
+	defer scheduledDispatcher.Stop()
 
 	middleware.SetJWTKey(cfg.JWTKey)
-	if cfg.JWTKey == "" {
+	middleware.SetJWTValidation(cfg.JWTIssuer, cfg.JWTAudience, cfg.JWTClockSkew)
+	if cfg.JWKSSource != "" {
+		jwks, err := middleware.NewJWKS(cfg.JWKSSource, cfg.JWKSCacheTTL)
+		if err != nil {
+			log.Fatalf("Failed to load JWKS: %v", err)
+		}
+		middleware.SetJWKS(jwks)
+	}
+	if cfg.JWTKey == "" && cfg.JWKSSource == "" {
 		log.Printf("Warning: JWT key not set. JWT authentication will not be available.")
 	}
 
+	policy := auth.DefaultPolicy()
+	if cfg.AuthzPolicyFile != "" {
+		policy, err = auth.LoadPolicyFile(cfg.AuthzPolicyFile)
+		if err != nil {
+			log.Fatalf("Failed to load authorization policy: %v", err)
+		}
+	}
+	middleware.SetPermissionChecker(policy)
+
+	var notificationClient *grpctransport.Client
+	if cfg.CoreNotificationServiceAddr != "" {
+		clientConfig := grpctransport.ClientConfig{
+			CallTimeout:      cfg.NotificationClientTimeout,
+			RetryMaxAttempts: cfg.NotificationClientRetries,
+			KeepaliveTime:    cfg.NotificationClientKeepalive,
+		}
+		if cfg.TLSEnabled {
+			// Present this service's client certificate so the notification server can identify it
+			reloader, err := certs.NewReloader(cfg.TLSClientCertFile, cfg.TLSClientKeyFile, cfg.TLSCAFile)
+			if err != nil {
+				log.Fatalf("Failed to load TLS certificates for the notification client: %v", err)
+			}
+			clientConfig.TLS = reloader.ClientConfig(cfg.TLSServerName)
+		}
+		clientFactory := grpctransport.NewClientFactory(cfg.CoreNotificationServiceAddr, cfg.ServiceKey, cfg.TLSEnabled, clientConfig)
+		notificationClient, err = grpctransport.NewClient(clientFactory, context.Background())
+		if err != nil {
+			log.Printf("Warning: Failed to create notification client: %v", err)
+		} else {
+			defer notificationClient.Close()
+		}
+	} else {
+		log.Printf("Warning: CORE_NOTIFICATION_SERVICE_ADDR not set. Scheduled expiry notifications will be disabled.")
+	}
+
 	var userService *userservice.Service
 	var documentService *documentservice.Service
 	var expiryScheduler *documentscheduler.ExpiryScheduler
+	var authService *auth.Service
+	var invoiceService *invoice.Service
 
-	if cfg.DBUrl != "" {
-		db, err := database.InitDB(cfg)
+	if db != nil {
+		userRepo := userrepos.NewGORMRepository(db)
+		userService = userservice.NewService(userRepo)
+
+		tokenIssuer, err := auth.NewTokenIssuer(auth.TokenConfig{
+			Key:       cfg.JWTKey,
+			Issuer:    cfg.JWTIssuer,
+			Audience:  cfg.JWTAudience,
+			AccessTTL: cfg.AccessTokenTTL,
+		})
 		if err != nil {
-			log.Fatalf("Failed to connect to database: %v", err)
+			log.Printf("Warning: %v. Auth endpoints will not be available.", err)
+		} else {
+			authRepo := authrepos.NewGORMRepository(db)
+			sessionCache := auth.NewSessionCache(authRepo, cfg.SessionCacheTTL)
+			authService = auth.NewService(userRepo, authRepo, tokenIssuer, cfg.RefreshTokenTTL)
+			authService.SetNotifier(notificationService)
+			authService.SetSessionCache(sessionCache)
+			authService.SetLinks(auth.LinkConfig{
+				PasswordResetURL:     cfg.PasswordResetURL,
+				EmailVerificationURL: cfg.EmailVerificationURL,
+				PasswordResetTTL:     cfg.PasswordResetTTL,
+				EmailVerificationTTL: cfg.EmailVerificationTTL,
+			})
+			if cfg.PasswordResetURL == "" || cfg.EmailVerificationURL == "" {
+				log.Printf("Warning: PASSWORD_RESET_URL or EMAIL_VERIFICATION_URL not set. Password reset or email verification will not be available.")
+			}
+			if err := authService.SetMFA(auth.MFAConfig{
+				Issuer:        cfg.MFAIssuer,
+				EncryptionKey: cfg.MFAEncryptionKey,
+			}); err != nil {
+				log.Printf("Warning: %v. Two-factor authentication will not be available.", err)
+			}
+			middleware.SetSessionChecker(sessionCache)
+			middleware.SetRoleResolver(auth.NewRoleCache(userRepo, cfg.RoleCacheTTL))
 		}
-		defer database.CloseDB(db)
 
-		userRepo := userrepos.NewGORMRepository(db)
-		userService = userservice.NewService(userRepo)
+		invoiceService = invoice.NewService(invoice.NewGORMRepository(db))
 
 		documentRepo := documentrepos.NewGORMRepository(db)
 		documentService = documentservice.NewService(documentRepo)
+		documentService.SetStatusPublisher(notificationService)
 
 		daysBeforeExpiry := 30
-		expiryScheduler = documentscheduler.NewExpiryScheduler(documentService, cfg, daysBeforeExpiry)
+		expiryScheduler = documentscheduler.NewExpiryScheduler(documentService, notificationClient, cfg, daysBeforeExpiry)
 		expiryScheduler.Start(context.Background())
 		defer expiryScheduler.Stop()
-		defer expiryScheduler.Close()
-	} else {
-		log.Printf("Warning: DBUrl not set. User and Document services will not be available.")
+	}
+
+	var idempotencyStore middleware.IdempotencyStore
+	if db != nil {
+		idempotencyStore = idempotency.NewGORMRepository(db)
+	}
+
+	// HTTP service routes and the gRPC server accept the same backend credentials
+	serviceCredentials, err := middleware.LoadServiceCredentials(cfg.ServiceCredentialsFile, cfg.ServiceKey)
+	if err != nil {
+		log.Fatalf("Failed to load service credentials: %v", err)
 	}
 
 	go func() {
-		grpctransport.StartGRPCServer(cfg, notificationService)
+		grpctransport.StartGRPCServer(cfg, notificationService, serviceCredentials, idempotencyStore)
 	}()
 
 	services := &httptransport.Services{
 		NotificationService: notificationService,
 		UserService:         userService,
 		DocumentService:     documentService,
+		AuthService:         authService,
+		InvoiceService:      invoiceService,
+		ServiceCredentials:  serviceCredentials,
 	}
 
 	httptransport.StartHTTPServer(cfg, services)
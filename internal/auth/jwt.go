@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/johnroshan2255/core-service/internal/middleware"
+	"github.com/johnroshan2255/core-service/internal/user/models"
+)
+
+const defaultAccessTokenTTL = 15 * time.Minute
+
+// TokenConfig configures access token issuance. Key, Issuer and Audience must match
+// what AuthMiddleware is configured to accept.
+type TokenConfig struct {
+	Key       string
+	Issuer    string
+	Audience  []string
+	AccessTTL time.Duration
+}
+
+// TokenIssuer signs HS256 access tokens carrying middleware.Claims
+type TokenIssuer struct {
+	key       []byte
+	issuer    string
+	audience  []string
+	accessTTL time.Duration
+}
+
+func NewTokenIssuer(config TokenConfig) (*TokenIssuer, error) {
+	if config.Key == "" {
+		return nil, fmt.Errorf("JWT key is required to issue tokens")
+	}
+	if config.AccessTTL <= 0 {
+		config.AccessTTL = defaultAccessTokenTTL
+	}
+
+	return &TokenIssuer{
+		key:       []byte(config.Key),
+		issuer:    config.Issuer,
+		audience:  config.Audience,
+		accessTTL: config.AccessTTL,
+	}, nil
+}
+
+// IssueAccessToken returns a signed access token for user in the given session, its
+// jti and its expiry. amr lists how the user authenticated (RFC 8176), e.g. pwd, otp
+// and mfa.
+func (t *TokenIssuer) IssueAccessToken(user *models.User, sessionID string, amr []string) (string, string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(t.accessTTL)
+
+	jti, err := randomID()
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+
+	claims := &middleware.Claims{
+		UserUUID:      user.UUID,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
+		Role:          user.Role,
+		TenantID:      user.TenantID,
+		SessionID:     sessionID,
+		AMR:           amr,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   user.UUID,
+			Issuer:    t.issuer,
+			Audience:  t.audience,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(t.key)
+	if err != nil {
+		return "", "", time.Time{}, fmt.Errorf("failed to sign access token: %w", err)
+	}
+	return signed, jti, expiresAt, nil
+}
+
+func randomID() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate token ID: %w", err)
+	}
+	return hex.EncodeToString(raw), nil
+}
@@ -0,0 +1,481 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/johnroshan2255/core-service/internal/auth/models"
+	usermodels "github.com/johnroshan2255/core-service/internal/user/models"
+	"gorm.io/gorm"
+)
+
+// Authentication method references (RFC 8176) placed in the amr claim
+const (
+	AMRPassword = "pwd"
+	AMROTP      = "otp"
+	AMRMFA      = "mfa"
+)
+
+const (
+	mfaChallengeTTL = 5 * time.Minute
+	// maxMFAAttempts wrong codes in a row lock the user's factor for mfaLockout, which
+	// doubles with every further round of failures up to mfaMaxLockout
+	maxMFAAttempts    = 5
+	mfaLockout        = 15 * time.Minute
+	mfaMaxLockout     = 24 * time.Hour
+	recoveryCodeCount = 10
+	defaultMFAIssuer  = "Core Service"
+)
+
+var (
+	ErrMFANotConfigured  = errors.New("multi-factor authentication is not configured")
+	ErrMFAAlreadyEnabled = errors.New("multi-factor authentication is already enabled")
+	ErrMFANotEnabled     = errors.New("multi-factor authentication is not enabled")
+	ErrMFANotEnrolled    = errors.New("no pending TOTP enrollment; start enrollment first")
+	ErrInvalidMFACode    = errors.New("invalid verification code")
+	ErrInvalidMFAToken   = errors.New("invalid or expired MFA token; sign in again")
+	ErrMFACodeRequired   = errors.New("a TOTP code or recovery code is required")
+	ErrMFALocked         = errors.New("too many invalid verification codes; try again later")
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// MFAConfig configures TOTP. Issuer is the account name shown in authenticator apps;
+// EncryptionKey encrypts TOTP secrets at rest and must not change once users enroll.
+type MFAConfig struct {
+	Issuer        string
+	EncryptionKey string
+}
+
+// TOTPEnrollment is returned when a user starts enrolling an authenticator app
+type TOTPEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// MFAStatus describes a user's second factor
+type MFAStatus struct {
+	Enabled                bool  `json:"enabled"`
+	PendingEnrollment      bool  `json:"pending_enrollment"`
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+}
+
+func (s *Service) SetMFA(config MFAConfig) error {
+	secrets, err := newSecretBox(config.EncryptionKey)
+	if err != nil {
+		return err
+	}
+	if config.Issuer == "" {
+		config.Issuer = defaultMFAIssuer
+	}
+	s.mfaIssuer = config.Issuer
+	s.secrets = secrets
+	return nil
+}
+
+// EnrollTOTP starts enrolling an authenticator app. The returned URI is rendered as a
+// QR code by the client. MFA is only enabled once ConfirmTOTP accepts a code.
+func (s *Service) EnrollTOTP(ctx context.Context, userUUID string) (*TOTPEnrollment, error) {
+	if s.secrets == nil {
+		return nil, ErrMFANotConfigured
+	}
+
+	user, err := s.getUser(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	factor, err := s.repo.GetTOTPFactor(ctx, userUUID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to get TOTP factor: %w", err)
+	}
+	if factor != nil && factor.ConfirmedAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.secrets.seal(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SaveTOTPFactor(ctx, &models.TOTPFactor{
+		UserUUID:        userUUID,
+		EncryptedSecret: sealed,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to save TOTP factor: %w", err)
+	}
+
+	log.Printf("AuthService: Started TOTP enrollment for user %s", userUUID)
+	return &TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totpProvisioningURI(s.mfaIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP finishes enrollment with a code from the authenticator app, enabling
+// MFA. It returns the user's recovery codes, which are only ever shown this once.
+func (s *Service) ConfirmTOTP(ctx context.Context, userUUID, code string) ([]string, error) {
+	if s.secrets == nil {
+		return nil, ErrMFANotConfigured
+	}
+
+	factor, err := s.repo.GetTOTPFactor(ctx, userUUID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrMFANotEnrolled
+		}
+		return nil, fmt.Errorf("failed to get TOTP factor: %w", err)
+	}
+	if factor.ConfirmedAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := s.secrets.open(factor.EncryptedSecret)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := validateTOTP(secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	now := time.Now()
+	factor.ConfirmedAt = &now
+	factor.LastUsedStep = step
+	if err := s.repo.SaveTOTPFactor(ctx, factor); err != nil {
+		return nil, fmt.Errorf("failed to enable TOTP: %w", err)
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("AuthService: Enabled TOTP for user %s", userUUID)
+	s.securityNotice(ctx, userUUID, "Two-factor authentication enabled",
+		"Two-factor authentication was turned on for your account. If this wasn't you, contact support immediately.")
+	return codes, nil
+}
+
+// DisableTOTP turns MFA off after checking a current TOTP or recovery code
+func (s *Service) DisableTOTP(ctx context.Context, userUUID, code, recoveryCode string) error {
+	if _, err := s.verifySecondFactor(ctx, userUUID, code, recoveryCode); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteTOTPFactor(ctx, userUUID); err != nil {
+		return fmt.Errorf("failed to disable TOTP: %w", err)
+	}
+
+	log.Printf("AuthService: Disabled TOTP for user %s", userUUID)
+	s.securityNotice(ctx, userUUID, "Two-factor authentication disabled",
+		"Two-factor authentication was turned off for your account. If this wasn't you, change your password and contact support immediately.")
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after checking a TOTP code
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userUUID, code string) ([]string, error) {
+	if _, err := s.verifySecondFactor(ctx, userUUID, code, ""); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("AuthService: Regenerated recovery codes for user %s", userUUID)
+	return codes, nil
+}
+
+func (s *Service) GetMFAStatus(ctx context.Context, userUUID string) (*MFAStatus, error) {
+	if userUUID == "" {
+		return nil, fmt.Errorf("user UUID is required")
+	}
+
+	factor, err := s.repo.GetTOTPFactor(ctx, userUUID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &MFAStatus{}, nil
+		}
+		return nil, fmt.Errorf("failed to get TOTP factor: %w", err)
+	}
+
+	status := &MFAStatus{
+		Enabled:           factor.ConfirmedAt != nil,
+		PendingEnrollment: factor.ConfirmedAt == nil,
+	}
+	if status.Enabled {
+		remaining, err := s.repo.CountRecoveryCodes(ctx, userUUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count recovery codes: %w", err)
+		}
+		status.RecoveryCodesRemaining = remaining
+	}
+	return status, nil
+}
+
+// CompleteMFALogin finishes a login started by Login using the challenge token and
+// either a TOTP code or a recovery code. After a wrong code the returned result holds
+// a fresh challenge token. Wrong codes are counted per user, not per challenge, so
+// signing in again doesn't reset the limit.
+func (s *Service) CompleteMFALogin(ctx context.Context, mfaToken, code, recoveryCode string, client ClientInfo) (*LoginResult, error) {
+	if mfaToken == "" {
+		return nil, ErrInvalidMFAToken
+	}
+	if code == "" && recoveryCode == "" {
+		return nil, ErrMFACodeRequired
+	}
+
+	challenge, err := s.repo.ConsumeActionToken(ctx, models.TokenPurposeMFAChallenge, hashToken(mfaToken))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrInvalidMFAToken
+		}
+		return nil, fmt.Errorf("failed to check MFA token: %w", err)
+	}
+
+	user, err := s.users.GetByUUID(ctx, challenge.UserUUID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrInvalidMFAToken
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	amr, err := s.verifySecondFactor(ctx, user.UUID, code, recoveryCode)
+	if err != nil {
+		if err != ErrInvalidMFACode {
+			return nil, err
+		}
+		retry, retryErr := s.startMFAChallenge(ctx, user)
+		if retryErr != nil {
+			return nil, retryErr
+		}
+		return retry, ErrInvalidMFACode
+	}
+
+	if recoveryCode != "" {
+		remaining, _ := s.repo.CountRecoveryCodes(ctx, user.UUID)
+		s.securityNotice(ctx, user.UUID, "Recovery code used",
+			fmt.Sprintf("A recovery code was used to sign in to your account. You have %d recovery codes left. If this wasn't you, change your password immediately.", remaining))
+	}
+
+	tokens, err := s.issueTokens(ctx, user, "", amr, client)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{Tokens: tokens}, nil
+}
+
+// mfaEnabled reports whether the user has a confirmed TOTP factor
+func (s *Service) mfaEnabled(ctx context.Context, userUUID string) (bool, error) {
+	factor, err := s.repo.GetTOTPFactor(ctx, userUUID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get TOTP factor: %w", err)
+	}
+	if factor.ConfirmedAt == nil {
+		return false, nil
+	}
+	// Refuse to fall back to password-only login if the key was removed from config
+	if s.secrets == nil {
+		return false, ErrMFANotConfigured
+	}
+	return true, nil
+}
+
+// startMFAChallenge issues the short-lived token that links the password step of a
+// login to its TOTP step
+func (s *Service) startMFAChallenge(ctx context.Context, user *usermodels.User) (*LoginResult, error) {
+	token, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(mfaChallengeTTL)
+	if err := s.repo.CreateActionToken(ctx, &models.ActionToken{
+		UserUUID:  user.UUID,
+		Purpose:   models.TokenPurposeMFAChallenge,
+		Email:     user.Email,
+		TokenHash: hashToken(token),
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to save MFA token: %w", err)
+	}
+
+	return &LoginResult{
+		MFARequired:       true,
+		MFAToken:          token,
+		MFATokenExpiresAt: &expiresAt,
+	}, nil
+}
+
+// verifySecondFactor checks a TOTP code, or a recovery code when no TOTP code is given,
+// and returns the amr values the check satisfies
+func (s *Service) verifySecondFactor(ctx context.Context, userUUID, code, recoveryCode string) ([]string, error) {
+	if s.secrets == nil {
+		return nil, ErrMFANotConfigured
+	}
+	if code == "" && recoveryCode == "" {
+		return nil, ErrMFACodeRequired
+	}
+
+	factor, err := s.repo.GetTOTPFactor(ctx, userUUID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrMFANotEnabled
+		}
+		return nil, fmt.Errorf("failed to get TOTP factor: %w", err)
+	}
+	if factor.ConfirmedAt == nil {
+		return nil, ErrMFANotEnabled
+	}
+	if factor.LockedUntil != nil && time.Now().Before(*factor.LockedUntil) {
+		return nil, ErrMFALocked
+	}
+
+	amr, err := s.checkSecondFactor(ctx, factor, code, recoveryCode)
+	if err == ErrInvalidMFACode {
+		return nil, s.recordMFAFailure(ctx, factor)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if factor.FailedAttempts > 0 {
+		if err := s.repo.ResetMFAFailures(ctx, factor.ID); err != nil {
+			log.Printf("AuthService: Failed to reset MFA failures for user %s: %v", userUUID, err)
+		}
+	}
+	return amr, nil
+}
+
+// checkSecondFactor checks a code against the user's factor or recovery codes
+func (s *Service) checkSecondFactor(ctx context.Context, factor *models.TOTPFactor, code, recoveryCode string) ([]string, error) {
+	userUUID := factor.UserUUID
+	if code == "" {
+		used, err := s.repo.UseRecoveryCode(ctx, userUUID, hashToken(normalizeRecoveryCode(recoveryCode)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to check recovery code: %w", err)
+		}
+		if !used {
+			return nil, ErrInvalidMFACode
+		}
+		return []string{AMRPassword, AMRMFA}, nil
+	}
+
+	secret, err := s.secrets.open(factor.EncryptedSecret)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := validateTOTP(secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+	fresh, err := s.repo.MarkTOTPStepUsed(ctx, factor.ID, step)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record TOTP code: %w", err)
+	}
+	if !fresh {
+		// The code (or a later one) was already accepted
+		return nil, ErrInvalidMFACode
+	}
+	return []string{AMRPassword, AMROTP, AMRMFA}, nil
+}
+
+// recordMFAFailure counts a wrong code and locks the factor once the user reaches the
+// limit. It returns ErrMFALocked when the factor was locked, otherwise ErrInvalidMFACode.
+func (s *Service) recordMFAFailure(ctx context.Context, factor *models.TOTPFactor) error {
+	failures, err := s.repo.RecordMFAFailure(ctx, factor.ID)
+	if err != nil {
+		return fmt.Errorf("failed to record MFA failure: %w", err)
+	}
+	log.Printf("AuthService: Invalid MFA code for user %s (%d in a row)", factor.UserUUID, failures)
+	if failures%maxMFAAttempts != 0 {
+		return ErrInvalidMFACode
+	}
+
+	lockout := mfaMaxLockout
+	if rounds := failures / maxMFAAttempts; rounds <= 10 {
+		lockout = min(mfaLockout<<(rounds-1), mfaMaxLockout)
+	}
+	if err := s.repo.LockTOTPFactor(ctx, factor.ID, time.Now().Add(lockout)); err != nil {
+		return fmt.Errorf("failed to lock TOTP factor: %w", err)
+	}
+	log.Printf("AuthService: Locked TOTP for user %s for %s after %d invalid codes", factor.UserUUID, lockout, failures)
+	s.securityNotice(ctx, factor.UserUUID, "Two-factor authentication locked",
+		fmt.Sprintf("Too many invalid verification codes were entered for your account, so two-factor sign-in is locked for %s. If this wasn't you, change your password.", lockout))
+	return ErrMFALocked
+}
+
+func (s *Service) replaceRecoveryCodes(ctx context.Context, userUUID string) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	records := make([]models.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		encoded := strings.ToLower(recoveryCodeEncoding.EncodeToString(raw))
+		codes[i] = encoded[:4] + "-" + encoded[4:]
+		records[i] = models.RecoveryCode{
+			UserUUID: userUUID,
+			CodeHash: hashToken(normalizeRecoveryCode(codes[i])),
+		}
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userUUID, records); err != nil {
+		return nil, fmt.Errorf("failed to save recovery codes: %w", err)
+	}
+	return codes, nil
+}
+
+func (s *Service) securityNotice(ctx context.Context, userUUID, subject, message string) {
+	if s.notifier == nil {
+		return
+	}
+	user, err := s.users.GetByUUID(ctx, userUUID)
+	if err != nil {
+		return
+	}
+	if err := s.notifier.NotifySecurityNotice(ctx, user.UUID, user.Email, subject, message); err != nil {
+		log.Printf("AuthService: Failed to send security notice to user %s: %v", user.UUID, err)
+	}
+}
+
+func (s *Service) getUser(ctx context.Context, userUUID string) (*usermodels.User, error) {
+	if userUUID == "" {
+		return nil, fmt.Errorf("user UUID is required")
+	}
+	user, err := s.users.GetByUUID(ctx, userUUID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+// normalizeRecoveryCode lets users type codes without the dash or in upper case
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// splitAMR parses the space-separated amr list stored on a session
+func splitAMR(value string) []string {
+	return strings.Fields(value)
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// AccessToken is one issued access token, keyed by its jti claim, so it can be revoked
+// before it expires. Revoking a session revokes every access token issued in it.
+type AccessToken struct {
+	JTI       string     `gorm:"type:varchar(64);primaryKey;column:jti" json:"jti"`
+	SessionID string     `gorm:"type:varchar(64);index;not null" json:"session_id"`
+	UserUUID  string     `gorm:"type:uuid;index;not null" json:"user_uuid"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// TokenPurpose says which flow an action token belongs to
+type TokenPurpose string
+
+const (
+	TokenPurposePasswordReset TokenPurpose = "password_reset"
+	TokenPurposeEmailVerify   TokenPurpose = "email_verification"
+	// TokenPurposeMFAChallenge links the TOTP step of a login to its password step
+	TokenPurposeMFAChallenge TokenPurpose = "mfa_challenge"
+)
+
+// ActionToken is a single-use, expiring token emailed to a user to reset their
+// password or verify their address, or handed out mid-login while the user enters
+// their TOTP code. Only its hash is stored.
+type ActionToken struct {
+	ID        uint         `gorm:"primaryKey" json:"id"`
+	UserUUID  string       `gorm:"type:uuid;index;not null" json:"user_uuid"`
+	Purpose   TokenPurpose `gorm:"type:varchar(30);not null" json:"purpose"`
+	Email     string       `gorm:"type:varchar(255);not null" json:"email"`
+	TokenHash string       `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time    `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time   `json:"used_at,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+// TOTPFactor is a user's authenticator app enrollment. The secret is stored encrypted.
+// A factor only counts towards login once the user has confirmed it with a valid code.
+type TOTPFactor struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	UserUUID        string     `gorm:"type:uuid;uniqueIndex;not null" json:"user_uuid"`
+	EncryptedSecret string     `gorm:"type:text;not null" json:"-"`
+	ConfirmedAt     *time.Time `json:"confirmed_at,omitempty"`
+	// LastUsedStep is the TOTP time step of the last accepted code, so a code can't be replayed
+	LastUsedStep int64 `gorm:"not null;default:0" json:"-"`
+	// FailedAttempts counts wrong codes since the last accepted one, across all logins;
+	// every maxMFAAttempts of them lock the factor until LockedUntil
+	FailedAttempts int        `gorm:"not null;default:0" json:"-"`
+	LockedUntil    *time.Time `json:"locked_until,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// RecoveryCode is a single-use code that stands in for a TOTP code when the user has
+// lost their authenticator. Only its hash is stored.
+type RecoveryCode struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserUUID  string     `gorm:"type:uuid;index;not null" json:"user_uuid"`
+	CodeHash  string     `gorm:"type:varchar(64);not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// RefreshToken is one issued refresh token. Only its hash is stored. Tokens produced
+// by rotating each other share a FamilyID, so reuse of a rotated token can revoke the
+// whole chain.
+type RefreshToken struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserUUID  string     `gorm:"type:uuid;index;not null" json:"user_uuid"`
+	FamilyID  string     `gorm:"type:varchar(64);index;not null" json:"family_id"`
+	TokenHash string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	RotatedAt *time.Time `json:"rotated_at,omitempty"`
+	RevokedAt *time.Time `gorm:"index" json:"revoked_at,omitempty"`
+	UserAgent string     `gorm:"type:varchar(255)" json:"user_agent"`
+	IPAddress string     `gorm:"type:varchar(45)" json:"ip_address"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// Session is one signed-in device. It starts at login and lives as long as its refresh
+// token family. Access tokens name it in their session_id claim, and revoking the
+// session revokes the AccessToken records issued in it, cutting them off before they
+// expire.
+type Session struct {
+	ID         string     `gorm:"type:varchar(64);primaryKey" json:"id"`
+	UserUUID   string     `gorm:"type:uuid;index;not null" json:"user_uuid"`
+	UserAgent  string     `gorm:"type:varchar(255)" json:"user_agent"`
+	IPAddress  string     `gorm:"type:varchar(45)" json:"ip_address"`
+	AMR        string     `gorm:"type:varchar(100)" json:"amr"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt  *time.Time `gorm:"index" json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Roles stored in User.Role
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Permissions checked by middleware.RequirePermission. A policy may grant "*" for every
+// permission or "<resource>:*" for every permission on a resource.
+const (
+	PermissionUsersRead        = "users:read"
+	PermissionDocumentsReadAny = "documents:read_any"
+	PermissionInvoicesRead     = "invoices:read"
+	PermissionInvoicesWrite    = "invoices:write"
+	PermissionMetricsRead      = "metrics:read"
+)
+
+// Policy maps role names to the permissions they grant. It is loaded from a JSON file
+// shaped like {"roles": {"support": ["users:read", "documents:read_any"]}}.
+type Policy struct {
+	Roles map[string][]string `json:"roles"`
+}
+
+// DefaultPolicy grants admins everything and regular users no extra permissions
+func DefaultPolicy() *Policy {
+	return &Policy{
+		Roles: map[string][]string{
+			RoleAdmin: {"*"},
+			RoleUser:  {},
+		},
+	}
+}
+
+// LoadPolicyFile reads a policy from a JSON file. Roles it doesn't mention get no
+// permissions, including admin, so the file must list every role that needs access.
+func LoadPolicyFile(path string) (*Policy, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read authorization policy: %w", err)
+	}
+
+	var policy Policy
+	if err := json.Unmarshal(content, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse authorization policy: %w", err)
+	}
+	if len(policy.Roles) == 0 {
+		return nil, fmt.Errorf("authorization policy defines no roles")
+	}
+	for role, permissions := range policy.Roles {
+		for _, permission := range permissions {
+			if permission == "" || (strings.Contains(permission, "*") && permission != "*" && !strings.HasSuffix(permission, ":*")) {
+				return nil, fmt.Errorf("role %s: invalid permission %q", role, permission)
+			}
+		}
+	}
+	return &policy, nil
+}
+
+// HasPermission implements middleware.PermissionChecker
+func (p *Policy) HasPermission(role, permission string) bool {
+	resource, _, _ := strings.Cut(permission, ":")
+	for _, granted := range p.Roles[role] {
+		if granted == "*" || granted == permission || granted == resource+":*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,243 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/johnroshan2255/core-service/internal/auth/models"
+	usermodels "github.com/johnroshan2255/core-service/internal/user/models"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPasswordResetTTL     = time.Hour
+	defaultEmailVerificationTTL = 48 * time.Hour
+)
+
+var (
+	ErrInvalidActionToken   = errors.New("invalid or expired token")
+	ErrEmailAlreadyVerified = errors.New("email is already verified")
+	ErrLinksNotConfigured   = errors.New("account email links are not configured")
+)
+
+// LinkConfig sets where emailed links point and how long they stay valid. The URLs
+// are pages that read the token query parameter and post it to the confirm endpoints.
+type LinkConfig struct {
+	PasswordResetURL     string
+	EmailVerificationURL string
+	PasswordResetTTL     time.Duration
+	EmailVerificationTTL time.Duration
+}
+
+func (s *Service) SetLinks(config LinkConfig) {
+	if config.PasswordResetTTL <= 0 {
+		config.PasswordResetTTL = defaultPasswordResetTTL
+	}
+	if config.EmailVerificationTTL <= 0 {
+		config.EmailVerificationTTL = defaultEmailVerificationTTL
+	}
+	s.links = config
+}
+
+// RequestPasswordReset emails a reset link if the address belongs to an account. It
+// succeeds either way so callers can't use it to find out which emails are registered.
+func (s *Service) RequestPasswordReset(ctx context.Context, email string) error {
+	if s.links.PasswordResetURL == "" || s.notifier == nil {
+		return ErrLinksNotConfigured
+	}
+
+	user, err := s.users.GetByEmail(ctx, normalizeEmail(email))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	token, err := s.createActionToken(ctx, user, models.TokenPurposePasswordReset, s.links.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	link, err := buildLink(s.links.PasswordResetURL, token)
+	if err != nil {
+		return err
+	}
+	if err := s.notifier.NotifyPasswordReset(ctx, user.UUID, user.Email, link, s.links.PasswordResetTTL); err != nil {
+		return fmt.Errorf("failed to send password reset email: %w", err)
+	}
+
+	log.Printf("AuthService: Sent password reset link to user %s", user.UUID)
+	return nil
+}
+
+// ResetPassword sets a new password using an emailed reset token and signs the user
+// out of every session
+func (s *Service) ResetPassword(ctx context.Context, token, password string) error {
+	if token == "" {
+		return ErrInvalidActionToken
+	}
+
+	// Hash first so a rejected password doesn't use up the token
+	passwordHash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	user, actionToken, err := s.consumeActionToken(ctx, models.TokenPurposePasswordReset, token)
+	if err != nil {
+		return err
+	}
+
+	user.PasswordHash = passwordHash
+	// Following the emailed link proves the user controls the address
+	if !user.EmailVerified && actionToken.Email == user.Email {
+		now := time.Now()
+		user.EmailVerified = true
+		user.EmailVerifiedAt = &now
+	}
+	if err := s.users.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if _, err := s.RevokeAllSessions(ctx, user.UUID, ""); err != nil {
+		log.Printf("AuthService: Failed to revoke sessions after password reset for user %s: %v", user.UUID, err)
+	}
+
+	log.Printf("AuthService: Reset password for user %s", user.UUID)
+
+	if s.notifier != nil {
+		message := "Your password was just reset and you have been signed out on all devices. If this wasn't you, contact support immediately."
+		if err := s.notifier.NotifySecurityNotice(ctx, user.UUID, user.Email, "Your password was changed", message); err != nil {
+			log.Printf("AuthService: Failed to send password change notice to user %s: %v", user.UUID, err)
+		}
+	}
+	return nil
+}
+
+// RequestEmailVerification emails the user a link to confirm their address
+func (s *Service) RequestEmailVerification(ctx context.Context, userUUID string) error {
+	if userUUID == "" {
+		return fmt.Errorf("user UUID is required")
+	}
+
+	user, err := s.users.GetByUUID(ctx, userUUID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fmt.Errorf("user not found")
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.EmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+
+	return s.sendEmailVerification(ctx, user)
+}
+
+// VerifyEmail marks the user's email as verified using an emailed token. Access tokens
+// issued before this still say email_verified=false until the client refreshes them.
+func (s *Service) VerifyEmail(ctx context.Context, token string) error {
+	if token == "" {
+		return ErrInvalidActionToken
+	}
+
+	user, actionToken, err := s.consumeActionToken(ctx, models.TokenPurposeEmailVerify, token)
+	if err != nil {
+		return err
+	}
+
+	// The token was sent to an address the user has since changed
+	if actionToken.Email != user.Email {
+		return ErrInvalidActionToken
+	}
+	if user.EmailVerified {
+		return nil
+	}
+
+	now := time.Now()
+	user.EmailVerified = true
+	user.EmailVerifiedAt = &now
+	if err := s.users.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	log.Printf("AuthService: Verified email for user %s", user.UUID)
+	return nil
+}
+
+func (s *Service) sendEmailVerification(ctx context.Context, user *usermodels.User) error {
+	if s.links.EmailVerificationURL == "" || s.notifier == nil {
+		return ErrLinksNotConfigured
+	}
+
+	token, err := s.createActionToken(ctx, user, models.TokenPurposeEmailVerify, s.links.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link, err := buildLink(s.links.EmailVerificationURL, token)
+	if err != nil {
+		return err
+	}
+	if err := s.notifier.NotifyEmailVerification(ctx, user.UUID, user.Email, link, s.links.EmailVerificationTTL); err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+
+	log.Printf("AuthService: Sent email verification link to user %s", user.UUID)
+	return nil
+}
+
+func (s *Service) createActionToken(ctx context.Context, user *usermodels.User, purpose models.TokenPurpose, ttl time.Duration) (string, error) {
+	token, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	record := &models.ActionToken{
+		UserUUID:  user.UUID,
+		Purpose:   purpose,
+		Email:     user.Email,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.repo.CreateActionToken(ctx, record); err != nil {
+		return "", fmt.Errorf("failed to save token: %w", err)
+	}
+	return token, nil
+}
+
+func (s *Service) consumeActionToken(ctx context.Context, purpose models.TokenPurpose, token string) (*usermodels.User, *models.ActionToken, error) {
+	actionToken, err := s.repo.ConsumeActionToken(ctx, purpose, hashToken(token))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, ErrInvalidActionToken
+		}
+		return nil, nil, fmt.Errorf("failed to check token: %w", err)
+	}
+
+	user, err := s.users.GetByUUID(ctx, actionToken.UserUUID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, ErrInvalidActionToken
+		}
+		return nil, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, actionToken, nil
+}
+
+// buildLink adds the token to base as the token query parameter
+func buildLink(base, token string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid link URL: %w", err)
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,334 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/johnroshan2255/core-service/internal/auth/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	MarkRefreshTokenRotated(ctx context.Context, id uint) (bool, error)
+
+	CreateSession(ctx context.Context, session *models.Session) error
+	GetSession(ctx context.Context, id string) (*models.Session, error)
+	TouchSession(ctx context.Context, id string, ipAddress string, expiresAt time.Time) error
+	GetActiveSessions(ctx context.Context, userUUID string) ([]models.Session, error)
+	RevokeSession(ctx context.Context, userUUID, id string) (int64, []string, error)
+	RevokeUserSessions(ctx context.Context, userUUID, exceptID string) (int, []string, error)
+
+	CreateAccessToken(ctx context.Context, token *models.AccessToken) error
+	GetAccessToken(ctx context.Context, jti string) (*models.AccessToken, error)
+
+	CreateActionToken(ctx context.Context, token *models.ActionToken) error
+	ConsumeActionToken(ctx context.Context, purpose models.TokenPurpose, tokenHash string) (*models.ActionToken, error)
+
+	GetTOTPFactor(ctx context.Context, userUUID string) (*models.TOTPFactor, error)
+	SaveTOTPFactor(ctx context.Context, factor *models.TOTPFactor) error
+	MarkTOTPStepUsed(ctx context.Context, id uint, step int64) (bool, error)
+	RecordMFAFailure(ctx context.Context, id uint) (int, error)
+	LockTOTPFactor(ctx context.Context, id uint, until time.Time) error
+	ResetMFAFailures(ctx context.Context, id uint) error
+	DeleteTOTPFactor(ctx context.Context, userUUID string) error
+
+	ReplaceRecoveryCodes(ctx context.Context, userUUID string, codes []models.RecoveryCode) error
+	UseRecoveryCode(ctx context.Context, userUUID, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userUUID string) (int64, error)
+}
+
+type GORMRepository struct {
+	db *gorm.DB
+}
+
+func NewGORMRepository(db *gorm.DB) *GORMRepository {
+	return &GORMRepository{
+		db: db,
+	}
+}
+
+func (r *GORMRepository) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *GORMRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkRefreshTokenRotated marks a token as used. It reports false when the token was
+// already rotated or revoked, so two concurrent refreshes can't both succeed.
+func (r *GORMRepository) MarkRefreshTokenRotated(ctx context.Context, id uint) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("id = ? AND rotated_at IS NULL AND revoked_at IS NULL", id).
+		Update("rotated_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *GORMRepository) CreateSession(ctx context.Context, session *models.Session) error {
+	return r.db.WithContext(ctx).Create(session).Error
+}
+
+func (r *GORMRepository) GetSession(ctx context.Context, id string) (*models.Session, error) {
+	var session models.Session
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// TouchSession records a token refresh and extends the session to the new refresh token's expiry
+func (r *GORMRepository) TouchSession(ctx context.Context, id string, ipAddress string, expiresAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.Session{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_used_at": time.Now(),
+		"ip_address":   ipAddress,
+		"expires_at":   expiresAt,
+	}).Error
+}
+
+func (r *GORMRepository) GetActiveSessions(ctx context.Context, userUUID string) ([]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.WithContext(ctx).
+		Where("user_uuid = ? AND revoked_at IS NULL AND expires_at > ?", userUUID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// RevokeSession revokes one of the user's sessions with its refresh and access tokens,
+// returning the number of sessions revoked and the jtis of the revoked access tokens
+func (r *GORMRepository) RevokeSession(ctx context.Context, userUUID, id string) (int64, []string, error) {
+	var revoked int64
+	var jtis []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.Session{}).
+			Where("id = ? AND user_uuid = ? AND revoked_at IS NULL", id, userUUID).
+			Update("revoked_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		revoked = result.RowsAffected
+		if revoked == 0 {
+			return nil
+		}
+		if err := tx.Model(&models.RefreshToken{}).
+			Where("family_id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		var err error
+		jtis, err = revokeAccessTokens(tx, []string{id}, now)
+		return err
+	})
+	return revoked, jtis, err
+}
+
+// RevokeUserSessions revokes all of the user's sessions except exceptID (which may be
+// empty) along with their refresh and access tokens, and returns the number of
+// sessions revoked and the jtis of the revoked access tokens
+func (r *GORMRepository) RevokeUserSessions(ctx context.Context, userUUID, exceptID string) (int, []string, error) {
+	var ids, jtis []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Session{}).
+			Where("user_uuid = ? AND revoked_at IS NULL AND id <> ?", userUUID, exceptID).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		if err := tx.Model(&models.Session{}).Where("id IN ?", ids).Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.RefreshToken{}).
+			Where("family_id IN ? AND revoked_at IS NULL", ids).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		var err error
+		jtis, err = revokeAccessTokens(tx, ids, now)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return len(ids), jtis, nil
+}
+
+// revokeAccessTokens revokes the unexpired access tokens of the sessions and returns their jtis
+func revokeAccessTokens(tx *gorm.DB, sessionIDs []string, now time.Time) ([]string, error) {
+	var jtis []string
+	if err := tx.Model(&models.AccessToken{}).
+		Where("session_id IN ? AND revoked_at IS NULL AND expires_at > ?", sessionIDs, now).
+		Pluck("jti", &jtis).Error; err != nil {
+		return nil, err
+	}
+	if len(jtis) == 0 {
+		return nil, nil
+	}
+	if err := tx.Model(&models.AccessToken{}).Where("jti IN ?", jtis).Update("revoked_at", now).Error; err != nil {
+		return nil, err
+	}
+	return jtis, nil
+}
+
+// CreateAccessToken records an issued access token. The session's expired access
+// tokens are deleted at the same time, since they can no longer be presented.
+func (r *GORMRepository) CreateAccessToken(ctx context.Context, token *models.AccessToken) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("session_id = ? AND expires_at < ?", token.SessionID, time.Now()).
+			Delete(&models.AccessToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+func (r *GORMRepository) GetAccessToken(ctx context.Context, jti string) (*models.AccessToken, error) {
+	var token models.AccessToken
+	if err := r.db.WithContext(ctx).Where("jti = ?", jti).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// CreateActionToken stores a new token, invalidating any unused tokens the user has
+// for the same purpose so only the most recent email works. MFA challenges are left
+// alone, so a login on one device can't cancel a login in progress on another.
+func (r *GORMRepository) CreateActionToken(ctx context.Context, token *models.ActionToken) error {
+	if token.Purpose == models.TokenPurposeMFAChallenge {
+		return r.db.WithContext(ctx).Create(token).Error
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.ActionToken{}).
+			Where("user_uuid = ? AND purpose = ? AND used_at IS NULL", token.UserUUID, token.Purpose).
+			Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+// ConsumeActionToken marks an unused, unexpired token as used and returns it. It
+// returns gorm.ErrRecordNotFound when there is no such token.
+func (r *GORMRepository) ConsumeActionToken(ctx context.Context, purpose models.TokenPurpose, tokenHash string) (*models.ActionToken, error) {
+	var token models.ActionToken
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", tokenHash, purpose, now).
+			First(&token).Error; err != nil {
+			return err
+		}
+		token.UsedAt = &now
+		return tx.Model(&token).Update("used_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *GORMRepository) GetTOTPFactor(ctx context.Context, userUUID string) (*models.TOTPFactor, error) {
+	var factor models.TOTPFactor
+	if err := r.db.WithContext(ctx).Where("user_uuid = ?", userUUID).First(&factor).Error; err != nil {
+		return nil, err
+	}
+	return &factor, nil
+}
+
+// SaveTOTPFactor creates or replaces the user's factor
+func (r *GORMRepository) SaveTOTPFactor(ctx context.Context, factor *models.TOTPFactor) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_uuid"}},
+		DoUpdates: clause.AssignmentColumns([]string{"encrypted_secret", "confirmed_at", "last_used_step", "updated_at"}),
+	}).Create(factor).Error
+}
+
+// MarkTOTPStepUsed records an accepted code's time step. It reports false when that
+// step or a later one was already used, which means the code is being replayed.
+func (r *GORMRepository) MarkTOTPStepUsed(ctx context.Context, id uint, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.TOTPFactor{}).
+		Where("id = ? AND last_used_step < ?", id, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RecordMFAFailure counts a wrong code against the factor and returns the number of
+// failures since the last accepted code
+func (r *GORMRepository) RecordMFAFailure(ctx context.Context, id uint) (int, error) {
+	factor := models.TOTPFactor{ID: id}
+	err := r.db.WithContext(ctx).Model(&factor).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "failed_attempts"}}}).
+		Update("failed_attempts", gorm.Expr("failed_attempts + 1")).Error
+	return factor.FailedAttempts, err
+}
+
+// LockTOTPFactor refuses codes for the factor until the given time
+func (r *GORMRepository) LockTOTPFactor(ctx context.Context, id uint, until time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.TOTPFactor{}).Where("id = ?", id).Update("locked_until", until).Error
+}
+
+// ResetMFAFailures clears the failure count and lock after a code is accepted
+func (r *GORMRepository) ResetMFAFailures(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Model(&models.TOTPFactor{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"failed_attempts": 0,
+		"locked_until":    nil,
+	}).Error
+}
+
+// DeleteTOTPFactor removes the user's factor and recovery codes
+func (r *GORMRepository) DeleteTOTPFactor(ctx context.Context, userUUID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_uuid = ?", userUUID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_uuid = ?", userUUID).Delete(&models.TOTPFactor{}).Error
+	})
+}
+
+func (r *GORMRepository) ReplaceRecoveryCodes(ctx context.Context, userUUID string, codes []models.RecoveryCode) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_uuid = ?", userUUID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode marks an unused code as used, reporting false if there was none
+func (r *GORMRepository) UseRecoveryCode(ctx context.Context, userUUID, codeHash string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.RecoveryCode{}).
+		Where("user_uuid = ? AND code_hash = ? AND used_at IS NULL", userUUID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *GORMRepository) CountRecoveryCodes(ctx context.Context, userUUID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.RecoveryCode{}).
+		Where("user_uuid = ? AND used_at IS NULL", userUUID).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	userrepos "github.com/johnroshan2255/core-service/internal/user/repos"
+	"gorm.io/gorm"
+)
+
+const defaultRoleCacheTTL = 30 * time.Second
+
+// RoleCache answers RequirePermission's role lookups from the users table, so a changed
+// or removed role applies without waiting for the user's access tokens to expire.
+// Roles are cached per process for the TTL (ROLE_CACHE_TTL); call Invalidate after
+// changing a role to apply it here immediately.
+type RoleCache struct {
+	users userrepos.Repository
+	ttl   time.Duration
+
+	mu      sync.Mutex
+	entries map[string]roleCacheEntry
+}
+
+type roleCacheEntry struct {
+	role      string
+	expiresAt time.Time
+}
+
+func NewRoleCache(users userrepos.Repository, ttl time.Duration) *RoleCache {
+	if ttl <= 0 {
+		ttl = defaultRoleCacheTTL
+	}
+	return &RoleCache{
+		users:   users,
+		ttl:     ttl,
+		entries: make(map[string]roleCacheEntry),
+	}
+}
+
+// Role implements middleware.RoleResolver. Users that no longer exist have no role.
+func (c *RoleCache) Role(ctx context.Context, userUUID string) (string, error) {
+	now := time.Now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[userUUID]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.role, nil
+	}
+
+	var role string
+	user, err := c.users.GetByUUID(ctx, userUUID)
+	if err == nil {
+		role = user.Role
+	} else if err != gorm.ErrRecordNotFound {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= sessionCacheMaxEntries {
+		for id, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+		if len(c.entries) >= sessionCacheMaxEntries {
+			c.entries = make(map[string]roleCacheEntry)
+		}
+	}
+	c.entries[userUUID] = roleCacheEntry{role: role, expiresAt: now.Add(c.ttl)}
+	return role, nil
+}
+
+// Invalidate drops cached roles so the next request loads them again
+func (c *RoleCache) Invalidate(userUUIDs ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range userUUIDs {
+		delete(c.entries, id)
+	}
+}
@@ -0,0 +1,524 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/johnroshan2255/core-service/internal/auth/models"
+	"github.com/johnroshan2255/core-service/internal/auth/repos"
+	"github.com/johnroshan2255/core-service/internal/tenant"
+	usermodels "github.com/johnroshan2255/core-service/internal/user/models"
+	userrepos "github.com/johnroshan2255/core-service/internal/user/repos"
+	"gorm.io/gorm"
+)
+
+const defaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+// Registration field limits, matching the users table columns
+const (
+	maxEmailLength       = 255
+	maxUsernameLength    = 50
+	maxNameLength        = 100
+	maxPhoneNumberLength = 20
+)
+
+var (
+	ErrEmailTaken          = errors.New("email is already registered")
+	ErrUsernameTaken       = errors.New("username is already taken")
+	ErrInvalidRegistration = errors.New("invalid registration")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrSessionNotFound     = errors.New("session not found")
+	// ErrRefreshTokenReused means an already rotated refresh token was presented again,
+	// which suggests it was stolen. The session it belongs to is revoked.
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+)
+
+// Notifier is told about account events so the user can be emailed
+type Notifier interface {
+	NotifyUserCreated(ctx context.Context, userUUID, email, username string) error
+	NotifySecurityNotice(ctx context.Context, userUUID, email, subject, message string) error
+	NotifyPasswordReset(ctx context.Context, userUUID, email, link string, expiresIn time.Duration) error
+	NotifyEmailVerification(ctx context.Context, userUUID, email, link string, expiresIn time.Duration) error
+}
+
+// RegisterInput is the data needed to create an account
+type RegisterInput struct {
+	Email       string
+	Username    string
+	Password    string
+	FirstName   string
+	LastName    string
+	PhoneNumber string
+}
+
+// ClientInfo describes the client a refresh token was issued to
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// TokenPair is returned by register, login and refresh
+type TokenPair struct {
+	AccessToken      string    `json:"access_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+type Service struct {
+	users      userrepos.Repository
+	repo       repos.Repository
+	tokens     *TokenIssuer
+	refreshTTL time.Duration
+	notifier   Notifier
+	sessions   *SessionCache
+	links      LinkConfig
+	mfaIssuer  string
+	secrets    *secretBox
+}
+
+func NewService(users userrepos.Repository, repo repos.Repository, tokens *TokenIssuer, refreshTTL time.Duration) *Service {
+	if refreshTTL <= 0 {
+		refreshTTL = defaultRefreshTokenTTL
+	}
+	return &Service{
+		users:      accountUsers{users},
+		repo:       repo,
+		tokens:     tokens,
+		refreshTTL: refreshTTL,
+		links: LinkConfig{
+			PasswordResetTTL:     defaultPasswordResetTTL,
+			EmailVerificationTTL: defaultEmailVerificationTTL,
+		},
+	}
+}
+
+// accountUsers runs the auth service's user lookups across tenants. Sign-in, sign-up
+// and account recovery happen before the caller's tenant is known, and the other
+// lookups are keyed by the user UUID of a verified token or stored challenge.
+type accountUsers struct {
+	userrepos.Repository
+}
+
+func (u accountUsers) GetByUUID(ctx context.Context, userUUID string) (*usermodels.User, error) {
+	return u.Repository.GetByUUID(tenant.Unscoped(ctx), userUUID)
+}
+
+func (u accountUsers) GetByEmail(ctx context.Context, email string) (*usermodels.User, error) {
+	return u.Repository.GetByEmail(tenant.Unscoped(ctx), email)
+}
+
+func (u accountUsers) Create(ctx context.Context, user *usermodels.User) error {
+	return u.Repository.Create(tenant.Unscoped(ctx), user)
+}
+
+func (u accountUsers) Update(ctx context.Context, user *usermodels.User) error {
+	return u.Repository.Update(tenant.Unscoped(ctx), user)
+}
+
+func (s *Service) SetNotifier(notifier Notifier) {
+	s.notifier = notifier
+}
+
+// SetSessionCache lets revocations take effect immediately in the cache AuthMiddleware checks
+func (s *Service) SetSessionCache(sessions *SessionCache) {
+	s.sessions = sessions
+}
+
+// Register creates an account and signs the new user in
+func (s *Service) Register(ctx context.Context, input RegisterInput, client ClientInfo) (*usermodels.User, *TokenPair, error) {
+	email := normalizeEmail(input.Email)
+	if email == "" {
+		return nil, nil, fmt.Errorf("%w: email is required", ErrInvalidRegistration)
+	}
+	if _, err := mail.ParseAddress(email); err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid email address", ErrInvalidRegistration)
+	}
+	username := strings.TrimSpace(input.Username)
+	if username == "" {
+		return nil, nil, fmt.Errorf("%w: username is required", ErrInvalidRegistration)
+	}
+	for _, field := range []struct {
+		name  string
+		value string
+		max   int
+	}{
+		{"email", email, maxEmailLength},
+		{"username", username, maxUsernameLength},
+		{"first_name", input.FirstName, maxNameLength},
+		{"last_name", input.LastName, maxNameLength},
+		{"phone_number", input.PhoneNumber, maxPhoneNumberLength},
+	} {
+		if utf8.RuneCountInString(field.value) > field.max {
+			return nil, nil, fmt.Errorf("%w: %s must be at most %d characters", ErrInvalidRegistration, field.name, field.max)
+		}
+	}
+
+	passwordHash, err := HashPassword(input.Password)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	userUUID, err := newUUID()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user := &usermodels.User{
+		UUID:         userUUID,
+		Email:        email,
+		Username:     username,
+		PasswordHash: passwordHash,
+		FirstName:    input.FirstName,
+		LastName:     input.LastName,
+		PhoneNumber:  input.PhoneNumber,
+		Role:         "user",
+	}
+	// The unique indexes decide whether the email or username is taken
+	if err := s.users.Create(ctx, user); err != nil {
+		switch {
+		case errors.Is(err, userrepos.ErrEmailExists):
+			return nil, nil, ErrEmailTaken
+		case errors.Is(err, userrepos.ErrUsernameExists):
+			return nil, nil, ErrUsernameTaken
+		}
+		return nil, nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	log.Printf("AuthService: Registered user %s", user.UUID)
+
+	if s.notifier != nil {
+		if err := s.notifier.NotifyUserCreated(ctx, user.UUID, user.Email, user.Username); err != nil {
+			log.Printf("AuthService: Failed to send welcome notification to user %s: %v", user.UUID, err)
+		}
+	}
+	if err := s.sendEmailVerification(ctx, user); err != nil && err != ErrLinksNotConfigured {
+		log.Printf("AuthService: Failed to send verification email to user %s: %v", user.UUID, err)
+	}
+
+	pair, err := s.issueTokens(ctx, user, "", []string{AMRPassword}, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, pair, nil
+}
+
+// LoginResult is either a token pair or, for users with MFA enabled, a challenge
+// token to complete with CompleteMFALogin
+type LoginResult struct {
+	Tokens            *TokenPair `json:"tokens,omitempty"`
+	MFARequired       bool       `json:"mfa_required"`
+	MFAToken          string     `json:"mfa_token,omitempty"`
+	MFATokenExpiresAt *time.Time `json:"mfa_token_expires_at,omitempty"`
+}
+
+// Login verifies the user's password. Users without MFA get a new session straight
+// away; users with MFA get a challenge to answer with a TOTP or recovery code.
+func (s *Service) Login(ctx context.Context, email, password string, client ClientInfo) (*LoginResult, error) {
+	user, err := s.users.GetByEmail(ctx, normalizeEmail(email))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			VerifyPassword(string(dummyHash), password)
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := VerifyPassword(user.PasswordHash, password); err != nil {
+		if err != ErrInvalidCredentials {
+			log.Printf("AuthService: Cannot verify password for user %s: %v", user.UUID, err)
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	if NeedsRehash(user.PasswordHash) {
+		s.rehashPassword(ctx, user, password)
+	}
+
+	enabled, err := s.mfaEnabled(ctx, user.UUID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return s.startMFAChallenge(ctx, user)
+	}
+
+	tokens, err := s.issueTokens(ctx, user, "", []string{AMRPassword}, client)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{Tokens: tokens}, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair. The presented token is
+// rotated out; presenting it again revokes the session it belongs to.
+func (s *Service) Refresh(ctx context.Context, refreshToken string, client ClientInfo) (*TokenPair, error) {
+	if refreshToken == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	token, err := s.repo.GetRefreshTokenByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	if token.RotatedAt != nil {
+		return nil, s.handleReuse(ctx, token)
+	}
+	if token.RevokedAt != nil || time.Now().After(token.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	rotated, err := s.repo.MarkRefreshTokenRotated(ctx, token.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if !rotated {
+		// Another request rotated the token between the lookup and the update
+		return nil, s.handleReuse(ctx, token)
+	}
+
+	user, err := s.users.GetByUUID(ctx, token.UserUUID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	session, err := s.repo.GetSession(ctx, token.FamilyID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return s.issueTokens(ctx, user, session.ID, splitAMR(session.AMR), client)
+}
+
+// Logout ends the session the refresh token belongs to
+func (s *Service) Logout(ctx context.Context, refreshToken string) error {
+	if refreshToken == "" {
+		return ErrInvalidRefreshToken
+	}
+
+	token, err := s.repo.GetRefreshTokenByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrInvalidRefreshToken
+		}
+		return fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	if err := s.revokeSession(ctx, token.UserUUID, token.FamilyID); err != nil && err != ErrSessionNotFound {
+		return err
+	}
+	log.Printf("AuthService: User %s logged out of session %s", token.UserUUID, token.FamilyID)
+	return nil
+}
+
+// ListSessions returns the user's active sessions, most recently used first
+func (s *Service) ListSessions(ctx context.Context, userUUID string) ([]models.Session, error) {
+	if userUUID == "" {
+		return nil, fmt.Errorf("user UUID is required")
+	}
+
+	sessions, err := s.repo.GetActiveSessions(ctx, userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// RevokeSession signs the user out of one session. Its access tokens stop working
+// immediately and its refresh token can no longer be used.
+func (s *Service) RevokeSession(ctx context.Context, userUUID, sessionID string) error {
+	if userUUID == "" {
+		return fmt.Errorf("user UUID is required")
+	}
+	if err := s.revokeSession(ctx, userUUID, sessionID); err != nil {
+		return err
+	}
+	log.Printf("AuthService: Revoked session %s for user %s", sessionID, userUUID)
+	return nil
+}
+
+// RevokeAllSessions signs the user out everywhere except exceptSessionID, which may be
+// empty, and returns how many sessions were revoked
+func (s *Service) RevokeAllSessions(ctx context.Context, userUUID, exceptSessionID string) (int, error) {
+	if userUUID == "" {
+		return 0, fmt.Errorf("user UUID is required")
+	}
+
+	revoked, jtis, err := s.repo.RevokeUserSessions(ctx, userUUID, exceptSessionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if s.sessions != nil {
+		s.sessions.Revoke(jtis...)
+	}
+
+	log.Printf("AuthService: Revoked %d session(s) for user %s", revoked, userUUID)
+	return revoked, nil
+}
+
+func (s *Service) revokeSession(ctx context.Context, userUUID, sessionID string) error {
+	revoked, jtis, err := s.repo.RevokeSession(ctx, userUUID, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if revoked == 0 {
+		return ErrSessionNotFound
+	}
+	if s.sessions != nil {
+		s.sessions.Revoke(jtis...)
+	}
+	return nil
+}
+
+func (s *Service) handleReuse(ctx context.Context, token *models.RefreshToken) error {
+	log.Printf("AuthService: Refresh token reuse detected for user %s, revoking session %s", token.UserUUID, token.FamilyID)
+
+	if err := s.revokeSession(ctx, token.UserUUID, token.FamilyID); err != nil && err != ErrSessionNotFound {
+		log.Printf("AuthService: Failed to revoke session %s: %v", token.FamilyID, err)
+	}
+
+	if s.notifier != nil {
+		if user, err := s.users.GetByUUID(ctx, token.UserUUID); err == nil {
+			message := "A sign-in token for your account was used more than once, so we signed that session out. If this wasn't you, change your password."
+			if err := s.notifier.NotifySecurityNotice(ctx, user.UUID, user.Email, "Suspicious sign-in activity", message); err != nil {
+				log.Printf("AuthService: Failed to send token reuse notice to user %s: %v", user.UUID, err)
+			}
+		}
+	}
+
+	return ErrRefreshTokenReused
+}
+
+// issueTokens signs an access token and stores a new refresh token. An empty
+// sessionID starts a new session, authenticated by the amr methods; the session ID
+// doubles as the refresh token family.
+func (s *Service) issueTokens(ctx context.Context, user *usermodels.User, sessionID string, amr []string, client ClientInfo) (*TokenPair, error) {
+	refreshToken, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	record := &models.RefreshToken{
+		UserUUID:  user.UUID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: now.Add(s.refreshTTL),
+		UserAgent: truncate(client.UserAgent, 255),
+		IPAddress: truncate(client.IPAddress, 45),
+	}
+
+	if sessionID == "" {
+		id, err := randomID()
+		if err != nil {
+			return nil, err
+		}
+		session := &models.Session{
+			ID:         id,
+			UserUUID:   user.UUID,
+			UserAgent:  record.UserAgent,
+			IPAddress:  record.IPAddress,
+			AMR:        strings.Join(amr, " "),
+			LastUsedAt: now,
+			ExpiresAt:  record.ExpiresAt,
+		}
+		if err := s.repo.CreateSession(ctx, session); err != nil {
+			return nil, fmt.Errorf("failed to create session: %w", err)
+		}
+		sessionID = id
+	} else if err := s.repo.TouchSession(ctx, sessionID, record.IPAddress, record.ExpiresAt); err != nil {
+		return nil, fmt.Errorf("failed to update session: %w", err)
+	}
+
+	record.FamilyID = sessionID
+	if err := s.repo.CreateRefreshToken(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to save refresh token: %w", err)
+	}
+
+	accessToken, jti, accessExpiresAt, err := s.tokens.IssueAccessToken(user, sessionID, amr)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateAccessToken(ctx, &models.AccessToken{
+		JTI:       jti,
+		SessionID: sessionID,
+		UserUUID:  user.UUID,
+		ExpiresAt: accessExpiresAt,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to save access token: %w", err)
+	}
+
+	return &TokenPair{
+		AccessToken:      accessToken,
+		TokenType:        "Bearer",
+		ExpiresAt:        accessExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: record.ExpiresAt,
+	}, nil
+}
+
+func (s *Service) rehashPassword(ctx context.Context, user *usermodels.User, password string) {
+	hash, err := HashPassword(password)
+	if err != nil {
+		return
+	}
+	user.PasswordHash = hash
+	if err := s.users.Update(ctx, user); err != nil {
+		log.Printf("AuthService: Failed to upgrade password hash for user %s: %v", user.UUID, err)
+	}
+}
+
+// newOpaqueToken returns a random URL-safe token for refresh, reset and challenge flows
+func newOpaqueToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func truncate(value string, max int) string {
+	if len(value) > max {
+		return value[:max]
+	}
+	return value
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate user UUID: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
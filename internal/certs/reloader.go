@@ -0,0 +1,191 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// reloadCheckInterval bounds how often the files are checked for changes
+const reloadCheckInterval = 30 * time.Second
+
+// Reloader serves a certificate, key and CA bundle from disk and picks up rotated files
+// without a restart. Files are checked during handshakes, at most once per
+// reloadCheckInterval; if a rotated file fails to load the previous material is kept.
+type Reloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	pool      *x509.CertPool
+	modTimes  [3]time.Time
+	lastCheck time.Time
+}
+
+// NewReloader loads the files once. certFile and keyFile may be empty for a client
+// that presents no certificate; caFile may be empty to use the system roots.
+func NewReloader(certFile, keyFile, caFile string) (*Reloader, error) {
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("TLS certificate and key files must be set together")
+	}
+
+	r := &Reloader{certFile: certFile, keyFile: keyFile, caFile: caFile}
+	modTimes, err := r.stat()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(modTimes); err != nil {
+		return nil, err
+	}
+	r.lastCheck = time.Now()
+	return r, nil
+}
+
+// ServerConfig returns a TLS config for a gRPC server. With requireClientCert the
+// server demands a client certificate signed by the CA bundle. Otherwise, when a CA
+// bundle is configured, a client certificate is optional but verified if presented, so
+// callers can still be identified by it; callers without one use a service key.
+func (r *Reloader) ServerConfig(requireClientCert bool) (*tls.Config, error) {
+	if r.certFile == "" {
+		return nil, errors.New("TLS certificate and key files are required for the server")
+	}
+	if requireClientCert && r.caFile == "" {
+		return nil, errors.New("a CA bundle is required to verify client certificates")
+	}
+
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// Build the config per handshake so rotated certificates and CAs apply to new connections
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, pool := r.current()
+			config := &tls.Config{
+				Certificates: []tls.Certificate{*cert},
+				ClientAuth:   tls.NoClientCert,
+				MinVersion:   tls.VersionTLS12,
+				NextProtos:   []string{"h2"},
+			}
+			if pool != nil {
+				config.ClientAuth = tls.VerifyClientCertIfGiven
+				config.ClientCAs = pool
+			}
+			if requireClientCert {
+				config.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return config, nil
+		},
+	}, nil
+}
+
+// ClientConfig returns a TLS config for a gRPC client. It presents the certificate
+// when one is configured and verifies the server against the CA bundle, or the system
+// roots without one. serverName overrides the name expected in the server certificate.
+func (r *Reloader) ClientConfig(serverName string) *tls.Config {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := r.current()
+			if cert == nil {
+				return &tls.Certificate{}, nil
+			}
+			return cert, nil
+		},
+	}
+	if r.caFile == "" {
+		return config
+	}
+
+	// The default verification would pin the CA pool for the config's lifetime, so
+	// verify against the current pool instead
+	config.InsecureSkipVerify = true
+	config.VerifyConnection = func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			return errors.New("server presented no certificate")
+		}
+		_, pool := r.current()
+		intermediates := x509.NewCertPool()
+		for _, cert := range state.PeerCertificates[1:] {
+			intermediates.AddCert(cert)
+		}
+		_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+			Roots:         pool,
+			Intermediates: intermediates,
+			DNSName:       state.ServerName,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		})
+		return err
+	}
+	return config
+}
+
+// current returns the certificate and CA pool, reloading them if the files changed
+func (r *Reloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.lastCheck) >= reloadCheckInterval {
+		r.lastCheck = time.Now()
+		modTimes, err := r.stat()
+		if err != nil {
+			log.Printf("CertReloader: Failed to check TLS files: %v", err)
+		} else if modTimes != r.modTimes {
+			if err := r.load(modTimes); err != nil {
+				log.Printf("CertReloader: Keeping previous TLS material: %v", err)
+			} else {
+				log.Printf("CertReloader: Reloaded TLS certificates")
+			}
+		}
+	}
+	return r.cert, r.pool
+}
+
+// load reads every file and swaps in the new material. Callers must hold r.mu or own r.
+func (r *Reloader) load(modTimes [3]time.Time) error {
+	var cert *tls.Certificate
+	if r.certFile != "" {
+		loaded, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load TLS certificates: %w", err)
+		}
+		cert = &loaded
+	}
+
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("CA bundle %s contains no certificates", r.caFile)
+		}
+	}
+
+	r.cert = cert
+	r.pool = pool
+	r.modTimes = modTimes
+	return nil
+}
+
+func (r *Reloader) stat() ([3]time.Time, error) {
+	var modTimes [3]time.Time
+	for i, file := range []string{r.certFile, r.keyFile, r.caFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return modTimes, err
+		}
+		modTimes[i] = info.ModTime()
+	}
+	return modTimes, nil
+}
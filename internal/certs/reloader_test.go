@@ -0,0 +1,290 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCA issues certificates for the tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns the PEM certificate and key for a leaf with the given serial, DNS name
+// and usage
+func (ca *testCA) issue(t *testing.T, serial int64, dnsName string, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: dnsName},
+		DNSNames:     []string{dnsName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeFile writes content and moves its modification time forward, so a rewrite
+// within the filesystem's timestamp resolution still counts as a change
+func writeFile(t *testing.T, path string, content []byte, age time.Duration) {
+	t.Helper()
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(age)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func serial(t *testing.T, cert *tls.Certificate) int64 {
+	t.Helper()
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf.SerialNumber.Int64()
+}
+
+func TestReloaderPicksUpRotatedFiles(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+
+	certPEM, keyPEM := ca.issue(t, 10, "server.test", x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, certPEM, -time.Minute)
+	writeFile(t, keyFile, keyPEM, -time.Minute)
+	writeFile(t, caFile, ca.pem, -time.Minute)
+
+	r, err := NewReloader(certFile, keyFile, caFile)
+	if err != nil {
+		t.Fatalf("NewReloader: %v", err)
+	}
+	if cert, _ := r.current(); serial(t, cert) != 10 {
+		t.Fatalf("initial serial = %d, want 10", serial(t, cert))
+	}
+
+	certPEM, keyPEM = ca.issue(t, 11, "server.test", x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, certPEM, 0)
+	writeFile(t, keyFile, keyPEM, 0)
+
+	// Files are only checked once per reloadCheckInterval
+	if cert, _ := r.current(); serial(t, cert) != 10 {
+		t.Fatalf("serial before the check interval = %d, want 10", serial(t, cert))
+	}
+
+	r.lastCheck = time.Time{}
+	if cert, _ := r.current(); serial(t, cert) != 11 {
+		t.Fatalf("serial after rotation = %d, want 11", serial(t, cert))
+	}
+}
+
+func TestReloaderKeepsPreviousMaterialWhenRotationFails(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+
+	certPEM, keyPEM := ca.issue(t, 10, "server.test", x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, certPEM, -time.Minute)
+	writeFile(t, keyFile, keyPEM, -time.Minute)
+
+	r, err := NewReloader(certFile, keyFile, "")
+	if err != nil {
+		t.Fatalf("NewReloader: %v", err)
+	}
+
+	// A certificate written before its key doesn't match the old key
+	rotated, _ := ca.issue(t, 11, "server.test", x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, rotated, 0)
+
+	r.lastCheck = time.Time{}
+	if cert, _ := r.current(); cert == nil || serial(t, cert) != 10 {
+		t.Fatalf("half-rotated files replaced the certificate")
+	}
+}
+
+func TestServerConfigClientCertificates(t *testing.T) {
+	ca := newTestCA(t)
+	otherCA := newTestCA(t)
+	dir := t.TempDir()
+
+	write := func(name string, content []byte) string {
+		path := filepath.Join(dir, name)
+		writeFile(t, path, content, 0)
+		return path
+	}
+	caFile := write("ca.crt", ca.pem)
+	serverCert, serverKey := ca.issue(t, 1, "server.test", x509.ExtKeyUsageServerAuth)
+	server, err := NewReloader(write("server.crt", serverCert), write("server.key", serverKey), caFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientCert, clientKey := ca.issue(t, 2, "billing.test", x509.ExtKeyUsageClientAuth)
+	trustedClient, err := NewReloader(write("client.crt", clientCert), write("client.key", clientKey), caFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	strangerCert, strangerKey := otherCA.issue(t, 3, "stranger.test", x509.ExtKeyUsageClientAuth)
+	untrustedClient, err := NewReloader(write("stranger.crt", strangerCert), write("stranger.key", strangerKey), caFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	anonymousClient, err := NewReloader("", "", caFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		require      bool
+		client       *Reloader
+		wantErr      bool
+		wantVerified bool
+	}{
+		{name: "optional without a certificate", client: anonymousClient},
+		{name: "optional with a trusted certificate", client: trustedClient, wantVerified: true},
+		{name: "optional with an untrusted certificate", client: untrustedClient, wantErr: true},
+		{name: "required without a certificate", require: true, client: anonymousClient, wantErr: true},
+		{name: "required with a trusted certificate", require: true, client: trustedClient, wantVerified: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverConfig, err := server.ServerConfig(tt.require)
+			if err != nil {
+				t.Fatal(err)
+			}
+			state, err := handshake(serverConfig, tt.client.ClientConfig("server.test"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("handshake error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && (len(state.VerifiedChains) > 0) != tt.wantVerified {
+				t.Fatalf("client certificate verified = %v, want %v", len(state.VerifiedChains) > 0, tt.wantVerified)
+			}
+		})
+	}
+}
+
+func TestClientConfigRejectsWrongServerName(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.crt")
+	writeFile(t, caFile, ca.pem, 0)
+	certPEM, keyPEM := ca.issue(t, 1, "server.test", x509.ExtKeyUsageServerAuth)
+	writeFile(t, filepath.Join(dir, "server.crt"), certPEM, 0)
+	writeFile(t, filepath.Join(dir, "server.key"), keyPEM, 0)
+
+	server, err := NewReloader(filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"), caFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewReloader("", "", caFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverConfig, err := server.ServerConfig(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := handshake(serverConfig, client.ClientConfig("other.test")); err == nil {
+		t.Fatal("handshake succeeded with a server name the certificate doesn't cover")
+	}
+}
+
+// handshake runs a TLS handshake over a loopback connection and returns the server's
+// view of it
+func handshake(serverConfig, clientConfig *tls.Config) (tls.ConnectionState, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
+	defer listener.Close()
+
+	clientErr := make(chan error, 1)
+	go func() {
+		conn, err := tls.Dial("tcp", listener.Addr().String(), clientConfig)
+		if err != nil {
+			clientErr <- err
+			return
+		}
+		defer conn.Close()
+		// TLS 1.3 clients finish before the server has checked their certificate, so
+		// wait for the server's byte to learn whether it accepted the connection
+		_, err = conn.Read(make([]byte, 1))
+		clientErr <- err
+	}()
+
+	conn, err := listener.Accept()
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	server := tls.Server(conn, serverConfig)
+	if err := server.Handshake(); err != nil {
+		return tls.ConnectionState{}, err
+	}
+	if _, err := server.Write([]byte{0}); err != nil {
+		return tls.ConnectionState{}, err
+	}
+	if err := <-clientErr; err != nil {
+		return tls.ConnectionState{}, err
+	}
+	return server.ConnectionState(), nil
+}
@@ -2,6 +2,9 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -9,12 +12,64 @@ type Config struct {
 	Port                       string
 	GRPCPort                   string
 	JWTKey                     string
+	JWKSSource                 string
+	JWKSCacheTTL               time.Duration
+	JWTIssuer                  string
+	JWTAudience                []string
+	JWTClockSkew               time.Duration
+	AccessTokenTTL             time.Duration
+	RefreshTokenTTL            time.Duration
+	SessionCacheTTL            time.Duration
+	RoleCacheTTL               time.Duration
+	PasswordResetURL           string
+	PasswordResetTTL           time.Duration
+	EmailVerificationURL       string
+	EmailVerificationTTL       time.Duration
+	MFAIssuer                  string
+	MFAEncryptionKey           string
+	AuthzPolicyFile            string
+	AuthServiceName            string
 	ServiceKey                 string
+	ServiceCredentialsFile     string
+	ServiceSigningSecret       string
+	ServiceSignatureMaxSkew    time.Duration
 	CoreNotificationServiceAddr string
+	NotificationClientTimeout  time.Duration
+	NotificationClientRetries  int
+	NotificationClientKeepalive time.Duration
 	TLSCertFile                string
 	TLSKeyFile                 string
+	TLSClientCertFile          string
+	TLSClientKeyFile           string
+	TLSCAFile                  string
+	TLSRequireClientCert       bool
+	TLSServerName              string
 	TLSEnabled                 bool
-	NotificationProvider       string
+	NotificationProviders      []string
+	NotificationSMSProviders   []string
+	NotificationWebhookProviders []string
+	NotificationBreakerThreshold int
+	NotificationBreakerCooldown  time.Duration
+	MetricsEnabled             bool
+	NotificationChannels       []string
+	NotificationOutboxWorkers  int
+	NotificationMaxAttempts    int
+	IdempotencyWindow          time.Duration
+	EventSchemasFile           string
+	MaxAttachmentBytes         int
+	DocumentExpiryAttachFile   bool
+	SMTPHost                   string
+	SMTPPort                   string
+	SMTPUsername               string
+	SMTPPassword               string
+	SMTPFrom                   string
+	TwilioAccountSID           string
+	TwilioAuthToken            string
+	TwilioFrom                 string
+	TwilioBaseURL              string
+	PublicBaseURL              string
+	UnsubscribeSecret          string
+	EmailEventsSecret          string
 }
 
 func LoadConfig() *Config {
@@ -23,11 +78,99 @@ func LoadConfig() *Config {
 		Port:                       os.Getenv("PORT"),
 		GRPCPort:                   os.Getenv("GRPC_PORT"),
 		JWTKey:                     os.Getenv("JWT_KEY"),
+		JWKSSource:                 getFirst("JWT_JWKS_URL", "JWT_JWKS_FILE"),
+		JWKSCacheTTL:               getDuration("JWT_JWKS_CACHE_TTL", 10*time.Minute),
+		JWTIssuer:                  os.Getenv("JWT_ISSUER"),
+		JWTAudience:                splitList(os.Getenv("JWT_AUDIENCE")),
+		JWTClockSkew:               getDuration("JWT_CLOCK_SKEW", 30*time.Second),
+		AccessTokenTTL:             getDuration("JWT_ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL:            getDuration("JWT_REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		SessionCacheTTL:            getDuration("SESSION_CACHE_TTL", 30*time.Second),
+		RoleCacheTTL:               getDuration("ROLE_CACHE_TTL", 30*time.Second),
+		PasswordResetURL:           os.Getenv("PASSWORD_RESET_URL"),
+		PasswordResetTTL:           getDuration("PASSWORD_RESET_TTL", time.Hour),
+		EmailVerificationURL:       os.Getenv("EMAIL_VERIFICATION_URL"),
+		EmailVerificationTTL:       getDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+		MFAIssuer:                  os.Getenv("MFA_ISSUER"),
+		MFAEncryptionKey:           os.Getenv("MFA_ENCRYPTION_KEY"),
+		AuthzPolicyFile:            os.Getenv("AUTHZ_POLICY_FILE"),
+		AuthServiceName:            os.Getenv("AUTH_SERVICE_NAME"),
 		ServiceKey:                 os.Getenv("SERVICE_KEY"),
+		ServiceCredentialsFile:     os.Getenv("SERVICE_CREDENTIALS_FILE"),
+		ServiceSigningSecret:       os.Getenv("SERVICE_SIGNING_SECRET"),
+		ServiceSignatureMaxSkew:    getDuration("SERVICE_SIGNATURE_MAX_SKEW", 5*time.Minute),
 		CoreNotificationServiceAddr: os.Getenv("CORE_NOTIFICATION_SERVICE_ADDR"),
+		NotificationClientTimeout:  getDuration("NOTIFICATION_CLIENT_TIMEOUT", 10*time.Second),
+		NotificationClientRetries:  getInt("NOTIFICATION_CLIENT_MAX_ATTEMPTS", 4),
+		NotificationClientKeepalive: getDuration("NOTIFICATION_CLIENT_KEEPALIVE", 30*time.Second),
 		TLSCertFile:                os.Getenv("TLS_CERT_FILE"),
 		TLSKeyFile:                 os.Getenv("TLS_KEY_FILE"),
+		TLSClientCertFile:          os.Getenv("TLS_CLIENT_CERT_FILE"),
+		TLSClientKeyFile:           os.Getenv("TLS_CLIENT_KEY_FILE"),
+		TLSCAFile:                  os.Getenv("TLS_CA_FILE"),
+		TLSRequireClientCert:       os.Getenv("TLS_REQUIRE_CLIENT_CERT") == "true",
+		TLSServerName:              os.Getenv("TLS_SERVER_NAME"),
 		TLSEnabled:                 os.Getenv("TLS_ENABLED") == "true",
-		NotificationProvider:       os.Getenv("NOTIFICATION_PROVIDER"),
+		NotificationProviders:      splitList(os.Getenv("NOTIFICATION_PROVIDER")),
+		NotificationSMSProviders:   splitList(os.Getenv("NOTIFICATION_SMS_PROVIDERS")),
+		NotificationWebhookProviders: splitList(os.Getenv("NOTIFICATION_WEBHOOK_PROVIDERS")),
+		NotificationBreakerThreshold: getInt("NOTIFICATION_BREAKER_THRESHOLD", 5),
+		NotificationBreakerCooldown:  getDuration("NOTIFICATION_BREAKER_COOLDOWN", 30*time.Second),
+		MetricsEnabled:             os.Getenv("METRICS_ENABLED") == "true",
+		NotificationChannels:       splitList(os.Getenv("NOTIFICATION_CHANNELS")),
+		NotificationOutboxWorkers:  getInt("NOTIFICATION_OUTBOX_WORKERS", 2),
+		NotificationMaxAttempts:    getInt("NOTIFICATION_MAX_ATTEMPTS", 5),
+		IdempotencyWindow:          getDuration("NOTIFICATION_IDEMPOTENCY_WINDOW", 24*time.Hour),
+		EventSchemasFile:           os.Getenv("NOTIFICATION_EVENT_SCHEMAS_FILE"),
+		MaxAttachmentBytes:         getInt("NOTIFICATION_MAX_ATTACHMENT_BYTES", 3<<20),
+		DocumentExpiryAttachFile:   os.Getenv("DOCUMENT_EXPIRY_ATTACH_FILE") == "true",
+		SMTPHost:                   os.Getenv("SMTP_HOST"),
+		SMTPPort:                   os.Getenv("SMTP_PORT"),
+		SMTPUsername:               os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:               os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:                   os.Getenv("SMTP_FROM"),
+		TwilioAccountSID:           os.Getenv("TWILIO_ACCOUNT_SID"),
+		TwilioAuthToken:            os.Getenv("TWILIO_AUTH_TOKEN"),
+		TwilioFrom:                 os.Getenv("TWILIO_FROM"),
+		TwilioBaseURL:              os.Getenv("TWILIO_BASE_URL"),
+		PublicBaseURL:              os.Getenv("PUBLIC_BASE_URL"),
+		UnsubscribeSecret:          os.Getenv("UNSUBSCRIBE_SECRET"),
+		EmailEventsSecret:          os.Getenv("EMAIL_EVENTS_SECRET"),
 	}
 }
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func getInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func getDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// getFirst returns the first non-empty value among the given environment variables
+func getFirst(keys ...string) string {
+	for _, key := range keys {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
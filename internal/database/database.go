@@ -2,9 +2,11 @@ package database
 
 import (
 	"database/sql"
+	"fmt"
 	"log"
 
 	"github.com/johnroshan2255/core-service/internal/config"
+	"github.com/johnroshan2255/core-service/internal/tenant"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -15,6 +17,10 @@ func InitDB(cfg *config.Config) (*gorm.DB, error) {
 		return nil, err
 	}
 
+	if err := db.Use(tenant.Plugin{}); err != nil {
+		return nil, err
+	}
+
 	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, err
@@ -40,3 +46,46 @@ func GetSQLDB(db *gorm.DB) (*sql.DB, error) {
 	return db.DB()
 }
 
+// PrepareTenantColumn maps users rows with a NULL tenant_id to the empty tenant, which
+// is what the tenant plugin scopes tenant-less callers to. It runs before AutoMigrate
+// makes the column NOT NULL and does nothing on a fresh database.
+func PrepareTenantColumn(db *gorm.DB) error {
+	if !db.Migrator().HasTable("users") || !db.Migrator().HasColumn("users", "tenant_id") {
+		return nil
+	}
+	return db.Exec("UPDATE users SET tenant_id = '' WHERE tenant_id IS NULL").Error
+}
+
+// BackfillTenantIDs copies the owner's tenant from users onto rows of the given models'
+// tables that were written before the table was tenant-scoped and so still have an
+// empty tenant_id. Every model must have a user_uuid column. Tables that don't exist
+// yet are skipped.
+func BackfillTenantIDs(db *gorm.DB, models ...interface{}) error {
+	for _, model := range models {
+		if !db.Migrator().HasTable(model) {
+			continue
+		}
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		result := db.Exec(fmt.Sprintf(`UPDATE %[1]s SET tenant_id = users.tenant_id FROM users
+			WHERE %[1]s.user_uuid::text = users.uuid::text AND %[1]s.tenant_id = '' AND users.tenant_id <> ''`, stmt.Table))
+		if result.Error != nil {
+			return fmt.Errorf("failed to backfill tenant_id on %s: %w", stmt.Table, result.Error)
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("Database: backfilled tenant_id on %d %s rows", result.RowsAffected, stmt.Table)
+		}
+	}
+	return nil
+}
+
+// AutoMigrate creates or updates the tables for the given models
+func AutoMigrate(db *gorm.DB, models ...interface{}) error {
+	if err := db.AutoMigrate(models...); err != nil {
+		return err
+	}
+	log.Println("Database migrations applied successfully")
+	return nil
+}
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	documentmodels "github.com/johnroshan2255/core-service/internal/document/models"
+	"github.com/johnroshan2255/core-service/internal/tenant"
+	"github.com/johnroshan2255/core-service/internal/tenant/tenanttest"
+	usermodels "github.com/johnroshan2255/core-service/internal/user/models"
+)
+
+const otherUUID = "2d9e7c4b-8a1f-4b3e-9c5d-6e7f8a9b0c1d"
+
+func TestBackfillTenantIDs(t *testing.T) {
+	db := tenanttest.OpenPostgres(t, &usermodels.User{}, &usermodels.CompanyDetails{}).
+		WithContext(tenant.Unscoped(context.Background()))
+
+	users := []usermodels.User{
+		{UUID: tenanttest.OwnerUUID, Email: "owner@example.com", Username: "owner", PasswordHash: "hash", TenantID: "tenant-b"},
+		{UUID: otherUUID, Email: "other@example.com", Username: "other", PasswordHash: "hash"},
+	}
+	if err := db.Create(&users).Error; err != nil {
+		t.Fatalf("create users: %v", err)
+	}
+	companies := []usermodels.CompanyDetails{
+		{UserUUID: tenanttest.OwnerUUID, CompanyName: "Owner Ltd"},
+		{UserUUID: otherUUID, CompanyName: "Other Ltd"},
+	}
+	if err := db.Create(&companies).Error; err != nil {
+		t.Fatalf("create company details: %v", err)
+	}
+
+	// documents was never migrated here and is skipped
+	if err := BackfillTenantIDs(db, &usermodels.CompanyDetails{}, &documentmodels.Document{}); err != nil {
+		t.Fatalf("BackfillTenantIDs: %v", err)
+	}
+
+	want := map[string]string{tenanttest.OwnerUUID: "tenant-b", otherUUID: ""}
+	var got []usermodels.CompanyDetails
+	if err := db.Find(&got).Error; err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	for _, company := range got {
+		if company.TenantID != want[company.UserUUID] {
+			t.Errorf("company details of %s have tenant %q, want %q", company.UserUUID, company.TenantID, want[company.UserUUID])
+		}
+	}
+
+	// A second run finds nothing left to backfill
+	if err := BackfillTenantIDs(db, &usermodels.CompanyDetails{}); err != nil {
+		t.Fatalf("second BackfillTenantIDs: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package calendar
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/johnroshan2255/core-service/internal/document/models"
+)
+
+// ContentType is the MIME type of the generated calendars
+const ContentType = "text/calendar; charset=utf-8"
+
+const (
+	prodID    = "-//core-service//Document Expiry//EN"
+	uidDomain = "core-service"
+	maxLine   = 75
+)
+
+// reminders are the alarms added to every expiry event, relative to the start of the expiry day
+var reminders = []struct {
+	trigger     string
+	description string
+}{
+	{"-P30D", "expires in 30 days"},
+	{"-P7D", "expires in 7 days"},
+	{"-P1D", "expires tomorrow"},
+}
+
+// Build renders an RFC 5545 calendar with one all-day event per document expiry date.
+// Documents without an expiry date are skipped.
+func Build(name string, docs []models.Document, now time.Time) []byte {
+	var buf bytes.Buffer
+
+	writeLine(&buf, "BEGIN:VCALENDAR")
+	writeLine(&buf, "VERSION:2.0")
+	writeLine(&buf, "PRODID:"+prodID)
+	writeLine(&buf, "CALSCALE:GREGORIAN")
+	writeLine(&buf, "METHOD:PUBLISH")
+	if name != "" {
+		writeLine(&buf, "X-WR-CALNAME:"+escapeText(name))
+	}
+
+	for i := range docs {
+		writeEvent(&buf, &docs[i], now)
+	}
+
+	writeLine(&buf, "END:VCALENDAR")
+	return buf.Bytes()
+}
+
+// Filename returns the attachment file name for a single document's calendar event
+func Filename(doc *models.Document) string {
+	return fmt.Sprintf("document-%d-expiry.ics", doc.ID)
+}
+
+func writeEvent(buf *bytes.Buffer, doc *models.Document, now time.Time) {
+	if doc.ExpiryDate == nil {
+		return
+	}
+
+	expiry := doc.ExpiryDate.UTC()
+	start := time.Date(expiry.Year(), expiry.Month(), expiry.Day(), 0, 0, 0, 0, time.UTC)
+	summary := fmt.Sprintf("%s expires", doc.Name)
+
+	writeLine(buf, "BEGIN:VEVENT")
+	writeLine(buf, fmt.Sprintf("UID:document-%d@%s", doc.ID, uidDomain))
+	writeLine(buf, "DTSTAMP:"+now.UTC().Format("20060102T150405Z"))
+	if !doc.UpdatedAt.IsZero() {
+		writeLine(buf, "LAST-MODIFIED:"+doc.UpdatedAt.UTC().Format("20060102T150405Z"))
+	}
+	writeLine(buf, "DTSTART;VALUE=DATE:"+start.Format("20060102"))
+	writeLine(buf, "DTEND;VALUE=DATE:"+start.AddDate(0, 0, 1).Format("20060102"))
+	writeLine(buf, "SUMMARY:"+escapeText(summary))
+	writeLine(buf, "DESCRIPTION:"+escapeText(description(doc)))
+	writeLine(buf, "CATEGORIES:"+escapeText(string(doc.Category)))
+	writeLine(buf, "TRANSP:TRANSPARENT")
+
+	for _, reminder := range reminders {
+		writeLine(buf, "BEGIN:VALARM")
+		writeLine(buf, "ACTION:DISPLAY")
+		writeLine(buf, "TRIGGER:"+reminder.trigger)
+		writeLine(buf, "DESCRIPTION:"+escapeText(fmt.Sprintf("%s %s", doc.Name, reminder.description)))
+		writeLine(buf, "END:VALARM")
+	}
+
+	writeLine(buf, "END:VEVENT")
+}
+
+func description(doc *models.Document) string {
+	text := fmt.Sprintf("Your %s document %q expires on %s. Renew it before then.",
+		strings.ReplaceAll(string(doc.Category), "_", " "), doc.Name, doc.ExpiryDate.UTC().Format("January 2, 2006"))
+	if doc.Description != "" {
+		text += "\n\n" + doc.Description
+	}
+	return text
+}
+
+// escapeText escapes a TEXT value as described in RFC 5545 section 3.3.11
+func escapeText(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", "",
+	).Replace(value)
+}
+
+// writeLine writes a content line, folding it at 75 octets without splitting UTF-8 sequences
+func writeLine(buf *bytes.Buffer, line string) {
+	limit := maxLine
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// continuation lines start with a space, which counts towards the limit
+		limit = maxLine - 1
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// CalendarFeed holds a user's secret calendar feed token. Only a SHA-256 hash of
+// the token is stored; rotating the token invalidates previously shared feed URLs.
+type CalendarFeed struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserUUID  string    `gorm:"type:uuid;uniqueIndex;not null" json:"user_uuid"`
+	TenantID  string    `gorm:"type:varchar(255);index;not null;default:''" json:"tenant_id"`
+	TokenHash string    `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
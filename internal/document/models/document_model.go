@@ -34,6 +34,7 @@ const (
 type Document struct {
 	ID          uint           `gorm:"primaryKey" json:"id"`
 	UserUUID    string         `gorm:"type:uuid;index;not null" json:"user_uuid"`
+	TenantID    string         `gorm:"type:varchar(255);index;not null;default:''" json:"tenant_id"`
 	Name        string         `gorm:"type:varchar(255);not null" json:"name"`
 	Description string         `gorm:"type:text" json:"description"`
 	Category    DocumentCategory `gorm:"type:varchar(50);not null" json:"category"`
@@ -6,6 +6,7 @@ import (
 
 	"github.com/johnroshan2255/core-service/internal/document/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Repository interface {
@@ -19,6 +20,10 @@ type Repository interface {
 	GetExpiringDocuments(ctx context.Context, daysBeforeExpiry int) ([]models.Document, error)
 	GetExpiredDocuments(ctx context.Context) ([]models.Document, error)
 	UpdateNotificationSent(ctx context.Context, id uint, sent bool) error
+	GetUpcomingExpiries(ctx context.Context, userUUID string, since time.Time) ([]models.Document, error)
+
+	GetCalendarFeedByTokenHash(ctx context.Context, tokenHash string) (*models.CalendarFeed, error)
+	UpsertCalendarFeed(ctx context.Context, feed *models.CalendarFeed) error
 }
 
 type GORMRepository struct {
@@ -106,3 +111,31 @@ func (r *GORMRepository) UpdateNotificationSent(ctx context.Context, id uint, se
 	return r.db.WithContext(ctx).Model(&models.Document{}).Where("id = ?", id).Update("notification_sent", sent).Error
 }
 
+
+func (r *GORMRepository) GetUpcomingExpiries(ctx context.Context, userUUID string, since time.Time) ([]models.Document, error) {
+	var docs []models.Document
+	if err := r.db.WithContext(ctx).
+		Where("user_uuid = ?", userUUID).
+		Where("expiry_date IS NOT NULL").
+		Where("expiry_date >= ?", since).
+		Order("expiry_date ASC").
+		Find(&docs).Error; err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+func (r *GORMRepository) GetCalendarFeedByTokenHash(ctx context.Context, tokenHash string) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&feed).Error; err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+func (r *GORMRepository) UpsertCalendarFeed(ctx context.Context, feed *models.CalendarFeed) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_uuid"}},
+		DoUpdates: clause.AssignmentColumns([]string{"token_hash", "updated_at"}),
+	}).Create(feed).Error
+}
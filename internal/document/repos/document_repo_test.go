@@ -0,0 +1,76 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/johnroshan2255/core-service/internal/document/models"
+	"github.com/johnroshan2255/core-service/internal/tenant/tenanttest"
+	"gorm.io/gorm"
+)
+
+func newTestDocument() *models.Document {
+	expiry := time.Now().AddDate(0, 0, 10)
+	return &models.Document{
+		UserUUID:   tenanttest.OwnerUUID,
+		Name:       "Passport",
+		Category:   models.DocumentCategoryLicense,
+		Type:       models.DocumentTypePDF,
+		FileName:   "passport.pdf",
+		FilePath:   "/files/passport.pdf",
+		ExpiryDate: &expiry,
+		Status:     models.DocumentStatusActive,
+	}
+}
+
+func TestDocumentsAreIsolatedByTenant(t *testing.T) {
+	db := tenanttest.Open(t, &models.Document{})
+	repo := NewGORMRepository(db)
+
+	doc := newTestDocument()
+	if err := repo.Create(tenanttest.TenantB, doc); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if _, err := repo.GetByID(tenanttest.TenantA, doc.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("GetByID from another tenant: got %v, want ErrRecordNotFound", err)
+	}
+	if _, err := repo.GetByUUID(tenanttest.TenantA, tenanttest.OwnerUUID, doc.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("GetByUUID from another tenant: got %v, want ErrRecordNotFound", err)
+	}
+	if docs, err := repo.GetByUserUUID(tenanttest.TenantA, tenanttest.OwnerUUID, 0, 0); err != nil || len(docs) != 0 {
+		t.Fatalf("GetByUserUUID from another tenant: got %d documents, %v", len(docs), err)
+	}
+	if docs, err := repo.GetUpcomingExpiries(tenanttest.TenantA, tenanttest.OwnerUUID, time.Now()); err != nil || len(docs) != 0 {
+		t.Fatalf("GetUpcomingExpiries from another tenant: got %d documents, %v", len(docs), err)
+	}
+
+	hijack := *doc
+	hijack.Name = "Hijacked"
+	tenanttest.AssertWritesIsolated(t, db, []string{"documents"},
+		tenanttest.CrossTenantWrite{Name: "Update", Write: func(ctx context.Context) error { return repo.Update(ctx, &hijack) }},
+		tenanttest.CrossTenantWrite{Name: "UpdateNotificationSent", Write: func(ctx context.Context) error { return repo.UpdateNotificationSent(ctx, doc.ID, true) }},
+		tenanttest.CrossTenantWrite{Name: "Delete", Write: func(ctx context.Context) error { return repo.Delete(ctx, doc.ID) }},
+	)
+}
+
+func TestCalendarFeedsAreIsolatedByTenant(t *testing.T) {
+	db := tenanttest.Open(t, &models.CalendarFeed{})
+	repo := NewGORMRepository(db)
+
+	if err := repo.UpsertCalendarFeed(tenanttest.TenantB, &models.CalendarFeed{UserUUID: tenanttest.OwnerUUID, TokenHash: "owner"}); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	if _, err := repo.GetCalendarFeedByTokenHash(tenanttest.TenantA, "owner"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("lookup from another tenant: got %v, want ErrRecordNotFound", err)
+	}
+
+	// An upsert from another tenant conflicts on user_uuid but must not take the row over
+	tenanttest.AssertWritesIsolated(t, db, []string{"calendar_feeds"},
+		tenanttest.CrossTenantWrite{Name: "UpsertCalendarFeed", Write: func(ctx context.Context) error {
+			return repo.UpsertCalendarFeed(ctx, &models.CalendarFeed{UserUUID: tenanttest.OwnerUUID, TokenHash: "attacker"})
+		}},
+	)
+}
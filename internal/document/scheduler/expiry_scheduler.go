@@ -2,77 +2,50 @@ package scheduler
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
 	"log"
+	"os"
 	"time"
 
 	"github.com/johnroshan2255/core-service/internal/config"
+	"github.com/johnroshan2255/core-service/internal/document/calendar"
 	"github.com/johnroshan2255/core-service/internal/document/models"
 	"github.com/johnroshan2255/core-service/internal/document/service"
+	"github.com/johnroshan2255/core-service/internal/tenant"
+	notificationgrpc "github.com/johnroshan2255/core-service/internal/transport/grpc/notification"
 	notificationv1 "github.com/johnroshan2255/core-service/proto/notification/v1"
 	"github.com/robfig/cron/v3"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/credentials/insecure"
-	"google.golang.org/grpc/metadata"
 )
 
 type ExpiryScheduler struct {
 	documentService *service.Service
-	conn            *grpc.ClientConn
-	client          notificationv1.NotificationServiceClient
-	serviceKey      string
+	client          *notificationgrpc.Client
 	daysBeforeExpiry int
 	cronScheduler   *cron.Cron
-}
-
-func NewExpiryScheduler(docService *service.Service, cfg *config.Config, daysBeforeExpiry int) *ExpiryScheduler {
-	var conn *grpc.ClientConn
-	var client notificationv1.NotificationServiceClient
 
-	if cfg.CoreNotificationServiceAddr != "" {
-		var creds credentials.TransportCredentials
-		if cfg.TLSEnabled {
-			config := &tls.Config{
-				InsecureSkipVerify: false,
-			}
-			creds = credentials.NewTLS(config)
-			log.Printf("ExpiryScheduler: Connecting to notification service with TLS at %s", cfg.CoreNotificationServiceAddr)
-		} else {
-			creds = insecure.NewCredentials()
-			log.Printf("ExpiryScheduler: WARNING: Connecting to notification service without TLS at %s", cfg.CoreNotificationServiceAddr)
-		}
+	// attachFiles sends the document file along with its expiry notification,
+	// as long as a user's files stay within maxAttachmentBytes
+	attachFiles        bool
+	maxAttachmentBytes int64
+}
 
-		var err error
-		conn, err = grpc.NewClient(cfg.CoreNotificationServiceAddr, grpc.WithTransportCredentials(creds))
-		if err != nil {
-			log.Printf("ExpiryScheduler: Failed to connect to notification service: %v", err)
-		} else {
-			client = notificationv1.NewNotificationServiceClient(conn)
-			log.Printf("ExpiryScheduler: Connected to notification service at %s", cfg.CoreNotificationServiceAddr)
-		}
-	} else {
-		log.Printf("ExpiryScheduler: CoreNotificationServiceAddr not set. Notifications will be disabled.")
+// NewExpiryScheduler creates the scheduler. client is shared with the rest of the
+// process and may be nil, in which case notifications are disabled.
+func NewExpiryScheduler(docService *service.Service, client *notificationgrpc.Client, cfg *config.Config, daysBeforeExpiry int) *ExpiryScheduler {
+	if client == nil {
+		log.Printf("ExpiryScheduler: Notification client not configured. Notifications will be disabled.")
 	}
 
 	return &ExpiryScheduler{
 		documentService:    docService,
-		conn:                conn,
 		client:              client,
-		serviceKey:          cfg.ServiceKey,
 		daysBeforeExpiry:   daysBeforeExpiry,
 		cronScheduler:      cron.New(cron.WithSeconds()),
+		attachFiles:        cfg.DocumentExpiryAttachFile,
+		maxAttachmentBytes: int64(cfg.MaxAttachmentBytes),
 	}
 }
 
-func (s *ExpiryScheduler) Close() error {
-	if s.conn != nil {
-		return s.conn.Close()
-	}
-	return nil
-}
-
 func (s *ExpiryScheduler) Start(ctx context.Context) {
 	schedule := "0 0 9 * * *"
 	
@@ -94,8 +67,18 @@ func (s *ExpiryScheduler) Stop() {
 	log.Printf("ExpiryScheduler: Stopped")
 }
 
+// userDigest collects the documents to report to a single user
+type userDigest struct {
+	userUUID  string
+	documents []*notificationv1.DigestDocument
+	expiring  []uint
+	attached  int64
+}
+
 func (s *ExpiryScheduler) checkExpiringDocuments(ctx context.Context) {
 	log.Printf("ExpiryScheduler: Checking for expiring documents...")
+	// The check covers every tenant's documents
+	ctx = tenant.Unscoped(ctx)
 	
 	docs, err := s.documentService.GetExpiringDocuments(ctx, s.daysBeforeExpiry)
 	if err != nil {
@@ -105,17 +88,6 @@ func (s *ExpiryScheduler) checkExpiringDocuments(ctx context.Context) {
 	
 	log.Printf("ExpiryScheduler: Found %d expiring documents", len(docs))
 	
-	for _, doc := range docs {
-		if err := s.sendExpiryNotification(ctx, doc); err != nil {
-			log.Printf("ExpiryScheduler: Failed to send notification for document %d: %v", doc.ID, err)
-			continue
-		}
-		
-		if err := s.documentService.MarkNotificationSent(ctx, doc.ID); err != nil {
-			log.Printf("ExpiryScheduler: Failed to mark notification sent for document %d: %v", doc.ID, err)
-		}
-	}
-	
 	expiredDocs, err := s.documentService.GetExpiredDocuments(ctx)
 	if err != nil {
 		log.Printf("ExpiryScheduler: Failed to get expired documents: %v", err)
@@ -124,119 +96,129 @@ func (s *ExpiryScheduler) checkExpiringDocuments(ctx context.Context) {
 	
 	log.Printf("ExpiryScheduler: Found %d expired documents", len(expiredDocs))
 	
-	for _, doc := range expiredDocs {
-		if err := s.sendExpiredNotification(ctx, doc); err != nil {
-			log.Printf("ExpiryScheduler: Failed to send expired notification for document %d: %v", doc.ID, err)
+	digests := make(map[string]*userDigest)
+	var order []string
+	add := func(doc *models.Document, isExpired bool) {
+		digest, ok := digests[doc.UserUUID]
+		if !ok {
+			digest = &userDigest{userUUID: doc.UserUUID}
+			digests[doc.UserUUID] = digest
+			order = append(order, doc.UserUUID)
+		}
+		document := digestDocument(doc, isExpired)
+		if !isExpired && doc.ExpiryDate != nil {
+			document.Attachments = append(document.Attachments, &notificationv1.Attachment{
+				Filename:    calendar.Filename(doc),
+				ContentType: calendar.ContentType,
+				Content:     calendar.Build("", []models.Document{*doc}, time.Now()),
+			})
+		}
+		if attachment := s.attachment(doc, digest); attachment != nil {
+			document.Attachments = append(document.Attachments, attachment)
+		}
+		digest.documents = append(digest.documents, document)
+		if !isExpired {
+			digest.expiring = append(digest.expiring, doc.ID)
 		}
-	}
-}
-
-func (s *ExpiryScheduler) createContextWithAuth(ctx context.Context) context.Context {
-	if s.serviceKey == "" {
-		return ctx
-	}
-	md := metadata.New(map[string]string{
-		"service-key": s.serviceKey,
-	})
-	return metadata.NewOutgoingContext(ctx, md)
-}
-
-func (s *ExpiryScheduler) sendExpiryNotification(ctx context.Context, doc *models.Document) error {
-	if s.client == nil {
-		log.Printf("ExpiryScheduler: Notification client not available, skipping notification for document %d", doc.ID)
-		return nil
 	}
 	
-	daysUntilExpiry := 0
-	if doc.ExpiryDate != nil {
-		daysUntilExpiry = int(time.Until(*doc.ExpiryDate).Hours() / 24)
+	for i := range expiredDocs {
+		add(&expiredDocs[i], true)
 	}
-	
-	userEmail := s.getUserEmailFromDocument(doc)
-	if userEmail == "" {
-		return fmt.Errorf("user email not found for document")
+	for i := range docs {
+		add(&docs[i], false)
 	}
 	
+	for _, userUUID := range order {
+		digest := digests[userUUID]
+		if err := s.sendDigest(ctx, digest); err != nil {
+			log.Printf("ExpiryScheduler: Failed to send digest to user %s: %v", userUUID, err)
+			continue
+		}
+		
+		for _, id := range digest.expiring {
+			if err := s.documentService.MarkNotificationSent(ctx, id); err != nil {
+				log.Printf("ExpiryScheduler: Failed to mark notification sent for document %d: %v", id, err)
+			}
+		}
+	}
+}
+
+func digestDocument(doc *models.Document, isExpired bool) *notificationv1.DigestDocument {
 	expiryDateStr := ""
+	daysUntilExpiry := 0
 	if doc.ExpiryDate != nil {
 		expiryDateStr = doc.ExpiryDate.Format(time.RFC3339)
+		daysUntilExpiry = int(time.Until(*doc.ExpiryDate).Hours() / 24)
 	}
 	
-	message := fmt.Sprintf(
-		"Your document '%s' (Category: %s) will expire in %d days on %s. Please renew it soon.",
-		doc.Name,
-		doc.Category,
-		daysUntilExpiry,
-		doc.ExpiryDate.Format("2006-01-02"),
-	)
-	
-	ctx = s.createContextWithAuth(ctx)
-	req := &notificationv1.DocumentExpiryRequest{
-		UserUuid:        doc.UserUUID,
-		Email:           userEmail,
-		DocumentName:   doc.Name,
+	return &notificationv1.DigestDocument{
+		DocumentName:     doc.Name,
 		DocumentCategory: string(doc.Category),
-		ExpiryDate:     expiryDateStr,
-		DaysUntilExpiry: int32(daysUntilExpiry),
-		IsExpired:      false,
-		Message:        message,
+		ExpiryDate:       expiryDateStr,
+		DaysUntilExpiry:  int32(daysUntilExpiry),
+		IsExpired:        isExpired,
 	}
-	
-	_, err := s.client.NotifyDocumentExpiry(ctx, req)
+}
+
+// attachment reads the document file when attachments are enabled and it fits in
+// what is left of the user's attachment budget
+func (s *ExpiryScheduler) attachment(doc *models.Document, digest *userDigest) *notificationv1.Attachment {
+	if !s.attachFiles || doc.FilePath == "" {
+		return nil
+	}
+	if digest.attached+doc.FileSize > s.maxAttachmentBytes {
+		log.Printf("ExpiryScheduler: Not attaching document %d (%d bytes), attachment limit reached for user %s", doc.ID, doc.FileSize, digest.userUUID)
+		return nil
+	}
+
+	content, err := os.ReadFile(doc.FilePath)
 	if err != nil {
-		return fmt.Errorf("failed to send notification: %w", err)
+		log.Printf("ExpiryScheduler: Failed to read file for document %d: %v", doc.ID, err)
+		return nil
+	}
+	if digest.attached+int64(len(content)) > s.maxAttachmentBytes {
+		log.Printf("ExpiryScheduler: Not attaching document %d (%d bytes), attachment limit reached for user %s", doc.ID, len(content), digest.userUUID)
+		return nil
+	}
+	digest.attached += int64(len(content))
+
+	return &notificationv1.Attachment{
+		Filename:    doc.FileName,
+		ContentType: doc.MimeType,
+		Content:     content,
 	}
-	
-	log.Printf("ExpiryScheduler: Sent expiry notification for document %d to user %s", doc.ID, doc.UserUUID)
-	return nil
 }
 
-func (s *ExpiryScheduler) sendExpiredNotification(ctx context.Context, doc *models.Document) error {
+// sendDigest sends one notification covering all of a user's expiring and expired
+// documents. The notification service decides whether to deliver it as a digest or
+// as individual notifications based on the user's preference.
+func (s *ExpiryScheduler) sendDigest(ctx context.Context, digest *userDigest) error {
 	if s.client == nil {
-		log.Printf("ExpiryScheduler: Notification client not available, skipping notification for document %d", doc.ID)
+		log.Printf("ExpiryScheduler: Notification client not available, skipping digest for user %s", digest.userUUID)
 		return nil
 	}
 	
-	userEmail := s.getUserEmailFromDocument(doc)
+	userEmail := s.getUserEmail(digest.userUUID)
 	if userEmail == "" {
-		return fmt.Errorf("user email not found for document")
+		return fmt.Errorf("user email not found")
 	}
 	
-	expiryDateStr := ""
-	daysUntilExpiry := 0
-	if doc.ExpiryDate != nil {
-		expiryDateStr = doc.ExpiryDate.Format(time.RFC3339)
-		daysUntilExpiry = int(time.Until(*doc.ExpiryDate).Hours() / 24)
-	}
-	
-	message := fmt.Sprintf(
-		"Your document '%s' (Category: %s) has expired on %s. Please renew it immediately.",
-		doc.Name,
-		doc.Category,
-		doc.ExpiryDate.Format("2006-01-02"),
-	)
-	
-	ctx = s.createContextWithAuth(ctx)
-	req := &notificationv1.DocumentExpiryRequest{
-		UserUuid:        doc.UserUUID,
-		Email:           userEmail,
-		DocumentName:   doc.Name,
-		DocumentCategory: string(doc.Category),
-		ExpiryDate:     expiryDateStr,
-		DaysUntilExpiry: int32(daysUntilExpiry),
-		IsExpired:      true,
-		Message:        message,
+	req := &notificationv1.DocumentDigestRequest{
+		UserUuid:       digest.userUUID,
+		Email:          userEmail,
+		Documents:      digest.documents,
+		IdempotencyKey: fmt.Sprintf("document-digest:%s:%s", digest.userUUID, time.Now().Format("2006-01-02")),
 	}
 	
-	_, err := s.client.NotifyDocumentExpiry(ctx, req)
-	if err != nil {
-		return fmt.Errorf("failed to send notification: %w", err)
+	if err := s.client.NotifyDocumentDigest(ctx, req); err != nil {
+		return err
 	}
 	
-	log.Printf("ExpiryScheduler: Sent expired notification for document %d to user %s", doc.ID, doc.UserUUID)
+	log.Printf("ExpiryScheduler: Sent digest with %d documents to user %s", len(digest.documents), digest.userUUID)
 	return nil
 }
 
-func (s *ExpiryScheduler) getUserEmailFromDocument(doc *models.Document) string {
-	return fmt.Sprintf("user-%s@example.com", doc.UserUUID)
+func (s *ExpiryScheduler) getUserEmail(userUUID string) string {
+	return fmt.Sprintf("user-%s@example.com", userUUID)
 }
@@ -2,21 +2,39 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	"mime"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/johnroshan2255/core-service/internal/document/calendar"
 	"github.com/johnroshan2255/core-service/internal/document/models"
 	"github.com/johnroshan2255/core-service/internal/document/repos"
+	"github.com/johnroshan2255/core-service/internal/tenant"
 	"gorm.io/gorm"
 )
 
+// ErrDocumentNotFound is returned when a document doesn't exist
+var ErrDocumentNotFound = errors.New("document not found")
+
+// ErrNoExpiryDate is returned when a calendar event is requested for a document without an expiry date
+var ErrNoExpiryDate = errors.New("document has no expiry date")
+
+// StatusPublisher is told about document status changes so they can be pushed to clients in real time
+type StatusPublisher interface {
+	PublishDocumentStatus(userUUID string, documentID uint, documentName, oldStatus, newStatus string)
+}
+
 type Service struct {
-	repo repos.Repository
+	repo      repos.Repository
+	publisher StatusPublisher
 }
 
 func NewService(repo repos.Repository) *Service {
@@ -25,6 +43,17 @@ func NewService(repo repos.Repository) *Service {
 	}
 }
 
+func (s *Service) SetStatusPublisher(publisher StatusPublisher) {
+	s.publisher = publisher
+}
+
+func (s *Service) publishStatus(doc *models.Document, oldStatus models.DocumentStatus) {
+	if s.publisher == nil || oldStatus == doc.Status {
+		return
+	}
+	s.publisher.PublishDocumentStatus(doc.UserUUID, doc.ID, doc.Name, string(oldStatus), string(doc.Status))
+}
+
 func (s *Service) ValidateDocument(fileName, mimeType string, fileSize int64) error {
 	ext := strings.ToLower(filepath.Ext(fileName))
 	
@@ -142,6 +171,7 @@ func (s *Service) CreateDocument(ctx context.Context, userUUID string, doc *mode
 	}
 	
 	log.Printf("DocumentService: Created document %d for user %s", doc.ID, userUUID)
+	s.publishStatus(doc, "")
 	return doc, nil
 }
 
@@ -153,7 +183,7 @@ func (s *Service) GetDocument(ctx context.Context, userUUID string, id uint) (*m
 	doc, err := s.repo.GetByUUID(ctx, userUUID, id)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("document not found")
+			return nil, ErrDocumentNotFound
 		}
 		return nil, fmt.Errorf("failed to get document: %w", err)
 	}
@@ -161,6 +191,21 @@ func (s *Service) GetDocument(ctx context.Context, userUUID string, id uint) (*m
 	return doc, nil
 }
 
+// GetAnyDocument returns a document regardless of owner, for callers with the
+// documents:read_any permission. Every access is logged with the acting user.
+func (s *Service) GetAnyDocument(ctx context.Context, actorUUID string, id uint) (*models.Document, error) {
+	doc, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrDocumentNotFound
+		}
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+
+	log.Printf("DocumentService: User %s accessed document %d owned by %s", actorUUID, doc.ID, doc.UserUUID)
+	return doc, nil
+}
+
 func (s *Service) GetUserDocuments(ctx context.Context, userUUID string, limit, offset int) ([]models.Document, error) {
 	if userUUID == "" {
 		return nil, fmt.Errorf("user UUID is required")
@@ -187,6 +232,7 @@ func (s *Service) UpdateDocument(ctx context.Context, userUUID string, id uint,
 		return fmt.Errorf("failed to get document: %w", err)
 	}
 	
+	oldStatus := doc.Status
 	if name, ok := updates["name"].(string); ok && name != "" {
 		doc.Name = name
 	}
@@ -215,6 +261,7 @@ func (s *Service) UpdateDocument(ctx context.Context, userUUID string, id uint,
 	}
 	
 	log.Printf("DocumentService: Updated document %d for user %s", id, userUUID)
+	s.publishStatus(doc, oldStatus)
 	return nil
 }
 
@@ -259,3 +306,73 @@ func (s *Service) MarkNotificationSent(ctx context.Context, id uint) error {
 	return s.repo.UpdateNotificationSent(ctx, id, true)
 }
 
+
+// RotateCalendarToken issues a new secret calendar feed token for the user, replacing
+// any previous one. The token is only returned here; just its hash is stored.
+func (s *Service) RotateCalendarToken(ctx context.Context, userUUID string) (string, error) {
+	if userUUID == "" {
+		return "", fmt.Errorf("user UUID is required")
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate calendar token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	feed := &models.CalendarFeed{
+		UserUUID:  userUUID,
+		TokenHash: hashCalendarToken(token),
+	}
+	if err := s.repo.UpsertCalendarFeed(ctx, feed); err != nil {
+		return "", fmt.Errorf("failed to save calendar token: %w", err)
+	}
+
+	log.Printf("DocumentService: Rotated calendar feed token for user %s", userUUID)
+	return token, nil
+}
+
+// GetCalendarFeed renders the ICS feed of upcoming expiries for the owner of token
+func (s *Service) GetCalendarFeed(ctx context.Context, token string) ([]byte, error) {
+	if token == "" {
+		return nil, fmt.Errorf("calendar token is required")
+	}
+
+	// The token is what identifies the tenant, so the lookup itself spans every tenant
+	feed, err := s.repo.GetCalendarFeedByTokenHash(tenant.Unscoped(ctx), hashCalendarToken(token))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("calendar feed not found")
+		}
+		return nil, fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+
+	// The feed URL is unauthenticated, so scope the lookup to the tenant the token was issued in
+	ctx = tenant.WithTenant(ctx, feed.TenantID)
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	docs, err := s.repo.GetUpcomingExpiries(ctx, feed.UserUUID, today)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get documents: %w", err)
+	}
+
+	return calendar.Build("Document expiries", docs, now), nil
+}
+
+// GetDocumentCalendar renders a calendar event for a single document's expiry date
+func (s *Service) GetDocumentCalendar(ctx context.Context, userUUID string, id uint) ([]byte, error) {
+	doc, err := s.GetDocument(ctx, userUUID, id)
+	if err != nil {
+		return nil, err
+	}
+	if doc.ExpiryDate == nil {
+		return nil, ErrNoExpiryDate
+	}
+	return calendar.Build("", []models.Document{*doc}, time.Now()), nil
+}
+
+func hashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,31 @@
+package idempotency
+
+import "time"
+
+type Status string
+
+const (
+	// StatusPending marks a key whose call is still running on some instance
+	StatusPending Status = "pending"
+	// StatusDone marks a key whose successful response can be replayed
+	StatusDone Status = "done"
+)
+
+// Record is a claimed idempotency key. KeyHash is unique, so only one instance can
+// run a call for a key at a time; pending records whose lease lapsed and done records
+// past ExpiresAt can be claimed again.
+type Record struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	KeyHash     string    `gorm:"type:char(64);uniqueIndex;not null" json:"key_hash"`
+	Method      string    `gorm:"type:varchar(255)" json:"method"`
+	Status      Status    `gorm:"type:varchar(20);not null" json:"status"`
+	Response    []byte    `gorm:"type:bytea" json:"-"`
+	LockedUntil time.Time `json:"locked_until"`
+	ExpiresAt   time.Time `gorm:"index" json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (Record) TableName() string {
+	return "idempotency_keys"
+}
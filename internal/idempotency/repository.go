@@ -0,0 +1,109 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GORMRepository stores idempotency keys in Postgres. It implements
+// middleware.IdempotencyStore.
+type GORMRepository struct {
+	db *gorm.DB
+}
+
+func NewGORMRepository(db *gorm.DB) *GORMRepository {
+	return &GORMRepository{
+		db: db,
+	}
+}
+
+// Claim reserves key for lease. It returns false while another call holds the key or
+// while a completed response for it is still within its window.
+func (r *GORMRepository) Claim(ctx context.Context, key, method string, lease, window time.Duration) (bool, error) {
+	now := time.Now()
+	record := Record{
+		KeyHash:     hashKey(key),
+		Method:      method,
+		Status:      StatusPending,
+		LockedUntil: now.Add(lease),
+		ExpiresAt:   now.Add(window),
+	}
+
+	table := Record{}.TableName()
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "key_hash"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"status":       StatusPending,
+			"response":     nil,
+			"locked_until": record.LockedUntil,
+			"expires_at":   record.ExpiresAt,
+			"updated_at":   now,
+		}),
+		// Take the key over only from an expired response or an abandoned call
+		Where: clause.Where{Exprs: []clause.Expression{clause.Or(
+			clause.Lt{Column: clause.Column{Table: table, Name: "expires_at"}, Value: now},
+			clause.And(
+				clause.Eq{Column: clause.Column{Table: table, Name: "status"}, Value: StatusPending},
+				clause.Lt{Column: clause.Column{Table: table, Name: "locked_until"}, Value: now},
+			),
+		)}},
+	}).Create(&record)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Response returns the stored response for key, or nil while the call is still running,
+// after it failed, or once the response expired
+func (r *GORMRepository) Response(ctx context.Context, key string) ([]byte, error) {
+	var record Record
+	err := r.db.WithContext(ctx).
+		Where("key_hash = ? AND status = ? AND expires_at > ?", hashKey(key), StatusDone, time.Now()).
+		First(&record).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return record.Response, nil
+}
+
+// Complete stores the response of a successful call for window
+func (r *GORMRepository) Complete(ctx context.Context, key string, response []byte, window time.Duration) error {
+	return r.db.WithContext(ctx).Model(&Record{}).
+		Where("key_hash = ? AND status = ?", hashKey(key), StatusPending).
+		Updates(map[string]interface{}{
+			"status":     StatusDone,
+			"response":   response,
+			"expires_at": time.Now().Add(window),
+		}).Error
+}
+
+// Release drops the claim of a failed call so a retry can run it again
+func (r *GORMRepository) Release(ctx context.Context, key string) error {
+	return r.db.WithContext(ctx).
+		Where("key_hash = ? AND status = ?", hashKey(key), StatusPending).
+		Delete(&Record{}).Error
+}
+
+// DeleteExpired removes responses past their window and pending claims whose lease lapsed
+func (r *GORMRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	now := time.Now()
+	result := r.db.WithContext(ctx).
+		Where("expires_at < ? AND (status = ? OR locked_until < ?)", now, StatusDone, now).
+		Delete(&Record{})
+	return result.RowsAffected, result.Error
+}
+
+// hashKey keeps the unique index small however long callers' keys are
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
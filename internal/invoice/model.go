@@ -0,0 +1,52 @@
+package invoice
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Status string
+
+const (
+	StatusDraft  Status = "draft"
+	StatusIssued Status = "issued"
+	StatusPaid   Status = "paid"
+	StatusVoid   Status = "void"
+)
+
+// Invoice is a bill for a user. AmountCents is in the currency's minor unit, so sums
+// and comparisons are exact.
+type Invoice struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	UUID        string         `gorm:"type:uuid;uniqueIndex;not null" json:"uuid"`
+	UserUUID    string         `gorm:"type:uuid;index;not null" json:"user_uuid"`
+	TenantID    string         `gorm:"type:varchar(255);index;not null;default:''" json:"tenant_id"`
+	Number      string         `gorm:"type:varchar(50);uniqueIndex;not null" json:"number"`
+	Status      Status         `gorm:"type:varchar(20);default:'draft';index" json:"status"`
+	AmountCents int64          `gorm:"not null;default:0" json:"amount_cents"`
+	Currency    string         `gorm:"type:varchar(3);default:'USD'" json:"currency"`
+	Description string         `gorm:"type:text" json:"description"`
+	DueDate     *time.Time     `json:"due_date,omitempty"`
+	IssuedAt    *time.Time     `json:"issued_at,omitempty"`
+	PaidAt      *time.Time     `json:"paid_at,omitempty"`
+	CreatedBy   string         `gorm:"type:uuid" json:"created_by"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// MigrateAmountCents moves amounts from the legacy decimal amount column into
+// amount_cents and drops the old column. It runs after AutoMigrate and does nothing
+// once the old column is gone.
+func MigrateAmountCents(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&Invoice{}, "amount") {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("UPDATE invoices SET amount_cents = ROUND(amount * 100) WHERE amount IS NOT NULL").Error; err != nil {
+			return err
+		}
+		return tx.Migrator().DropColumn(&Invoice{}, "amount")
+	})
+}
@@ -0,0 +1,76 @@
+package invoice
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// ListFilter narrows List. Empty fields match every invoice.
+type ListFilter struct {
+	UserUUID   string
+	Status     Status
+	HideDrafts bool
+}
+
+type Repository interface {
+	Create(ctx context.Context, invoice *Invoice) error
+	GetByUUID(ctx context.Context, invoiceUUID string) (*Invoice, error)
+	List(ctx context.Context, filter ListFilter, limit, offset int) ([]Invoice, error)
+	Update(ctx context.Context, invoice *Invoice) error
+	Delete(ctx context.Context, invoiceUUID string) error
+}
+
+type GORMRepository struct {
+	db *gorm.DB
+}
+
+func NewGORMRepository(db *gorm.DB) *GORMRepository {
+	return &GORMRepository{
+		db: db,
+	}
+}
+
+func (r *GORMRepository) Create(ctx context.Context, invoice *Invoice) error {
+	return r.db.WithContext(ctx).Create(invoice).Error
+}
+
+func (r *GORMRepository) GetByUUID(ctx context.Context, invoiceUUID string) (*Invoice, error) {
+	var invoice Invoice
+	if err := r.db.WithContext(ctx).Where("uuid = ?", invoiceUUID).First(&invoice).Error; err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+func (r *GORMRepository) List(ctx context.Context, filter ListFilter, limit, offset int) ([]Invoice, error) {
+	var invoices []Invoice
+	query := r.db.WithContext(ctx).Order("created_at DESC")
+	if filter.UserUUID != "" {
+		query = query.Where("user_uuid = ?", filter.UserUUID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.HideDrafts {
+		query = query.Where("status <> ?", StatusDraft)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	if err := query.Find(&invoices).Error; err != nil {
+		return nil, err
+	}
+	return invoices, nil
+}
+
+func (r *GORMRepository) Update(ctx context.Context, invoice *Invoice) error {
+	return r.db.WithContext(ctx).Save(invoice).Error
+}
+
+func (r *GORMRepository) Delete(ctx context.Context, invoiceUUID string) error {
+	return r.db.WithContext(ctx).Where("uuid = ?", invoiceUUID).Delete(&Invoice{}).Error
+}
@@ -0,0 +1,42 @@
+package invoice
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/johnroshan2255/core-service/internal/tenant/tenanttest"
+	"gorm.io/gorm"
+)
+
+func TestInvoicesAreIsolatedByTenant(t *testing.T) {
+	db := tenanttest.Open(t, &Invoice{})
+	repo := NewGORMRepository(db)
+
+	invoice := &Invoice{
+		UUID:        "0b6e2c1a-9d4f-4e3b-8a7c-5f6e7d8c9b0a",
+		UserUUID:    tenanttest.OwnerUUID,
+		Number:      "INV-0001",
+		Status:      StatusDraft,
+		AmountCents: 1999,
+	}
+	if err := repo.Create(tenanttest.TenantB, invoice); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if _, err := repo.GetByUUID(tenanttest.TenantA, invoice.UUID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("GetByUUID from another tenant: got %v, want ErrRecordNotFound", err)
+	}
+	if invoices, err := repo.List(tenanttest.TenantA, ListFilter{UserUUID: tenanttest.OwnerUUID}, 0, 0); err != nil || len(invoices) != 0 {
+		t.Fatalf("List from another tenant: got %d invoices, %v", len(invoices), err)
+	}
+
+	hijack := *invoice
+	hijack.AmountCents = 1
+	tenanttest.AssertWritesIsolated(t, db, []string{"invoices"},
+		// Save finds no row in the caller's tenant and falls back to an upsert, whose
+		// conflict update is guarded to the caller's tenant
+		tenanttest.CrossTenantWrite{Name: "Update", Write: func(ctx context.Context) error { return repo.Update(ctx, &hijack) }},
+		tenanttest.CrossTenantWrite{Name: "Delete", Write: func(ctx context.Context) error { return repo.Delete(ctx, invoice.UUID) }},
+	)
+}
@@ -0,0 +1,235 @@
+package invoice
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvoiceNotFound   = errors.New("invoice not found")
+	ErrInvoiceLocked     = errors.New("only draft invoices can be edited or deleted; void the invoice instead")
+	ErrInvalidTransition = errors.New("invalid invoice status change")
+)
+
+// transitions lists the statuses each status may move to
+var transitions = map[Status][]Status{
+	StatusDraft:  {StatusIssued, StatusVoid},
+	StatusIssued: {StatusPaid, StatusVoid},
+}
+
+type Service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) *Service {
+	return &Service{
+		repo: repo,
+	}
+}
+
+// CreateInvoice adds a draft invoice for invoice.UserUUID on behalf of actorUUID
+func (s *Service) CreateInvoice(ctx context.Context, actorUUID string, invoice *Invoice) (*Invoice, error) {
+	if invoice.UserUUID == "" {
+		return nil, fmt.Errorf("user UUID is required")
+	}
+	if invoice.AmountCents <= 0 {
+		return nil, fmt.Errorf("amount must be greater than zero")
+	}
+	currency, err := normalizeCurrency(invoice.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	invoice.UUID, err = newUUID()
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Number == "" {
+		invoice.Number, err = newInvoiceNumber(time.Now())
+		if err != nil {
+			return nil, err
+		}
+	}
+	invoice.ID = 0
+	invoice.Currency = currency
+	invoice.Status = StatusDraft
+	invoice.IssuedAt = nil
+	invoice.PaidAt = nil
+	invoice.CreatedBy = actorUUID
+
+	if err := s.repo.Create(ctx, invoice); err != nil {
+		return nil, fmt.Errorf("failed to create invoice: %w", err)
+	}
+
+	log.Printf("InvoiceService: User %s created invoice %s for user %s", actorUUID, invoice.Number, invoice.UserUUID)
+	return invoice, nil
+}
+
+func (s *Service) GetInvoice(ctx context.Context, invoiceUUID string) (*Invoice, error) {
+	invoice, err := s.repo.GetByUUID(ctx, invoiceUUID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, fmt.Errorf("failed to get invoice: %w", err)
+	}
+	return invoice, nil
+}
+
+// GetUserInvoice returns one of the user's own invoices. Drafts stay hidden until issued.
+func (s *Service) GetUserInvoice(ctx context.Context, userUUID, invoiceUUID string) (*Invoice, error) {
+	if userUUID == "" {
+		return nil, fmt.Errorf("user UUID is required")
+	}
+
+	invoice, err := s.GetInvoice(ctx, invoiceUUID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.UserUUID != userUUID || invoice.Status == StatusDraft {
+		return nil, ErrInvoiceNotFound
+	}
+	return invoice, nil
+}
+
+func (s *Service) ListInvoices(ctx context.Context, filter ListFilter, limit, offset int) ([]Invoice, error) {
+	invoices, err := s.repo.List(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invoices: %w", err)
+	}
+	return invoices, nil
+}
+
+// ListUserInvoices returns the user's issued, paid and void invoices
+func (s *Service) ListUserInvoices(ctx context.Context, userUUID string, limit, offset int) ([]Invoice, error) {
+	if userUUID == "" {
+		return nil, fmt.Errorf("user UUID is required")
+	}
+	return s.ListInvoices(ctx, ListFilter{UserUUID: userUUID, HideDrafts: true}, limit, offset)
+}
+
+// UpdateInvoice edits a draft's amount, currency, description or due date, and moves
+// an invoice between statuses: draft -> issued -> paid, with draft or issued -> void.
+func (s *Service) UpdateInvoice(ctx context.Context, actorUUID, invoiceUUID string, updates map[string]interface{}) (*Invoice, error) {
+	invoice, err := s.GetInvoice(ctx, invoiceUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	editing := false
+	if amount, ok := updates["amount_cents"].(int64); ok {
+		if amount <= 0 {
+			return nil, fmt.Errorf("amount must be greater than zero")
+		}
+		invoice.AmountCents = amount
+		editing = true
+	}
+	if currency, ok := updates["currency"].(string); ok {
+		if invoice.Currency, err = normalizeCurrency(currency); err != nil {
+			return nil, err
+		}
+		editing = true
+	}
+	if description, ok := updates["description"].(string); ok {
+		invoice.Description = description
+		editing = true
+	}
+	if dueDate, ok := updates["due_date"].(*time.Time); ok && dueDate != nil {
+		invoice.DueDate = dueDate
+		editing = true
+	}
+	if editing && invoice.Status != StatusDraft {
+		return nil, ErrInvoiceLocked
+	}
+
+	oldStatus := invoice.Status
+	if status, ok := updates["status"].(string); ok && Status(status) != invoice.Status {
+		if !canTransition(invoice.Status, Status(status)) {
+			return nil, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, invoice.Status, status)
+		}
+		now := time.Now()
+		invoice.Status = Status(status)
+		switch invoice.Status {
+		case StatusIssued:
+			invoice.IssuedAt = &now
+		case StatusPaid:
+			invoice.PaidAt = &now
+		}
+	}
+
+	if err := s.repo.Update(ctx, invoice); err != nil {
+		return nil, fmt.Errorf("failed to update invoice: %w", err)
+	}
+
+	if invoice.Status != oldStatus {
+		log.Printf("InvoiceService: User %s changed invoice %s from %s to %s", actorUUID, invoice.Number, oldStatus, invoice.Status)
+	} else {
+		log.Printf("InvoiceService: User %s updated invoice %s", actorUUID, invoice.Number)
+	}
+	return invoice, nil
+}
+
+// DeleteInvoice removes a draft. Issued invoices are kept for the record and voided instead.
+func (s *Service) DeleteInvoice(ctx context.Context, actorUUID, invoiceUUID string) error {
+	invoice, err := s.GetInvoice(ctx, invoiceUUID)
+	if err != nil {
+		return err
+	}
+	if invoice.Status != StatusDraft {
+		return ErrInvoiceLocked
+	}
+
+	if err := s.repo.Delete(ctx, invoiceUUID); err != nil {
+		return fmt.Errorf("failed to delete invoice: %w", err)
+	}
+
+	log.Printf("InvoiceService: User %s deleted invoice %s", actorUUID, invoice.Number)
+	return nil
+}
+
+func canTransition(from, to Status) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return "USD", nil
+	}
+	if len(currency) != 3 {
+		return "", fmt.Errorf("currency must be a three-letter ISO 4217 code")
+	}
+	return currency, nil
+}
+
+// newInvoiceNumber returns a number like INV-20240131-9F2C4A
+func newInvoiceNumber(now time.Time) (string, error) {
+	b := make([]byte, 3)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate invoice number: %w", err)
+	}
+	return fmt.Sprintf("INV-%s-%X", now.Format("20060102"), b), nil
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate invoice UUID: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -4,28 +4,35 @@ import (
 	"context"
 	"errors"
 	"log"
+	"time"
 
+	"github.com/johnroshan2255/core-service/internal/tenant"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
 var (
-	ErrMissingServiceKey = errors.New("service key is missing in request metadata")
-	ErrInvalidServiceKey = errors.New("invalid service key")
+	ErrMissingServiceKey        = errors.New("service key is missing in request metadata")
+	ErrInvalidServiceKey        = errors.New("invalid service key")
+	ErrUnknownClientCertificate = errors.New("client certificate is not mapped to a service")
 )
 
-// BackendAuthInterceptor validates service keys for inter-service gRPC communication
-// It checks for "service-key" in the gRPC metadata and validates it against the configured service key
+// BackendAuthInterceptor authenticates inter-service gRPC communication
+// Callers are identified by the SANs of a verified mTLS client certificate or, failing
+// that, by the "service-key" gRPC metadata. Methods the matching credential isn't
+// allowed to call are rejected, and the caller's ServiceIdentity is attached to the context
 type BackendAuthInterceptor struct {
-	serviceKey string
+	credentials *ServiceCredentials
 }
 
 // NewBackendAuthInterceptor creates a new backend authentication interceptor
-func NewBackendAuthInterceptor(serviceKey string) *BackendAuthInterceptor {
+func NewBackendAuthInterceptor(credentials *ServiceCredentials) *BackendAuthInterceptor {
 	return &BackendAuthInterceptor{
-		serviceKey: serviceKey,
+		credentials: credentials,
 	}
 }
 
@@ -37,23 +44,10 @@ func (i *BackendAuthInterceptor) UnaryInterceptor() grpc.UnaryServerInterceptor
 		info *grpc.UnaryServerInfo,
 		handler grpc.UnaryHandler,
 	) (interface{}, error) {
-		md, ok := metadata.FromIncomingContext(ctx)
-		if !ok {
-			log.Printf("BackendAuthInterceptor: No metadata found in request")
-			return nil, status.Errorf(codes.Unauthenticated, "missing metadata")
+		ctx, err := i.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
 		}
-
-		serviceKeys := md.Get("service-key")
-		if len(serviceKeys) == 0 || serviceKeys[0] == "" {
-			log.Printf("BackendAuthInterceptor: Service key missing in request")
-			return nil, status.Errorf(codes.Unauthenticated, ErrMissingServiceKey.Error())
-		}
-
-		if serviceKeys[0] != i.serviceKey {
-			log.Printf("BackendAuthInterceptor: Invalid service key provided")
-			return nil, status.Errorf(codes.Unauthenticated, ErrInvalidServiceKey.Error())
-		}
-
 		return handler(ctx, req)
 	}
 }
@@ -66,24 +60,80 @@ func (i *BackendAuthInterceptor) StreamInterceptor() grpc.StreamServerIntercepto
 		info *grpc.StreamServerInfo,
 		handler grpc.StreamHandler,
 	) error {
-		md, ok := metadata.FromIncomingContext(ss.Context())
-		if !ok {
-			log.Printf("BackendAuthInterceptor: No metadata found in stream request")
-			return status.Errorf(codes.Unauthenticated, "missing metadata")
+		ctx, err := i.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
 		}
+		return handler(srv, &identifiedStream{ServerStream: ss, ctx: ctx})
+	}
+}
 
-		serviceKeys := md.Get("service-key")
-		if len(serviceKeys) == 0 || serviceKeys[0] == "" {
-			log.Printf("BackendAuthInterceptor: Service key missing in stream request")
-			return status.Errorf(codes.Unauthenticated, ErrMissingServiceKey.Error())
-		}
+// authenticate checks the caller's certificate or key and method allowlist and returns
+// ctx with the caller's identity
+func (i *BackendAuthInterceptor) authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	if identity, credential, ok := i.authenticateCertificate(ctx); ok {
+		return i.authorize(ctx, fullMethod, credential, identity)
+	}
 
-		if serviceKeys[0] != i.serviceKey {
-			log.Printf("BackendAuthInterceptor: Invalid service key provided in stream")
-			return status.Errorf(codes.Unauthenticated, ErrInvalidServiceKey.Error())
-		}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		log.Printf("BackendAuthInterceptor: No metadata found in request to %s", fullMethod)
+		return nil, status.Errorf(codes.Unauthenticated, "missing metadata")
+	}
 
-		return handler(srv, ss)
+	serviceKeys := md.Get("service-key")
+	if len(serviceKeys) == 0 || serviceKeys[0] == "" {
+		log.Printf("BackendAuthInterceptor: Service key missing in request to %s", fullMethod)
+		return nil, status.Errorf(codes.Unauthenticated, ErrMissingServiceKey.Error())
 	}
+
+	credential, err := i.credentials.Authenticate(serviceKeys[0], time.Now())
+	if err != nil {
+		log.Printf("BackendAuthInterceptor: Invalid service key provided for %s", fullMethod)
+		return nil, status.Errorf(codes.Unauthenticated, ErrInvalidServiceKey.Error())
+	}
+
+	return i.authorize(ctx, fullMethod, credential, ServiceIdentity{Name: credential.Name, Method: "service-key"})
 }
 
+// authenticateCertificate maps a verified client certificate to a credential. Callers
+// without one, or with one no credential claims, fall back to their service key.
+func (i *BackendAuthInterceptor) authenticateCertificate(ctx context.Context) (ServiceIdentity, *ServiceCredential, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ServiceIdentity{}, nil, false
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return ServiceIdentity{}, nil, false
+	}
+
+	leaf := tlsInfo.State.VerifiedChains[0][0]
+	credential, san, err := i.credentials.AuthenticateCertificate(leaf, time.Now())
+	if err != nil {
+		log.Printf("BackendAuthInterceptor: Client certificate %q is not mapped to a service", leaf.Subject.String())
+		return ServiceIdentity{}, nil, false
+	}
+	return ServiceIdentity{Name: credential.Name, Method: "certificate", SAN: san}, credential, true
+}
+
+func (i *BackendAuthInterceptor) authorize(ctx context.Context, fullMethod string, credential *ServiceCredential, identity ServiceIdentity) (context.Context, error) {
+	if !credential.Allows(fullMethod) {
+		log.Printf("BackendAuthInterceptor: Service %s is not allowed to call %s", credential.Name, fullMethod)
+		return nil, status.Errorf(codes.PermissionDenied, "service %s is not allowed to call %s", credential.Name, fullMethod)
+	}
+
+	log.Printf("BackendAuthInterceptor: Service %s called %s (%s)", identity.Name, fullMethod, identity.Method)
+	// Backend services act for the system rather than one tenant
+	return tenant.Unscoped(WithServiceIdentity(ctx, identity)), nil
+}
+
+// identifiedStream carries the authenticated context into stream handlers
+type identifiedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identifiedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// claimsContextKey is where AuthMiddleware stores the token claims on the gin context
+const claimsContextKey = "auth_claims"
+
+// Claims are the JWT claims this service understands
+type Claims struct {
+	UserUUID string `json:"user_uuid"`
+	// LegacyUUID is the older "uuid" claim, used when user_uuid is absent
+	LegacyUUID string `json:"uuid,omitempty"`
+	Email      string `json:"email"`
+	// EmailVerified is the OIDC email_verified claim
+	EmailVerified bool   `json:"email_verified"`
+	Role          string `json:"role"`
+	TenantID      string `json:"tenant_id"`
+	// SessionID names the server-side session of tokens issued by this service
+	SessionID string `json:"session_id,omitempty"`
+	// AMR lists the authentication methods used to sign in (RFC 8176)
+	AMR []string `json:"amr,omitempty"`
+	// Scope is the space-delimited OAuth scope claim; some issuers send scp as a list instead
+	Scope  string   `json:"scope,omitempty"`
+	Scopes []string `json:"scp,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// UUID returns the authenticated user's UUID, falling back to the legacy uuid and
+// standard sub claims
+func (c *Claims) UUID() string {
+	switch {
+	case c.UserUUID != "":
+		return c.UserUUID
+	case c.LegacyUUID != "":
+		return c.LegacyUUID
+	default:
+		return c.Subject
+	}
+}
+
+// AllScopes returns the scopes from both the scope and scp claims
+func (c *Claims) AllScopes() []string {
+	scopes := strings.Fields(c.Scope)
+	return append(scopes, c.Scopes...)
+}
+
+// HasScope reports whether the token was granted scope
+func (c *Claims) HasScope(scope string) bool {
+	for _, s := range c.AllScopes() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAMR reports whether the user signed in with the given authentication method
+func (c *Claims) HasAMR(method string) bool {
+	for _, m := range c.AMR {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// jwtValidation holds the registered-claim checks applied to every token
+var jwtValidation struct {
+	issuer    string
+	audiences []string
+	leeway    time.Duration
+}
+
+// SetJWTValidation configures the expected issuer and audiences and the clock skew
+// allowed when checking exp, nbf and iat. Empty issuer or audiences skip that check.
+func SetJWTValidation(issuer string, audiences []string, leeway time.Duration) {
+	jwtValidation.issuer = issuer
+	jwtValidation.audiences = audiences
+	jwtValidation.leeway = leeway
+}
+
+// parseClaims verifies the token signature and its registered claims
+func parseClaims(tokenString string) (*Claims, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(validMethods()),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(jwtValidation.leeway),
+	}
+	if jwtValidation.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(jwtValidation.issuer))
+	}
+
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, keyFunc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	if len(jwtValidation.audiences) > 0 && !audienceAllowed(claims.Audience) {
+		return nil, jwt.ErrTokenInvalidAudience
+	}
+	return claims, nil
+}
+
+func audienceAllowed(audience jwt.ClaimStrings) bool {
+	for _, aud := range audience {
+		for _, allowed := range jwtValidation.audiences {
+			if aud == allowed {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetClaims returns the claims of the token that authenticated the request
+func GetClaims(c *gin.Context) (*Claims, bool) {
+	value, exists := c.Get(claimsContextKey)
+	if !exists {
+		return nil, false
+	}
+	claims, ok := value.(*Claims)
+	return claims, ok
+}
+
+// UserUUID returns the authenticated user's UUID, or false when the request has none
+func UserUUID(c *gin.Context) (string, bool) {
+	claims, ok := GetClaims(c)
+	if !ok || claims.UUID() == "" {
+		return "", false
+	}
+	return claims.UUID(), true
+}
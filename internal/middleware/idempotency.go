@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const (
+	// idempotencyLease is how long a claimed key blocks other calls before it is
+	// considered abandoned, e.g. because the instance running it died
+	idempotencyLease = time.Minute
+	// idempotencyPollInterval is how often a duplicate call checks whether the first one finished
+	idempotencyPollInterval = 200 * time.Millisecond
+)
+
+// idempotencyKeyed is implemented by request messages that carry an idempotency_key field
+type idempotencyKeyed interface {
+	GetIdempotencyKey() string
+}
+
+// IdempotencyStore persists idempotency keys so duplicates are caught across restarts
+// and across every instance behind the load balancer
+type IdempotencyStore interface {
+	// Claim reserves key for lease; false means another call holds it or already completed it
+	Claim(ctx context.Context, key, method string, lease, window time.Duration) (bool, error)
+	// Response returns the stored response of a completed call, or nil if there is none
+	Response(ctx context.Context, key string) ([]byte, error)
+	// Complete stores a successful call's response for window
+	Complete(ctx context.Context, key string, response []byte, window time.Duration) error
+	// Release drops the claim of a failed call so it can be retried
+	Release(ctx context.Context, key string) error
+	// DeleteExpired removes expired responses and abandoned claims
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// IdempotencyInterceptor stores the responses of successful unary calls that carry an
+// idempotency key and replays them for repeated calls within the configured window.
+// Concurrent calls with the same key wait for the first one to finish.
+type IdempotencyInterceptor struct {
+	store  IdempotencyStore
+	window time.Duration
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+// NewIdempotencyInterceptor creates a new idempotency interceptor. With a nil store
+// requests are not deduplicated.
+func NewIdempotencyInterceptor(store IdempotencyStore, window time.Duration) *IdempotencyInterceptor {
+	return &IdempotencyInterceptor{
+		store:     store,
+		window:    window,
+		lastSweep: time.Now(),
+	}
+}
+
+// UnaryInterceptor is a gRPC unary interceptor that deduplicates requests by idempotency key
+func (i *IdempotencyInterceptor) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		keyed, ok := req.(idempotencyKeyed)
+		if !ok || keyed.GetIdempotencyKey() == "" || i.window <= 0 || i.store == nil {
+			return handler(ctx, req)
+		}
+		// Keys are per caller so one service can't replay another's responses
+		caller, _ := ServiceIdentityFromContext(ctx)
+		key := caller.Name + "|" + info.FullMethod + "|" + keyed.GetIdempotencyKey()
+
+		i.sweep(ctx)
+
+		for {
+			owner, err := i.store.Claim(ctx, key, info.FullMethod, idempotencyLease, i.window)
+			if err != nil {
+				// Failing open keeps notifications flowing when the store is unavailable
+				log.Printf("IdempotencyInterceptor: Failed to claim key for %s, running without deduplication: %v", info.FullMethod, err)
+				return handler(ctx, req)
+			}
+			if owner {
+				return i.execute(ctx, req, key, handler)
+			}
+
+			stored, err := i.store.Response(ctx, key)
+			if err != nil {
+				log.Printf("IdempotencyInterceptor: Failed to load stored response for %s: %v", info.FullMethod, err)
+			} else if stored != nil {
+				resp, err := unmarshalResponse(stored)
+				if err == nil {
+					log.Printf("IdempotencyInterceptor: Replaying response for %s (key %s)", info.FullMethod, keyed.GetIdempotencyKey())
+					return resp, nil
+				}
+				log.Printf("IdempotencyInterceptor: Failed to decode stored response for %s: %v", info.FullMethod, err)
+			}
+
+			// The first call is still running, or failed and released the key; try again
+			select {
+			case <-time.After(idempotencyPollInterval):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+}
+
+func (i *IdempotencyInterceptor) execute(ctx context.Context, req interface{}, key string, handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+
+	// Record the outcome even if the caller went away mid-call
+	storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	if msg, ok := resp.(proto.Message); ok && err == nil {
+		stored, marshalErr := marshalResponse(msg)
+		if marshalErr == nil {
+			marshalErr = i.store.Complete(storeCtx, key, stored, i.window)
+		}
+		if marshalErr != nil {
+			log.Printf("IdempotencyInterceptor: Failed to store response: %v", marshalErr)
+		}
+		return resp, err
+	}
+
+	if releaseErr := i.store.Release(storeCtx, key); releaseErr != nil {
+		log.Printf("IdempotencyInterceptor: Failed to release key after a failed call: %v", releaseErr)
+	}
+	return resp, err
+}
+
+// sweep deletes expired keys at most once a minute per instance
+func (i *IdempotencyInterceptor) sweep(ctx context.Context) {
+	i.mu.Lock()
+	if time.Since(i.lastSweep) < time.Minute {
+		i.mu.Unlock()
+		return
+	}
+	i.lastSweep = time.Now()
+	i.mu.Unlock()
+
+	if _, err := i.store.DeleteExpired(ctx); err != nil {
+		log.Printf("IdempotencyInterceptor: Failed to delete expired keys: %v", err)
+	}
+}
+
+// marshalResponse wraps the response in an Any so it can be decoded without knowing its type
+func marshalResponse(msg proto.Message) ([]byte, error) {
+	wrapped, err := anypb.New(msg)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(wrapped)
+}
+
+func unmarshalResponse(stored []byte) (proto.Message, error) {
+	var wrapped anypb.Any
+	if err := proto.Unmarshal(stored, &wrapped); err != nil {
+		return nil, err
+	}
+	return wrapped.UnmarshalNew()
+}
@@ -0,0 +1,258 @@
+package middleware
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultJWKSCacheTTL = 10 * time.Minute
+	// jwksMinRefresh limits how often the source is fetched, including after failed
+	// fetches, so that tokens with made-up kids or an unavailable identity provider
+	// can't turn every request into a fetch
+	jwksMinRefresh = 30 * time.Second
+)
+
+// JWKS verifies asymmetric tokens against a JSON Web Key Set loaded from a file or
+// an http(s) URL. Keys are cached for the configured TTL and refreshed early when a
+// token names a kid that isn't in the cache, which picks up rotated keys. Expired keys
+// keep being served while the refresh runs in the background or the source is down.
+type JWKS struct {
+	source string
+	ttl    time.Duration
+	client *http.Client
+	group  singleflight.Group
+
+	mu          sync.Mutex
+	keys        map[string]interface{}
+	fetchedAt   time.Time
+	lastAttempt time.Time
+	refreshing  bool
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// NewJWKS creates a key set and loads it once so misconfiguration shows up at startup
+func NewJWKS(source string, ttl time.Duration) (*JWKS, error) {
+	if source == "" {
+		return nil, fmt.Errorf("JWKS source is required")
+	}
+	if ttl <= 0 {
+		ttl = defaultJWKSCacheTTL
+	}
+
+	j := &JWKS{
+		source: source,
+		ttl:    ttl,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+
+	if err := j.refresh(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Key returns the public key for kid. Tokens without a kid are accepted only when
+// the set holds a single key.
+func (j *JWKS) Key(kid string) (interface{}, error) {
+	j.mu.Lock()
+	key, found := j.lookup(kid)
+	stale := time.Since(j.fetchedAt) > j.ttl
+	// Callers may join a refresh that is already running even inside the rate limit
+	canRefresh := j.refreshing || time.Since(j.lastAttempt) >= jwksMinRefresh
+	j.mu.Unlock()
+
+	if found {
+		if stale && canRefresh {
+			go j.sharedRefresh()
+		}
+		return key, nil
+	}
+
+	if canRefresh {
+		j.sharedRefresh()
+		j.mu.Lock()
+		key, found = j.lookup(kid)
+		j.mu.Unlock()
+		if found {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no signing key found for kid %q", kid)
+}
+
+// sharedRefresh refreshes the key set once for all concurrent callers
+func (j *JWKS) sharedRefresh() {
+	_, err, shared := j.group.Do("refresh", func() (interface{}, error) {
+		return nil, j.refresh()
+	})
+	if err != nil && !shared {
+		// The cached keys stay in use while the source is unavailable
+		log.Printf("JWKS: Failed to refresh keys from %s: %v", j.source, err)
+	}
+}
+
+// lookup finds the key for kid. Callers must hold j.mu.
+func (j *JWKS) lookup(kid string) (interface{}, bool) {
+	if kid == "" {
+		if len(j.keys) != 1 {
+			return nil, false
+		}
+		for _, key := range j.keys {
+			return key, true
+		}
+	}
+	key, ok := j.keys[kid]
+	return key, ok
+}
+
+// refresh reloads the key set. The source is read without holding j.mu so a slow
+// identity provider doesn't block lookups of cached keys.
+func (j *JWKS) refresh() error {
+	j.mu.Lock()
+	j.lastAttempt = time.Now()
+	j.refreshing = true
+	j.mu.Unlock()
+	defer func() {
+		j.mu.Lock()
+		j.refreshing = false
+		j.mu.Unlock()
+	}()
+
+	data, err := j.read()
+	if err != nil {
+		return err
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			log.Printf("JWKS: Skipping key %q: %v", jwk.Kid, err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("JWKS from %s contains no usable signing keys", j.source)
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.fetchedAt = time.Now()
+	j.mu.Unlock()
+	log.Printf("JWKS: Loaded %d signing key(s) from %s", len(keys), j.source)
+	return nil
+}
+
+func (j *JWKS) read() ([]byte, error) {
+	if !strings.HasPrefix(j.source, "http://") && !strings.HasPrefix(j.source, "https://") {
+		data, err := os.ReadFile(j.source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+		}
+		return data, nil
+	}
+
+	resp, err := j.client.Get(j.source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKS endpoint returned status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %w", err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty value")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"crypto/ecdsa"
+	"crypto/rsa"
 	"errors"
 	"log"
 	"net/http"
@@ -8,17 +10,73 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/johnroshan2255/core-service/internal/tenant"
 )
 
 var jwtKey string
 
+var jwks *JWKS
+
 func SetJWTKey(key string) {
 	jwtKey = key
 }
 
+// SetJWKS enables RS256/ES256 (and related) token verification against a key set.
+// HMAC tokens keep working while a JWT key is also set, to allow migrating issuers.
+func SetJWKS(keySet *JWKS) {
+	jwks = keySet
+}
+
+// validMethods lists the signing algorithms accepted with the configured keys
+func validMethods() []string {
+	var methods []string
+	if jwtKey != "" {
+		methods = append(methods, "HS256", "HS384", "HS512")
+	}
+	if jwks != nil {
+		methods = append(methods, "RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512")
+	}
+	return methods
+}
+
+// keyFunc picks the verification key for a token. The key type must match the
+// signing method, so an RSA public key can never be used as an HMAC secret.
+func keyFunc(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if jwtKey == "" {
+			return nil, errors.New("HMAC tokens are not accepted")
+		}
+		return []byte(jwtKey), nil
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS, *jwt.SigningMethodECDSA:
+		if jwks == nil {
+			return nil, errors.New("asymmetric tokens are not accepted")
+		}
+		kid, _ := token.Header["kid"].(string)
+		key, err := jwks.Key(kid)
+		if err != nil {
+			return nil, err
+		}
+		switch key.(type) {
+		case *rsa.PublicKey:
+			if _, ok := token.Method.(*jwt.SigningMethodECDSA); ok {
+				return nil, errors.New("key type does not match signing method")
+			}
+		case *ecdsa.PublicKey:
+			if _, ok := token.Method.(*jwt.SigningMethodECDSA); !ok {
+				return nil, errors.New("key type does not match signing method")
+			}
+		}
+		return key, nil
+	default:
+		return nil, errors.New("invalid signing method")
+	}
+}
+
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if jwtKey == "" {
+		if jwtKey == "" && jwks == nil {
 			log.Printf("JWT middleware: JWT key not set")
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "JWT authentication not configured"})
 			c.Abort()
@@ -41,13 +99,7 @@ func AuthMiddleware() gin.HandlerFunc {
 
 		tokenString := parts[1]
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("invalid signing method")
-			}
-			return []byte(jwtKey), nil
-		})
-
+		claims, err := parseClaims(tokenString)
 		if err != nil {
 			log.Printf("JWT middleware: Token validation error: %v", err)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
@@ -55,31 +107,30 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		if !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
-			return
-		}
-
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
+		if claims.UUID() == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
 			c.Abort()
 			return
 		}
 
-		userUUID, ok := claims["user_uuid"].(string)
-		if !ok {
-			userUUID, ok = claims["uuid"].(string)
-		}
-		if ok && userUUID != "" {
-			c.Set("user_uuid", userUUID)
+		if claims.SessionID != "" && sessionChecker != nil {
+			active, err := sessionChecker.IsTokenActive(c.Request.Context(), claims.ID)
+			if err != nil {
+				log.Printf("JWT middleware: Failed to check token of session %s: %v", claims.SessionID, err)
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Unable to verify session"})
+				c.Abort()
+				return
+			}
+			if !active {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+				c.Abort()
+				return
+			}
 		}
 
-		email, ok := claims["email"].(string)
-		if ok && email != "" {
-			c.Set("email", email)
-		}
+		c.Set(claimsContextKey, claims)
+		// Scope every repository call made with the request context to the caller's tenant
+		c.Request = c.Request.WithContext(tenant.WithTenant(c.Request.Context(), claims.TenantID))
 
 		c.Next()
 	}
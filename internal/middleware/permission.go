@@ -0,0 +1,31 @@
+package middleware
+
+import "context"
+
+// PermissionChecker decides whether a role grants a permission
+type PermissionChecker interface {
+	HasPermission(role, permission string) bool
+}
+
+var permissionChecker PermissionChecker
+
+// SetPermissionChecker sets the policy RequirePermission consults. Until it is called
+// RequirePermission denies every request.
+func SetPermissionChecker(checker PermissionChecker) {
+	permissionChecker = checker
+}
+
+// RoleResolver looks up a user's current role
+type RoleResolver interface {
+	Role(ctx context.Context, userUUID string) (string, error)
+}
+
+var roleResolver RoleResolver
+
+// SetRoleResolver makes RequirePermission check the user's current role instead of the
+// role claim, so a demotion applies to tokens already issued. Tokens without a
+// session_id claim, such as those from an external identity provider, keep using
+// their role claim.
+func SetRoleResolver(resolver RoleResolver) {
+	roleResolver = resolver
+}
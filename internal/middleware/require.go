@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireVerifiedEmail rejects requests whose token doesn't carry email_verified=true.
+// It must run after AuthMiddleware.
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := GetClaims(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			c.Abort()
+			return
+		}
+		if !claims.EmailVerified {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Email address must be verified"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireAMR rejects requests whose token wasn't obtained with every given
+// authentication method, e.g. RequireAMR("mfa") for routes that need a second factor.
+// It must run after AuthMiddleware.
+func RequireAMR(methods ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := GetClaims(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			c.Abort()
+			return
+		}
+		for _, method := range methods {
+			if !claims.HasAMR(method) {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":        "Additional authentication is required",
+					"required_amr": methods,
+				})
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequirePermission rejects requests whose role doesn't grant every given permission
+// under the policy set with SetPermissionChecker. The role comes from the resolver set
+// with SetRoleResolver, or the role claim without one. It must run after AuthMiddleware.
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := GetClaims(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			c.Abort()
+			return
+		}
+
+		role := claims.Role
+		if roleResolver != nil && claims.SessionID != "" {
+			current, err := roleResolver.Role(c.Request.Context(), claims.UUID())
+			if err != nil {
+				log.Printf("RequirePermission: Failed to load role for user %s: %v", claims.UUID(), err)
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Unable to verify permissions"})
+				c.Abort()
+				return
+			}
+			role = current
+		}
+
+		for _, permission := range permissions {
+			if permissionChecker == nil || !permissionChecker.HasPermission(role, permission) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to perform this action"})
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
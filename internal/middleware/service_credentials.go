@@ -0,0 +1,214 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// ServiceCredential is one named identity a backend service can authenticate with: a
+// key, a client certificate, or both. Only the hex SHA-256 of the key is stored, e.g.
+// from `printf %s "$KEY" | sha256sum`. Rotate a key by adding a second credential with
+// the same name and an overlapping window, then removing the old one once callers have
+// switched.
+type ServiceCredential struct {
+	Name    string `json:"name"`
+	KeyHash string `json:"key_hash,omitempty"`
+	// CertSANs lists URI or DNS subject alternative names, such as
+	// "spiffe://core/billing", that identify the service by its verified mTLS certificate
+	CertSANs []string `json:"cert_sans,omitempty"`
+	// NotBefore and NotAfter bound when the credential is accepted; either may be omitted
+	NotBefore *time.Time `json:"not_before,omitempty"`
+	NotAfter  *time.Time `json:"not_after,omitempty"`
+	// Methods lists the gRPC methods the service may call: full method names such as
+	// "/notification.v1.NotificationService/SendNotification", a service wildcard such
+	// as "/notification.v1.NotificationService/*", or "*" for every method. HTTP service
+	// routes are checked against the gRPC method they mirror.
+	Methods []string `json:"methods"`
+
+	hash []byte
+}
+
+// ServiceCredentials holds the identities accepted by BackendAuthInterceptor and
+// ServiceAuthMiddleware
+type ServiceCredentials struct {
+	credentials []ServiceCredential
+}
+
+// NewServiceCredentials validates credentials and returns the set
+func NewServiceCredentials(credentials []ServiceCredential) (*ServiceCredentials, error) {
+	set := &ServiceCredentials{credentials: make([]ServiceCredential, len(credentials))}
+	for i, credential := range credentials {
+		if credential.Name == "" {
+			return nil, fmt.Errorf("service credential %d: name is required", i)
+		}
+		if credential.KeyHash == "" && len(credential.CertSANs) == 0 {
+			return nil, fmt.Errorf("service credential %s: key_hash or cert_sans is required", credential.Name)
+		}
+		if credential.KeyHash != "" {
+			hash, err := hex.DecodeString(strings.TrimPrefix(credential.KeyHash, "sha256:"))
+			if err != nil || len(hash) != sha256.Size {
+				return nil, fmt.Errorf("service credential %s: key_hash must be a hex SHA-256 digest", credential.Name)
+			}
+			credential.hash = hash
+		}
+		if len(credential.Methods) == 0 {
+			return nil, fmt.Errorf("service credential %s: at least one method is required", credential.Name)
+		}
+		if credential.NotBefore != nil && credential.NotAfter != nil && !credential.NotAfter.After(*credential.NotBefore) {
+			return nil, fmt.Errorf("service credential %s: not_after must be after not_before", credential.Name)
+		}
+		set.credentials[i] = credential
+	}
+	return set, nil
+}
+
+// LoadServiceCredentialsFile reads a JSON array of ServiceCredential
+func LoadServiceCredentialsFile(path string) (*ServiceCredentials, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service credentials: %w", err)
+	}
+
+	var credentials []ServiceCredential
+	if err := json.Unmarshal(content, &credentials); err != nil {
+		return nil, fmt.Errorf("failed to parse service credentials: %w", err)
+	}
+	return NewServiceCredentials(credentials)
+}
+
+// LoadServiceCredentials returns the credentials in credentialsFile or, without one,
+// the single shared serviceKey. With neither, every backend call is rejected.
+func LoadServiceCredentials(credentialsFile, serviceKey string) (*ServiceCredentials, error) {
+	if credentialsFile != "" {
+		credentials, err := LoadServiceCredentialsFile(credentialsFile)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Loaded service credentials from %s", credentialsFile)
+		return credentials, nil
+	}
+
+	if serviceKey == "" {
+		log.Printf("WARNING: neither SERVICE_CREDENTIALS_FILE nor SERVICE_KEY is set; all backend service calls will be rejected")
+		return NewServiceCredentials(nil)
+	}
+	log.Printf("WARNING: using the shared SERVICE_KEY; set SERVICE_CREDENTIALS_FILE for per-service keys")
+	return SingleServiceKey("default", serviceKey), nil
+}
+
+// SingleServiceKey accepts one key for every method, matching the older SERVICE_KEY setup
+func SingleServiceKey(name, key string) *ServiceCredentials {
+	sum := sha256.Sum256([]byte(key))
+	return &ServiceCredentials{credentials: []ServiceCredential{{
+		Name:    name,
+		KeyHash: hex.EncodeToString(sum[:]),
+		Methods: []string{"*"},
+		hash:    sum[:],
+	}}}
+}
+
+// Authenticate returns the credential matching key that is valid at now. Every
+// credential is compared so the time taken doesn't reveal which one matched.
+func (s *ServiceCredentials) Authenticate(key string, now time.Time) (*ServiceCredential, error) {
+	if s == nil || key == "" {
+		return nil, ErrInvalidServiceKey
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	var match *ServiceCredential
+	for i := range s.credentials {
+		credential := &s.credentials[i]
+		if credential.hash != nil && subtle.ConstantTimeCompare(sum[:], credential.hash) == 1 && credential.activeAt(now) && match == nil {
+			match = credential
+		}
+	}
+	if match == nil {
+		return nil, ErrInvalidServiceKey
+	}
+	return match, nil
+}
+
+// AuthenticateCertificate returns the credential mapped to one of a verified client
+// certificate's URI or DNS SANs that is valid at now, and the SAN that matched
+func (s *ServiceCredentials) AuthenticateCertificate(cert *x509.Certificate, now time.Time) (*ServiceCredential, string, error) {
+	if s == nil || cert == nil {
+		return nil, "", ErrUnknownClientCertificate
+	}
+
+	sans := make([]string, 0, len(cert.URIs)+len(cert.DNSNames))
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+	sans = append(sans, cert.DNSNames...)
+
+	for i := range s.credentials {
+		credential := &s.credentials[i]
+		if !credential.activeAt(now) {
+			continue
+		}
+		for _, allowed := range credential.CertSANs {
+			for _, san := range sans {
+				if san == allowed {
+					return credential, san, nil
+				}
+			}
+		}
+	}
+	return nil, "", ErrUnknownClientCertificate
+}
+
+func (c *ServiceCredential) activeAt(now time.Time) bool {
+	if c.NotBefore != nil && now.Before(*c.NotBefore) {
+		return false
+	}
+	if c.NotAfter != nil && !now.Before(*c.NotAfter) {
+		return false
+	}
+	return true
+}
+
+// Allows reports whether the credential may call the gRPC method fullMethod
+func (c *ServiceCredential) Allows(fullMethod string) bool {
+	for _, method := range c.Methods {
+		if method == "*" || method == fullMethod {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(method, "*"); ok && strings.HasSuffix(prefix, "/") && strings.HasPrefix(fullMethod, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ServiceIdentity is the authenticated backend caller of a gRPC or HTTP service request
+type ServiceIdentity struct {
+	// Name is the credential name, shared by every key of the same service
+	Name string
+	// Method is how the caller authenticated: "certificate" or "service-key"
+	Method string
+	// SAN is the certificate SAN that identified the caller, for certificate callers
+	SAN string
+}
+
+type serviceIdentityKey struct{}
+
+// WithServiceIdentity returns a context carrying the caller's identity
+func WithServiceIdentity(ctx context.Context, identity ServiceIdentity) context.Context {
+	return context.WithValue(ctx, serviceIdentityKey{}, identity)
+}
+
+// ServiceIdentityFromContext returns the caller set by BackendAuthInterceptor or
+// ServiceAuthMiddleware
+func ServiceIdentityFromContext(ctx context.Context) (ServiceIdentity, bool) {
+	identity, ok := ctx.Value(serviceIdentityKey{}).(ServiceIdentity)
+	return identity, ok
+}
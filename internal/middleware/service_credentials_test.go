@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"crypto/x509"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestAuthenticateCertificateMapsSANs(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	expired := now.Add(-time.Hour)
+	credentials, err := NewServiceCredentials([]ServiceCredential{
+		{Name: "billing", CertSANs: []string{"spiffe://core/billing"}, Methods: []string{"*"}},
+		{Name: "documents", CertSANs: []string{"documents.core.internal"}, Methods: []string{"*"}},
+		{Name: "retired", CertSANs: []string{"spiffe://core/retired"}, NotAfter: &expired, Methods: []string{"*"}},
+	})
+	if err != nil {
+		t.Fatalf("NewServiceCredentials: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		cert     *x509.Certificate
+		wantName string
+		wantSAN  string
+	}{
+		{"URI SAN", certWithSANs("spiffe://core/billing"), "billing", "spiffe://core/billing"},
+		{"DNS SAN", certWithSANs("", "documents.core.internal"), "documents", "documents.core.internal"},
+		{"unknown SAN", certWithSANs("spiffe://core/unknown", "unknown.core.internal"), "", ""},
+		{"expired credential", certWithSANs("spiffe://core/retired"), "", ""},
+		{"no certificate", nil, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			credential, san, err := credentials.AuthenticateCertificate(tt.cert, now)
+			if tt.wantName == "" {
+				if !errors.Is(err, ErrUnknownClientCertificate) {
+					t.Fatalf("got %v, want ErrUnknownClientCertificate", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AuthenticateCertificate: %v", err)
+			}
+			if credential.Name != tt.wantName || san != tt.wantSAN {
+				t.Fatalf("got %s via %q, want %s via %q", credential.Name, san, tt.wantName, tt.wantSAN)
+			}
+		})
+	}
+}
+
+func TestServiceCredentialAllows(t *testing.T) {
+	credential := ServiceCredential{Methods: []string{
+		"/notification.v1.NotificationService/*",
+		"/user.v1.UserService/GetUser",
+	}}
+
+	tests := []struct {
+		method string
+		want   bool
+	}{
+		{"/notification.v1.NotificationService/SendNotification", true},
+		{"/user.v1.UserService/GetUser", true},
+		{"/user.v1.UserService/DeleteUser", false},
+		{"/notification.v1.NotificationServiceAdmin/Purge", false},
+	}
+	for _, tt := range tests {
+		if got := credential.Allows(tt.method); got != tt.want {
+			t.Errorf("Allows(%q) = %v, want %v", tt.method, got, tt.want)
+		}
+	}
+}
+
+// certWithSANs returns a certificate carrying uri as a URI SAN, when set, and dnsNames
+func certWithSANs(uri string, dnsNames ...string) *x509.Certificate {
+	cert := &x509.Certificate{DNSNames: dnsNames}
+	if uri != "" {
+		parsed, err := url.Parse(uri)
+		if err != nil {
+			panic(err)
+		}
+		cert.URIs = []*url.URL{parsed}
+	}
+	return cert
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"context"
+)
+
+// SessionChecker reports whether an access token, identified by its jti claim, is still
+// active, i.e. neither it nor its session has been revoked
+type SessionChecker interface {
+	IsTokenActive(ctx context.Context, jti string) (bool, error)
+}
+
+var sessionChecker SessionChecker
+
+// SetSessionChecker makes AuthMiddleware reject tokens that have been revoked. Tokens
+// without a session_id claim, such as those from an external identity provider, are
+// not checked.
+func SetSessionChecker(checker SessionChecker) {
+	sessionChecker = checker
+}
@@ -0,0 +1,164 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Event types published to real-time subscribers
+const (
+	EventNotificationCreated   = "notification.created"
+	EventDocumentStatusChanged = "document.status_changed"
+)
+
+const (
+	subscriberBuffer    = 32
+	relayPublishTimeout = 5 * time.Second
+	relayBaseBackoff    = time.Second
+	relayMaxBackoff     = 30 * time.Second
+)
+
+// Event is a real-time update pushed to SSE and gRPC stream subscribers
+type Event struct {
+	Type      string                 `json:"type"`
+	UserUUID  string                 `json:"user_uuid"`
+	Payload   map[string]interface{} `json:"payload"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+type subscription struct {
+	userUUID string
+	events   chan Event
+}
+
+var errRelayClosed = errors.New("event relay connection closed")
+
+// EventRelay carries events between service instances. Publish sends an event to every
+// listening instance, this one included. Listen returns once the instance is listening
+// and closes the channel when the connection is lost.
+type EventRelay interface {
+	Publish(ctx context.Context, event Event) error
+	Listen(ctx context.Context) (<-chan Event, error)
+}
+
+// Broker fans events out to subscribers. Without a relay subscribers only see events
+// published by the same instance; with one, events published on any instance reach
+// every instance's subscribers.
+type Broker struct {
+	mu            sync.RWMutex
+	subscriptions map[*subscription]struct{}
+
+	relay     EventRelay
+	listening atomic.Bool
+}
+
+// NewBroker creates a new event broker
+func NewBroker() *Broker {
+	return &Broker{
+		subscriptions: make(map[*subscription]struct{}),
+	}
+}
+
+// Subscribe returns a channel of events for userUUID, or for every user when
+// userUUID is empty, and a function that cancels the subscription
+func (b *Broker) Subscribe(userUUID string) (<-chan Event, func()) {
+	sub := &subscription{
+		userUUID: userUUID,
+		events:   make(chan Event, subscriberBuffer),
+	}
+
+	b.mu.Lock()
+	b.subscriptions[sub] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscriptions, sub)
+			b.mu.Unlock()
+			close(sub.events)
+		})
+	}
+
+	return sub.events, cancel
+}
+
+// UseRelay sends published events through relay and delivers the events it receives
+// to this broker's subscribers until ctx is done. Lost connections are re-established
+// with backoff; while the broker isn't listening, events are delivered locally only.
+func (b *Broker) UseRelay(ctx context.Context, relay EventRelay) {
+	b.relay = relay
+	go b.listen(ctx)
+}
+
+func (b *Broker) listen(ctx context.Context) {
+	backoff := relayBaseBackoff
+	for {
+		events, err := b.relay.Listen(ctx)
+		if err == nil {
+			log.Printf("Broker: Listening for events from other instances")
+			b.listening.Store(true)
+			for event := range events {
+				b.deliver(event)
+			}
+			b.listening.Store(false)
+			backoff = relayBaseBackoff
+			err = errRelayClosed
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("Broker: Event relay disconnected, reconnecting in %s: %v", backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > relayMaxBackoff {
+			backoff = relayMaxBackoff
+		}
+	}
+}
+
+// Publish delivers an event to every matching subscriber, through the relay when one
+// is listening. Slow subscribers whose buffer is full miss the event rather than block
+// the publisher.
+func (b *Broker) Publish(event Event) {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	if b.relay != nil && b.listening.Load() {
+		ctx, cancel := context.WithTimeout(context.Background(), relayPublishTimeout)
+		err := b.relay.Publish(ctx, event)
+		cancel()
+		if err == nil {
+			return
+		}
+		log.Printf("Broker: Failed to relay %s event, delivering locally only: %v", event.Type, err)
+	}
+	b.deliver(event)
+}
+
+func (b *Broker) deliver(event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subscriptions {
+		if sub.userUUID != "" && sub.userUUID != event.UserUUID {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			log.Printf("Broker: Dropping %s event for slow subscriber", event.Type)
+		}
+	}
+}
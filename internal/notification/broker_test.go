@@ -0,0 +1,95 @@
+package notification
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryRelay connects brokers in one process the way Postgres connects instances
+type memoryRelay struct {
+	mu        sync.Mutex
+	listeners []chan Event
+}
+
+func (r *memoryRelay) Publish(ctx context.Context, event Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, listener := range r.listeners {
+		listener <- event
+	}
+	return nil
+}
+
+func (r *memoryRelay) Listen(ctx context.Context) (<-chan Event, error) {
+	events := make(chan Event, subscriberBuffer)
+	r.mu.Lock()
+	r.listeners = append(r.listeners, events)
+	r.mu.Unlock()
+	return events, nil
+}
+
+func TestBrokerRelaysEventsBetweenInstances(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	relay := &memoryRelay{}
+	publisher, subscriber := NewBroker(), NewBroker()
+	publisher.UseRelay(ctx, relay)
+	subscriber.UseRelay(ctx, relay)
+	waitListening(t, publisher, subscriber)
+
+	remote, cancelRemote := subscriber.Subscribe("user-1")
+	defer cancelRemote()
+	local, cancelLocal := publisher.Subscribe("user-1")
+	defer cancelLocal()
+
+	publisher.Publish(Event{Type: EventNotificationCreated, UserUUID: "user-1"})
+
+	for name, events := range map[string]<-chan Event{"other instance": remote, "publishing instance": local} {
+		select {
+		case event := <-events:
+			if event.Type != EventNotificationCreated {
+				t.Fatalf("%s got %s, want %s", name, event.Type, EventNotificationCreated)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s did not receive the event", name)
+		}
+		select {
+		case event := <-events:
+			t.Fatalf("%s received %s twice", name, event.Type)
+		default:
+		}
+	}
+}
+
+func TestBrokerDeliversLocallyWithoutRelay(t *testing.T) {
+	broker := NewBroker()
+	events, cancel := broker.Subscribe("")
+	defer cancel()
+
+	broker.Publish(Event{Type: EventDocumentStatusChanged, UserUUID: "user-1"})
+
+	select {
+	case event := <-events:
+		if event.CreatedAt.IsZero() {
+			t.Fatalf("CreatedAt was not set")
+		}
+	default:
+		t.Fatalf("subscriber did not receive the event")
+	}
+}
+
+func waitListening(t *testing.T, brokers ...*Broker) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for _, broker := range brokers {
+		for !broker.listening.Load() {
+			if time.Now().After(deadline) {
+				t.Fatalf("broker did not start listening")
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
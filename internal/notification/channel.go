@@ -0,0 +1,71 @@
+package notification
+
+// Channel identifies a delivery channel a notification can be sent through
+type Channel string
+
+const (
+	ChannelEmail   Channel = "email"
+	ChannelSMS     Channel = "sms"
+	ChannelWebhook Channel = "webhook"
+	ChannelInApp   Channel = "in_app"
+)
+
+// Notification types used for routing and preference lookups
+const (
+	TypeUserCreated    = "user_created"
+	TypeDocumentExpiry = "document_expiry"
+	TypeDocumentDigest = "document_digest"
+	TypeSecurityNotice = "security_notice"
+	TypePasswordReset  = "password_reset"
+	TypeEmailVerify    = "email_verification"
+)
+
+// defaultChannels are used when a user has no stored channel preferences.
+// Channels without a configured provider are skipped.
+var defaultChannels = []Channel{ChannelEmail, ChannelInApp}
+
+// mandatoryTypes are delivered regardless of opt-outs and quiet hours
+var mandatoryTypes = map[string]bool{
+	TypeSecurityNotice: true,
+	TypePasswordReset:  true,
+	TypeEmailVerify:    true,
+}
+
+// emailOnlyTypes carry a secret link meant for the email address itself, so they are
+// never sent through other channels
+var emailOnlyTypes = map[string]bool{
+	TypePasswordReset: true,
+	TypeEmailVerify:   true,
+}
+
+// preferenceTypes maps notification types onto the type whose channel preferences they follow
+var preferenceTypes = map[string]string{
+	TypeDocumentDigest: TypeDocumentExpiry,
+}
+
+// ParseChannel validates a channel name
+func ParseChannel(name string) (Channel, bool) {
+	switch Channel(name) {
+	case ChannelEmail, ChannelSMS, ChannelWebhook, ChannelInApp:
+		return Channel(name), true
+	}
+	return "", false
+}
+
+// IsMandatory reports whether a notification type bypasses user opt-outs
+func IsMandatory(notificationType string) bool {
+	return mandatoryTypes[notificationType]
+}
+
+// carriesSecret reports whether a notification type's payload holds a secret link
+func carriesSecret(notificationType string) bool {
+	return emailOnlyTypes[notificationType]
+}
+
+// preferenceType returns the notification type used for channel preference lookups
+func preferenceType(notificationType string) string {
+	if t, ok := preferenceTypes[notificationType]; ok {
+		return t
+	}
+	return notificationType
+}
@@ -0,0 +1,269 @@
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// FieldType is the expected JSON type of an event payload field
+type FieldType string
+
+const (
+	FieldString FieldType = "string"
+	FieldNumber FieldType = "number"
+	FieldBool   FieldType = "bool"
+	FieldList   FieldType = "list"
+	FieldObject FieldType = "object"
+)
+
+const defaultLocale = "en"
+
+// ErrInvalidNotification is returned for unknown event types and payloads that don't
+// match their schema
+var ErrInvalidNotification = errors.New("invalid notification")
+
+// EventSchema describes an event type accepted by the generic SendNotification RPC.
+// Subjects and Bodies are text/template strings keyed by locale and rendered with the payload.
+type EventSchema struct {
+	Type     string               `json:"type"`
+	Required map[string]FieldType `json:"required"`
+	Optional map[string]FieldType `json:"optional"`
+	Subjects map[string]string    `json:"subjects"`
+	Bodies   map[string]string    `json:"bodies"`
+}
+
+type compiledSchema struct {
+	schema   EventSchema
+	subjects map[string]*template.Template
+	bodies   map[string]*template.Template
+}
+
+// SchemaRegistry holds the event schemas notifications are validated against
+type SchemaRegistry struct {
+	mu      sync.RWMutex
+	schemas map[string]*compiledSchema
+}
+
+// NewSchemaRegistry creates a registry pre-loaded with the built-in event schemas
+func NewSchemaRegistry() *SchemaRegistry {
+	r := &SchemaRegistry{
+		schemas: make(map[string]*compiledSchema),
+	}
+	for _, schema := range builtinSchemas {
+		if err := r.Register(schema); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+// Register adds or replaces an event schema
+func (r *SchemaRegistry) Register(schema EventSchema) error {
+	if schema.Type == "" {
+		return fmt.Errorf("event type is required")
+	}
+	if schema.Subjects[defaultLocale] == "" {
+		return fmt.Errorf("event %s: a %q subject is required", schema.Type, defaultLocale)
+	}
+	for field, fieldType := range schema.Required {
+		if !validFieldType(fieldType) {
+			return fmt.Errorf("event %s: field %s has unknown type %s", schema.Type, field, fieldType)
+		}
+	}
+	for field, fieldType := range schema.Optional {
+		if !validFieldType(fieldType) {
+			return fmt.Errorf("event %s: field %s has unknown type %s", schema.Type, field, fieldType)
+		}
+	}
+
+	compiled := &compiledSchema{
+		schema:   schema,
+		subjects: make(map[string]*template.Template),
+		bodies:   make(map[string]*template.Template),
+	}
+	for locale, text := range schema.Subjects {
+		t, err := template.New(schema.Type + ".subject." + locale).Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+		if err != nil {
+			return fmt.Errorf("event %s: invalid %s subject template: %w", schema.Type, locale, err)
+		}
+		compiled.subjects[strings.ToLower(locale)] = t
+	}
+	for locale, text := range schema.Bodies {
+		t, err := template.New(schema.Type + ".body." + locale).Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+		if err != nil {
+			return fmt.Errorf("event %s: invalid %s body template: %w", schema.Type, locale, err)
+		}
+		compiled.bodies[strings.ToLower(locale)] = t
+	}
+
+	r.mu.Lock()
+	r.schemas[schema.Type] = compiled
+	r.mu.Unlock()
+	return nil
+}
+
+// LoadFile registers every schema in a JSON file containing an array of EventSchema
+func (r *SchemaRegistry) LoadFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read event schemas: %w", err)
+	}
+
+	var schemas []EventSchema
+	if err := json.Unmarshal(content, &schemas); err != nil {
+		return fmt.Errorf("failed to parse event schemas: %w", err)
+	}
+
+	for _, schema := range schemas {
+		if err := r.Register(schema); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks a payload against the event's schema
+func (r *SchemaRegistry) Validate(eventType string, payload map[string]interface{}) error {
+	compiled, err := r.get(eventType)
+	if err != nil {
+		return err
+	}
+
+	for field, fieldType := range compiled.schema.Required {
+		value, ok := payload[field]
+		if !ok || value == nil {
+			return fmt.Errorf("%w: payload field %s is required", ErrInvalidNotification, field)
+		}
+		if !matchesFieldType(value, fieldType) {
+			return fmt.Errorf("%w: payload field %s must be a %s", ErrInvalidNotification, field, fieldType)
+		}
+	}
+	for field, fieldType := range compiled.schema.Optional {
+		if value, ok := payload[field]; ok && value != nil && !matchesFieldType(value, fieldType) {
+			return fmt.Errorf("%w: payload field %s must be a %s", ErrInvalidNotification, field, fieldType)
+		}
+	}
+	return nil
+}
+
+// Render produces the subject and body for an event in the requested locale,
+// falling back to the base language and then to the default locale
+func (r *SchemaRegistry) Render(eventType, locale string, payload map[string]interface{}) (string, string, error) {
+	compiled, err := r.get(eventType)
+	if err != nil {
+		return "", "", err
+	}
+
+	subject, err := renderLocalized(compiled.subjects, locale, payload)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to render subject: %w", err)
+	}
+	body, err := renderLocalized(compiled.bodies, locale, payload)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to render body: %w", err)
+	}
+	return subject, body, nil
+}
+
+func (r *SchemaRegistry) get(eventType string) (*compiledSchema, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	compiled, ok := r.schemas[eventType]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown event type: %s", ErrInvalidNotification, eventType)
+	}
+	return compiled, nil
+}
+
+func renderLocalized(templates map[string]*template.Template, locale string, payload map[string]interface{}) (string, error) {
+	locale = strings.ToLower(locale)
+	candidates := []string{locale}
+	if i := strings.IndexAny(locale, "-_"); i > 0 {
+		candidates = append(candidates, locale[:i])
+	}
+	candidates = append(candidates, defaultLocale)
+
+	for _, candidate := range candidates {
+		t, ok := templates[candidate]
+		if !ok {
+			continue
+		}
+		var out bytes.Buffer
+		if err := t.Execute(&out, payload); err != nil {
+			return "", err
+		}
+		return out.String(), nil
+	}
+	return "", nil
+}
+
+func validFieldType(fieldType FieldType) bool {
+	switch fieldType {
+	case FieldString, FieldNumber, FieldBool, FieldList, FieldObject:
+		return true
+	}
+	return false
+}
+
+func matchesFieldType(value interface{}, fieldType FieldType) bool {
+	switch fieldType {
+	case FieldString:
+		_, ok := value.(string)
+		return ok
+	case FieldNumber:
+		switch value.(type) {
+		case float64, float32, int, int32, int64, uint, uint32, uint64, json.Number:
+			return true
+		}
+	case FieldBool:
+		_, ok := value.(bool)
+		return ok
+	case FieldList:
+		_, ok := value.([]interface{})
+		return ok
+	case FieldObject:
+		_, ok := value.(map[string]interface{})
+		return ok
+	}
+	return false
+}
+
+// builtinSchemas cover the notification types that also have dedicated RPCs
+var builtinSchemas = []EventSchema{
+	{
+		Type:     TypeUserCreated,
+		Optional: map[string]FieldType{"username": FieldString},
+		Subjects: map[string]string{defaultLocale: "Welcome to our platform!"},
+		Bodies:   map[string]string{defaultLocale: "Hi {{with .username}}{{.}}{{else}}there{{end}}, your account has been created."},
+	},
+	{
+		Type: TypeDocumentExpiry,
+		Required: map[string]FieldType{
+			"document_name": FieldString,
+			"expiry_date":   FieldString,
+		},
+		Optional: map[string]FieldType{
+			"document_category": FieldString,
+			"days_until_expiry": FieldNumber,
+			"is_expired":        FieldBool,
+			"message":           FieldString,
+		},
+		Subjects: map[string]string{defaultLocale: "{{if .is_expired}}Document Expired{{else}}Document Expiring Soon{{end}}: {{.document_name}}"},
+		Bodies: map[string]string{defaultLocale: "{{with .message}}{{.}}{{else}}Your document '{{.document_name}}' " +
+			"{{if .is_expired}}has expired on {{date .expiry_date}}{{else}}will expire on {{date .expiry_date}}{{end}}.{{end}}"},
+	},
+	{
+		Type:     TypeSecurityNotice,
+		Required: map[string]FieldType{"message": FieldString},
+		Optional: map[string]FieldType{"subject": FieldString},
+		Subjects: map[string]string{defaultLocale: "{{with .subject}}{{.}}{{else}}Security notice{{end}}"},
+		Bodies:   map[string]string{defaultLocale: "{{.message}}"},
+	},
+}
@@ -3,35 +3,156 @@ package notification
 import (
 	"fmt"
 	"log"
+	"strings"
+
+	"github.com/johnroshan2255/core-service/internal/notification/repos"
 )
 
 // Factory creates notification service instances
 type Factory struct {
-	provider Provider
+	providers map[Channel]Provider
+	inApp     bool
 }
 
-// NewFactory creates a new notification factory
-func NewFactory(providerType string) (*Factory, error) {
-	var provider Provider
-
-	switch providerType {
-	case "email":
-		provider = NewEmailProvider()
-		log.Printf("NotificationFactory: Using email provider")
-	case "mock":
-		provider = NewMockProvider()
-		log.Printf("NotificationFactory: Using mock provider")
-	default:
-		return nil, fmt.Errorf("unknown notification provider: %s", providerType)
+// FactoryConfig selects the providers built by NewFactory
+type FactoryConfig struct {
+	// EmailProviders is the email failover chain in priority order (email, smtp, mock)
+	EmailProviders []string
+	// Channels lists the additional channels (sms, webhook, in_app) enabled alongside email
+	Channels []string
+	// SMSProviders is the SMS failover chain (twilio, mock); without one the sms channel
+	// stays off. WebhookProviders overrides the default webhook chain.
+	SMSProviders     []string
+	WebhookProviders []string
+	// SMTP is only used by the smtp provider
+	SMTP SMTPConfig
+	// Twilio is only used by the twilio SMS provider
+	Twilio  TwilioConfig
+	Breaker BreakerConfig
+}
+
+// NewFactory creates a new notification factory. Every channel gets a failover
+// chain of providers with a circuit breaker per provider.
+func NewFactory(config FactoryConfig) (*Factory, error) {
+	providers := make(map[Channel]Provider)
+	inApp := false
+
+	emailProviders := config.EmailProviders
+	if len(emailProviders) == 0 {
+		emailProviders = []string{"email"}
+	}
+	chain, err := newChain(ChannelEmail, emailProviders, config)
+	if err != nil {
+		return nil, err
+	}
+	providers[ChannelEmail] = chain
+
+	for _, name := range config.Channels {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		channel, ok := ParseChannel(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown notification channel: %s", name)
+		}
+		if _, exists := providers[channel]; exists {
+			continue
+		}
+
+		switch channel {
+		case ChannelSMS:
+			// SMS needs gateway credentials, so the channel stays off unless a provider is
+			// configured; otherwise SMS messages would be marked sent and never delivered
+			if len(config.SMSProviders) == 0 {
+				log.Printf("NotificationFactory: No SMS provider configured, disabling sms channel")
+				continue
+			}
+			if providers[channel], err = newChain(channel, config.SMSProviders, config); err != nil {
+				return nil, err
+			}
+		case ChannelWebhook:
+			names := config.WebhookProviders
+			if len(names) == 0 {
+				names = []string{"webhook"}
+			}
+			if providers[channel], err = newChain(channel, names, config); err != nil {
+				return nil, err
+			}
+		case ChannelInApp:
+			// The in-app provider needs the notification repository, so it is
+			// created in NewService
+			inApp = true
+		default:
+			return nil, fmt.Errorf("notification channel %s is not supported", name)
+		}
+		log.Printf("NotificationFactory: Enabled %s channel", channel)
 	}
 
 	return &Factory{
-		provider: provider,
+		providers: providers,
+		inApp:     inApp,
 	}, nil
 }
 
-// NewService creates a new notification service with the configured provider
-func (f *Factory) NewService() *NotificationService {
-	return NewNotificationService(f.provider)
+// newChain builds the failover chain for a channel from provider names in priority order
+func newChain(channel Channel, names []string, config FactoryConfig) (*FailoverProvider, error) {
+	chain := make([]Provider, 0, len(names))
+	chainNames := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		provider, err := newProvider(channel, name, config)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, provider)
+		chainNames = append(chainNames, name)
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("no providers configured for %s channel", channel)
+	}
+
+	log.Printf("NotificationFactory: Using %s providers %s", channel, strings.Join(chainNames, " -> "))
+	return NewFailoverProvider(channel, chainNames, chain, config.Breaker), nil
+}
+
+func newProvider(channel Channel, name string, config FactoryConfig) (Provider, error) {
+	switch {
+	case name == "mock":
+		return NewMockProvider(), nil
+	case channel == ChannelEmail && name == "email":
+		return NewEmailProvider(), nil
+	case channel == ChannelEmail && name == "smtp":
+		return NewSMTPProvider(config.SMTP)
+	case channel == ChannelSMS && name == "twilio":
+		return NewTwilioProvider(config.Twilio)
+	case channel == ChannelWebhook && name == "webhook":
+		return NewWebhookProvider(), nil
+	default:
+		return nil, fmt.Errorf("unknown %s notification provider: %s", channel, name)
+	}
 }
 
+// NewService creates a new notification service with the configured providers.
+// repo may be nil when no database is configured.
+func (f *Factory) NewService(repo repos.Repository) *NotificationService {
+	providers := make(map[Channel]Provider, len(f.providers)+1)
+	for channel, provider := range f.providers {
+		providers[channel] = provider
+	}
+
+	service := NewNotificationService(providers, repo)
+
+	if f.inApp {
+		if repo != nil {
+			providers[ChannelInApp] = NewInAppProvider(repo, service.broker)
+		} else {
+			log.Printf("NotificationFactory: in_app channel requires a database, disabling it")
+		}
+	}
+
+	return service
+}
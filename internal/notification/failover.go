@@ -0,0 +1,281 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Circuit breaker states
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+// providerMetrics publishes per-provider counters and breaker state at /debug/vars
+var providerMetrics = expvar.NewMap("notification_providers")
+
+// RecipientError marks a failure caused by the recipient or the message rather than the
+// provider, such as a rejected address or a webhook that answered 4xx. The provider is
+// working, so it doesn't count toward the circuit breaker, isn't retried on fallback
+// providers and dead-letters the outbox message straight away.
+type RecipientError struct {
+	Err error
+}
+
+func (e *RecipientError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RecipientError) Unwrap() error {
+	return e.Err
+}
+
+// RecipientFailure wraps err as a RecipientError
+func RecipientFailure(err error) error {
+	return &RecipientError{Err: err}
+}
+
+// IsRecipientError reports whether err, or an error it wraps, is a RecipientError
+func IsRecipientError(err error) bool {
+	var recipientErr *RecipientError
+	return errors.As(err, &recipientErr)
+}
+
+// BreakerConfig controls when a provider's circuit breaker opens and how long it stays open
+type BreakerConfig struct {
+	// Threshold is the number of consecutive failures that opens the breaker
+	Threshold int
+	// Cooldown is how long an open breaker rejects sends before letting a trial send through
+	Cooldown time.Duration
+}
+
+// ProviderHealth describes the state of one provider in a failover chain
+type ProviderHealth struct {
+	Channel             Channel    `json:"channel"`
+	Provider            string     `json:"provider"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	Sent                int64      `json:"sent"`
+	Failed              int64      `json:"failed"`
+	Rejected            int64      `json:"rejected"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastFailureAt       *time.Time `json:"last_failure_at,omitempty"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+}
+
+// circuitBreaker wraps a provider and stops sending to it after repeated failures.
+// Once the cooldown passes a single trial send is allowed; success closes the
+// breaker again and failure re-opens it.
+type circuitBreaker struct {
+	name     string
+	channel  Channel
+	provider Provider
+	config   BreakerConfig
+
+	mu            sync.Mutex
+	state         string
+	failures      int
+	openedAt      time.Time
+	trialInFlight bool
+	lastError     string
+	lastFailureAt time.Time
+
+	sent     expvar.Int
+	failed   expvar.Int
+	rejected expvar.Int
+	status   expvar.String
+}
+
+func newCircuitBreaker(channel Channel, name string, provider Provider, config BreakerConfig) *circuitBreaker {
+	b := &circuitBreaker{
+		name:     name,
+		channel:  channel,
+		provider: provider,
+		config:   config,
+		state:    BreakerClosed,
+	}
+	b.status.Set(BreakerClosed)
+
+	metrics := new(expvar.Map).Init()
+	metrics.Set("sent", &b.sent)
+	metrics.Set("failed", &b.failed)
+	metrics.Set("rejected", &b.rejected)
+	metrics.Set("state", &b.status)
+	providerMetrics.Set(fmt.Sprintf("%s/%s", channel, name), metrics)
+
+	return b
+}
+
+// allow reports whether a send may be attempted now
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.config.Cooldown {
+			return false
+		}
+		b.setState(BreakerHalfOpen)
+		b.trialInFlight = true
+		return true
+	case BreakerHalfOpen:
+		if b.trialInFlight {
+			return false
+		}
+		b.trialInFlight = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record updates the breaker with the outcome of a send. Only provider failures, such as
+// transport errors, timeouts and 5xx responses, count toward opening it; a recipient
+// rejection shows the provider is up.
+func (b *circuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trialInFlight = false
+	if err == nil || IsRecipientError(err) {
+		if err == nil {
+			b.sent.Add(1)
+		} else {
+			b.rejected.Add(1)
+		}
+		b.failures = 0
+		if b.state != BreakerClosed {
+			log.Printf("NotificationFailover: %s provider %s recovered, closing breaker", b.channel, b.name)
+			b.setState(BreakerClosed)
+		}
+		return
+	}
+
+	b.failed.Add(1)
+	b.failures++
+	b.lastError = err.Error()
+	b.lastFailureAt = time.Now()
+
+	if b.state == BreakerHalfOpen || b.failures >= b.config.Threshold {
+		if b.state != BreakerOpen {
+			log.Printf("NotificationFailover: Opening breaker for %s provider %s after %d consecutive failures: %v", b.channel, b.name, b.failures, err)
+		}
+		b.setState(BreakerOpen)
+		b.openedAt = time.Now()
+	}
+}
+
+func (b *circuitBreaker) setState(state string) {
+	b.state = state
+	b.status.Set(state)
+}
+
+func (b *circuitBreaker) health() ProviderHealth {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	h := ProviderHealth{
+		Channel:             b.channel,
+		Provider:            b.name,
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		Sent:                b.sent.Value(),
+		Failed:              b.failed.Value(),
+		Rejected:            b.rejected.Value(),
+		LastError:           b.lastError,
+	}
+	if !b.lastFailureAt.IsZero() {
+		t := b.lastFailureAt
+		h.LastFailureAt = &t
+	}
+	if b.state != BreakerClosed {
+		t := b.openedAt
+		h.OpenedAt = &t
+	}
+	return h
+}
+
+// FailoverProvider sends through an ordered chain of providers, moving on to the
+// next one when a provider fails or its circuit breaker is open
+type FailoverProvider struct {
+	channel  Channel
+	breakers []*circuitBreaker
+}
+
+// NewFailoverProvider creates a failover chain. names and providers are parallel
+// slices in priority order.
+func NewFailoverProvider(channel Channel, names []string, providers []Provider, config BreakerConfig) *FailoverProvider {
+	if config.Threshold <= 0 {
+		config.Threshold = defaultBreakerThreshold
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = defaultBreakerCooldown
+	}
+
+	breakers := make([]*circuitBreaker, 0, len(providers))
+	for i, provider := range providers {
+		breakers = append(breakers, newCircuitBreaker(channel, names[i], provider, config))
+	}
+
+	return &FailoverProvider{
+		channel:  channel,
+		breakers: breakers,
+	}
+}
+
+// SendNotification tries each available provider in order until one succeeds. A
+// recipient error is returned straight away since other providers would fail the same way.
+func (p *FailoverProvider) SendNotification(ctx context.Context, recipient, subject string, data map[string]interface{}, attachments []Attachment) error {
+	var errs []error
+	for i, breaker := range p.breakers {
+		if !breaker.allow() {
+			continue
+		}
+
+		err := breaker.provider.SendNotification(ctx, recipient, subject, data, attachments)
+		breaker.record(err)
+		if err == nil {
+			if i > 0 {
+				log.Printf("NotificationFailover: Sent %s notification via fallback provider %s", p.channel, breaker.name)
+			}
+			return nil
+		}
+
+		if IsRecipientError(err) {
+			log.Printf("NotificationFailover: %s provider %s rejected the recipient: %v", p.channel, breaker.name, err)
+			return fmt.Errorf("%s: %w", breaker.name, err)
+		}
+
+		log.Printf("NotificationFailover: %s provider %s failed: %v", p.channel, breaker.name, err)
+		errs = append(errs, fmt.Errorf("%s: %w", breaker.name, err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	if len(errs) == 0 {
+		return fmt.Errorf("all %s providers are unavailable", p.channel)
+	}
+	return errors.Join(errs...)
+}
+
+// Health returns the state of every provider in the chain, in priority order
+func (p *FailoverProvider) Health() []ProviderHealth {
+	health := make([]ProviderHealth, 0, len(p.breakers))
+	for _, breaker := range p.breakers {
+		health = append(health, breaker.health())
+	}
+	return health
+}
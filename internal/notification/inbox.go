@@ -0,0 +1,157 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/johnroshan2255/core-service/internal/notification/models"
+	"github.com/johnroshan2255/core-service/internal/notification/repos"
+	"gorm.io/gorm"
+)
+
+// ErrInboxNotificationNotFound is returned when an in-app notification doesn't exist
+// or belongs to another user
+var ErrInboxNotificationNotFound = errors.New("notification not found")
+
+// InAppProvider stores notifications in the user's in-app inbox.
+// The recipient is the user's UUID.
+type InAppProvider struct {
+	repo   repos.Repository
+	broker *Broker
+}
+
+// NewInAppProvider creates a new in-app notification provider.
+// Stored notifications are also published to broker for real-time delivery.
+func NewInAppProvider(repo repos.Repository, broker *Broker) *InAppProvider {
+	return &InAppProvider{
+		repo:   repo,
+		broker: broker,
+	}
+}
+
+// SendNotification adds the notification to the recipient's inbox
+func (p *InAppProvider) SendNotification(ctx context.Context, recipient, subject string, data map[string]interface{}, attachments []Attachment) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification data: %w", err)
+	}
+
+	n := &models.InAppNotification{
+		UserUUID: recipient,
+		Title:    subject,
+		Data:     string(payload),
+	}
+	if notificationType, ok := data["type"].(string); ok {
+		n.NotificationType = notificationType
+	}
+	if message, ok := data["message"].(string); ok {
+		n.Body = message
+	}
+
+	if err := p.repo.CreateInAppNotification(ctx, n); err != nil {
+		return fmt.Errorf("failed to store in-app notification: %w", err)
+	}
+
+	log.Printf("InAppProvider: Stored notification %d for user %s: %s", n.ID, recipient, subject)
+
+	if p.broker != nil {
+		p.broker.Publish(Event{
+			Type:     EventNotificationCreated,
+			UserUUID: recipient,
+			Payload: map[string]interface{}{
+				"id":                n.ID,
+				"notification_type": n.NotificationType,
+				"title":             n.Title,
+				"body":              n.Body,
+			},
+			CreatedAt: n.CreatedAt,
+		})
+	}
+	return nil
+}
+
+// ListInbox returns the user's in-app notifications, newest first
+func (s *NotificationService) ListInbox(ctx context.Context, userUUID string, unreadOnly bool, limit, offset int) ([]models.InAppNotification, error) {
+	if userUUID == "" {
+		return nil, fmt.Errorf("user UUID is required")
+	}
+	if s.repo == nil {
+		return nil, fmt.Errorf("notification inbox is not available")
+	}
+
+	notifications, err := s.repo.GetInAppNotifications(ctx, userUUID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inbox: %w", err)
+	}
+	return notifications, nil
+}
+
+// CountUnread returns the number of unread in-app notifications for the user
+func (s *NotificationService) CountUnread(ctx context.Context, userUUID string) (int64, error) {
+	if userUUID == "" {
+		return 0, fmt.Errorf("user UUID is required")
+	}
+	if s.repo == nil {
+		return 0, fmt.Errorf("notification inbox is not available")
+	}
+
+	count, err := s.repo.CountUnreadInAppNotifications(ctx, userUUID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+// MarkRead marks one of the user's in-app notifications as read
+func (s *NotificationService) MarkRead(ctx context.Context, userUUID string, id uint) error {
+	if userUUID == "" {
+		return fmt.Errorf("user UUID is required")
+	}
+	if s.repo == nil {
+		return fmt.Errorf("notification inbox is not available")
+	}
+
+	if err := s.repo.MarkInAppNotificationRead(ctx, userUUID, id); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrInboxNotificationNotFound
+		}
+		return fmt.Errorf("failed to mark notification as read: %w", err)
+	}
+	return nil
+}
+
+// MarkAllRead marks all of the user's in-app notifications as read
+func (s *NotificationService) MarkAllRead(ctx context.Context, userUUID string) error {
+	if userUUID == "" {
+		return fmt.Errorf("user UUID is required")
+	}
+	if s.repo == nil {
+		return fmt.Errorf("notification inbox is not available")
+	}
+
+	if err := s.repo.MarkAllInAppNotificationsRead(ctx, userUUID); err != nil {
+		return fmt.Errorf("failed to mark notifications as read: %w", err)
+	}
+	return nil
+}
+
+// DeleteInboxNotification removes one of the user's in-app notifications
+func (s *NotificationService) DeleteInboxNotification(ctx context.Context, userUUID string, id uint) error {
+	if userUUID == "" {
+		return fmt.Errorf("user UUID is required")
+	}
+	if s.repo == nil {
+		return fmt.Errorf("notification inbox is not available")
+	}
+
+	if err := s.repo.DeleteInAppNotification(ctx, userUUID, id); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrInboxNotificationNotFound
+		}
+		return fmt.Errorf("failed to delete notification: %w", err)
+	}
+	return nil
+}
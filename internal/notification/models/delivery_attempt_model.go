@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusDelivered  DeliveryStatus = "delivered"
+	DeliveryStatusFailed     DeliveryStatus = "failed"
+	DeliveryStatusSuppressed DeliveryStatus = "suppressed"
+)
+
+// DeliveryAttempt records one attempt to hand a notification to a provider
+type DeliveryAttempt struct {
+	ID               uint           `gorm:"primaryKey" json:"id"`
+	OutboxMessageID  uint           `gorm:"index" json:"outbox_message_id"`
+	NotificationType string         `gorm:"type:varchar(50);not null" json:"notification_type"`
+	UserUUID         string         `gorm:"type:varchar(255);index" json:"user_uuid"`
+	TenantID         string         `gorm:"type:varchar(255);index;not null;default:''" json:"tenant_id"`
+	Channel          string         `gorm:"type:varchar(20);not null" json:"channel"`
+	Recipient        string         `gorm:"type:varchar(1000);not null" json:"recipient"`
+	Subject          string         `gorm:"type:varchar(500)" json:"subject"`
+	Attempt          int            `json:"attempt"`
+	Status           DeliveryStatus `gorm:"type:varchar(20);not null" json:"status"`
+	ProviderResponse string         `gorm:"type:text" json:"provider_response"`
+	QueuedAt         time.Time      `json:"queued_at"`
+	AttemptedAt      time.Time      `gorm:"index" json:"attempted_at"`
+}
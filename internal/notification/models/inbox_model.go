@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InAppNotification is a notification shown in the web app's notification bell
+type InAppNotification struct {
+	ID               uint           `gorm:"primaryKey" json:"id"`
+	UserUUID         string         `gorm:"type:varchar(255);index;not null" json:"user_uuid"`
+	TenantID         string         `gorm:"type:varchar(255);index;not null;default:''" json:"tenant_id"`
+	NotificationType string         `gorm:"type:varchar(50)" json:"notification_type"`
+	Title            string         `gorm:"type:varchar(500);not null" json:"title"`
+	Body             string         `gorm:"type:text" json:"body"`
+	Data             string         `gorm:"type:jsonb" json:"data,omitempty"`
+	ReadAt           *time.Time     `gorm:"index" json:"read_at,omitempty"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxStatusPending OutboxStatus = "pending"
+	OutboxStatusSent    OutboxStatus = "sent"
+	OutboxStatusDead    OutboxStatus = "dead"
+)
+
+// OutboxMessage is a single channel delivery waiting to be handed to a provider.
+// Attachments holds JSON but is stored as text, since it is empty for most messages.
+// Data and Attachments are cleared once the message is sent, and when a message carrying
+// a secret link is dead-lettered; Redacted marks such messages.
+type OutboxMessage struct {
+	ID               uint         `gorm:"primaryKey" json:"id"`
+	NotificationType string       `gorm:"type:varchar(50);not null" json:"notification_type"`
+	UserUUID         string       `gorm:"type:varchar(255);index" json:"user_uuid"`
+	TenantID         string       `gorm:"type:varchar(255);index;not null;default:''" json:"tenant_id"`
+	Channel          string       `gorm:"type:varchar(20);not null" json:"channel"`
+	Recipient        string       `gorm:"type:varchar(1000);not null" json:"recipient"`
+	Subject          string       `gorm:"type:varchar(500)" json:"subject"`
+	Data             string       `gorm:"type:jsonb" json:"data"`
+	Attachments      string       `gorm:"type:text" json:"-"`
+	Status           OutboxStatus `gorm:"type:varchar(20);index:idx_outbox_due;default:'pending'" json:"status"`
+	Attempts         int          `gorm:"default:0" json:"attempts"`
+	MaxAttempts      int          `gorm:"default:5" json:"max_attempts"`
+	NextAttemptAt    time.Time    `gorm:"index:idx_outbox_due" json:"next_attempt_at"`
+	LastError        string       `gorm:"type:text" json:"last_error,omitempty"`
+	SentAt           *time.Time   `json:"sent_at,omitempty"`
+	Redacted         bool         `gorm:"default:false" json:"redacted"`
+	CreatedAt        time.Time    `json:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at"`
+}
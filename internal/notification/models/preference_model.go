@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// Expiry delivery modes
+const (
+	ExpiryDeliveryDigest     = "digest"
+	ExpiryDeliveryIndividual = "individual"
+)
+
+// NotificationPreference holds per-user delivery settings shared by all notification types
+type NotificationPreference struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	UserUUID        string    `gorm:"type:uuid;uniqueIndex;not null" json:"user_uuid"`
+	TenantID        string    `gorm:"type:varchar(255);index;not null;default:''" json:"tenant_id"`
+	PhoneNumber     string    `gorm:"type:varchar(20)" json:"phone_number"`
+	WebhookURL      string    `gorm:"type:varchar(1000)" json:"webhook_url"`
+	QuietHoursStart string    `gorm:"type:varchar(5)" json:"quiet_hours_start"`
+	QuietHoursEnd   string    `gorm:"type:varchar(5)" json:"quiet_hours_end"`
+	Timezone        string    `gorm:"type:varchar(64);default:'UTC'" json:"timezone"`
+	ExpiryDelivery  string    `gorm:"type:varchar(20);default:'digest'" json:"expiry_delivery"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// ChannelPreference opts a user in or out of a channel for a notification type
+type ChannelPreference struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	UserUUID         string    `gorm:"type:uuid;uniqueIndex:idx_channel_pref;not null" json:"user_uuid"`
+	TenantID         string    `gorm:"type:varchar(255);index;not null;default:''" json:"tenant_id"`
+	NotificationType string    `gorm:"type:varchar(50);uniqueIndex:idx_channel_pref;not null" json:"notification_type"`
+	Channel          string    `gorm:"type:varchar(20);uniqueIndex:idx_channel_pref;not null" json:"channel"`
+	Enabled          bool      `gorm:"default:true" json:"enabled"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+type ScheduledStatus string
+
+const (
+	ScheduledStatusPending   ScheduledStatus = "pending"
+	ScheduledStatusSent      ScheduledStatus = "sent"
+	ScheduledStatusCancelled ScheduledStatus = "cancelled"
+	ScheduledStatusFailed    ScheduledStatus = "failed"
+)
+
+// ScheduledNotification is a generic notification held back until SendAt
+type ScheduledNotification struct {
+	ID          uint            `gorm:"primaryKey" json:"id"`
+	EventType   string          `gorm:"type:varchar(50);not null" json:"event_type"`
+	UserUUID    string          `gorm:"type:varchar(255);index:idx_scheduled_key;not null" json:"user_uuid"`
+	TenantID    string          `gorm:"type:varchar(255);index;not null;default:''" json:"tenant_id"`
+	Email       string          `gorm:"type:varchar(320)" json:"email"`
+	Locale      string          `gorm:"type:varchar(20)" json:"locale"`
+	Payload     string          `gorm:"type:jsonb" json:"payload"`
+	Key         string          `gorm:"type:varchar(255);index:idx_scheduled_key" json:"key,omitempty"`
+	SendAt      time.Time       `gorm:"index;not null" json:"send_at"`
+	Status      ScheduledStatus `gorm:"type:varchar(20);index;not null" json:"status"`
+	Attempts    int             `gorm:"default:0" json:"attempts"`
+	LockedUntil *time.Time      `json:"-"`
+	LastError   string          `gorm:"type:text" json:"last_error,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
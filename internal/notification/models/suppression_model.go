@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// Suppression reasons
+const (
+	SuppressionReasonBounce    = "bounce"
+	SuppressionReasonComplaint = "complaint"
+	SuppressionReasonManual    = "manual"
+)
+
+// EmailSuppression is an address no email should be sent to, usually because it
+// hard-bounced or the recipient marked a message as spam
+type EmailSuppression struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Email     string    `gorm:"type:varchar(320);uniqueIndex;not null" json:"email"`
+	Reason    string    `gorm:"type:varchar(20);not null" json:"reason"`
+	Detail    string    `gorm:"type:text" json:"detail"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,152 @@
+package notification
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/johnroshan2255/core-service/internal/notification/models"
+	"github.com/johnroshan2255/core-service/internal/tenant"
+)
+
+const (
+	outboxBatchSize    = 50
+	outboxPollInterval = 5 * time.Second
+	outboxLease        = 5 * time.Minute
+	outboxBaseBackoff  = 30 * time.Second
+	outboxMaxBackoff   = time.Hour
+)
+
+// OutboxWorker delivers queued outbox messages and retries failures with exponential backoff
+type OutboxWorker struct {
+	service *NotificationService
+	workers int
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewOutboxWorker creates a worker pool that drains the notification outbox
+func NewOutboxWorker(service *NotificationService, workers int) *OutboxWorker {
+	if workers < 1 {
+		workers = 1
+	}
+	return &OutboxWorker{
+		service: service,
+		workers: workers,
+		stop:    make(chan struct{}),
+	}
+}
+
+// Start launches the outbox workers
+func (w *OutboxWorker) Start(ctx context.Context) {
+	if w.service.repo == nil {
+		log.Printf("OutboxWorker: Notification repository not configured, outbox delivery disabled")
+		return
+	}
+
+	for i := 0; i < w.workers; i++ {
+		w.wg.Add(1)
+		go w.run(ctx)
+	}
+	log.Printf("OutboxWorker: Started %d worker(s)", w.workers)
+}
+
+// Stop signals the workers to exit and waits for in-flight deliveries to finish
+func (w *OutboxWorker) Stop() {
+	close(w.stop)
+	w.wg.Wait()
+	log.Printf("OutboxWorker: Stopped")
+}
+
+func (w *OutboxWorker) run(ctx context.Context) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	for {
+		for w.processBatch(ctx) == outboxBatchSize {
+			select {
+			case <-w.stop:
+				return
+			default:
+			}
+		}
+
+		select {
+		case <-w.stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processBatch claims and delivers one batch of due messages and returns how many were claimed
+func (w *OutboxWorker) processBatch(ctx context.Context) int {
+	msgs, err := w.service.repo.ClaimDueOutboxMessages(tenant.Unscoped(ctx), outboxBatchSize, outboxLease)
+	if err != nil {
+		log.Printf("OutboxWorker: Failed to claim outbox messages: %v", err)
+		return 0
+	}
+
+	for i := range msgs {
+		// Deliver as the message's tenant so the inbox entry and delivery attempt land in it
+		w.processMessage(tenant.WithTenant(ctx, msgs[i].TenantID), &msgs[i])
+	}
+	return len(msgs)
+}
+
+func (w *OutboxWorker) processMessage(ctx context.Context, msg *models.OutboxMessage) {
+	err := w.service.deliver(ctx, msg)
+	if err == nil {
+		if err := w.service.repo.MarkOutboxSent(ctx, msg.ID); err != nil {
+			log.Printf("OutboxWorker: Failed to mark message %d as sent: %v", msg.ID, err)
+		}
+		return
+	}
+
+	attempts := msg.Attempts + 1
+	maxAttempts := msg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	// Retrying a rejected recipient would only fail again
+	dead := attempts >= maxAttempts || IsRecipientError(err)
+	nextAttemptAt := time.Now().Add(backoff(attempts))
+
+	if dead {
+		log.Printf("OutboxWorker: Message %d (%s via %s) dead-lettered after %d attempts: %v", msg.ID, msg.NotificationType, msg.Channel, attempts, err)
+	} else {
+		log.Printf("OutboxWorker: Message %d (%s via %s) failed attempt %d/%d, retrying at %s: %v",
+			msg.ID, msg.NotificationType, msg.Channel, attempts, maxAttempts, nextAttemptAt.Format(time.RFC3339), err)
+	}
+
+	if err := w.service.repo.MarkOutboxFailed(ctx, msg.ID, attempts, nextAttemptAt, err.Error(), dead); err != nil {
+		log.Printf("OutboxWorker: Failed to record failure for message %d: %v", msg.ID, err)
+		return
+	}
+
+	// A dead-lettered reset or verification link must not sit in the table; the user
+	// can request a new one
+	if dead && carriesSecret(msg.NotificationType) {
+		if err := w.service.repo.RedactOutboxMessage(ctx, msg.ID); err != nil {
+			log.Printf("OutboxWorker: Failed to redact dead-lettered message %d: %v", msg.ID, err)
+		}
+	}
+}
+
+// backoff returns the delay before the next attempt, doubling per attempt with up to 20% jitter
+func backoff(attempts int) time.Duration {
+	delay := outboxBaseBackoff
+	for i := 1; i < attempts && delay < outboxMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > outboxMaxBackoff {
+		delay = outboxMaxBackoff
+	}
+	return delay + time.Duration(rand.Int63n(int64(delay)/5+1))
+}
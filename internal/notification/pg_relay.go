@@ -0,0 +1,88 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/johnroshan2255/core-service/internal/tenant"
+	"gorm.io/gorm"
+)
+
+// eventChannel is the Postgres channel events are relayed over
+const eventChannel = "core_notification_events"
+
+// maxEventPayload keeps NOTIFY payloads under Postgres's 8000 byte limit
+const maxEventPayload = 7900
+
+// ErrEventTooLarge is returned for events whose encoding exceeds the NOTIFY payload limit
+var ErrEventTooLarge = errors.New("event is too large to relay")
+
+// PostgresRelay relays broker events between instances with Postgres LISTEN/NOTIFY.
+// Notifications are not persisted, so an instance misses events sent while it is
+// reconnecting; the inbox remains the source of truth.
+type PostgresRelay struct {
+	db  *gorm.DB
+	dsn string
+}
+
+// NewPostgresRelay creates a relay that publishes through db and listens on a
+// dedicated connection to dsn, since LISTEN needs a session outside the pool
+func NewPostgresRelay(db *gorm.DB, dsn string) *PostgresRelay {
+	return &PostgresRelay{db: db, dsn: dsn}
+}
+
+// Publish sends event to every listening instance
+func (r *PostgresRelay) Publish(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	if len(payload) > maxEventPayload {
+		return ErrEventTooLarge
+	}
+	return r.db.WithContext(tenant.Unscoped(ctx)).Exec("SELECT pg_notify(?, ?)", eventChannel, string(payload)).Error
+}
+
+// Listen subscribes to the event channel and streams the events received on it
+func (r *PostgresRelay) Listen(ctx context.Context) (<-chan Event, error) {
+	conn, err := pgx.Connect(ctx, r.dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	if _, err := conn.Exec(ctx, "LISTEN "+eventChannel); err != nil {
+		conn.Close(context.Background())
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+
+	events := make(chan Event, subscriberBuffer)
+	go func() {
+		defer close(events)
+		defer conn.Close(context.Background())
+
+		for {
+			notification, err := conn.WaitForNotification(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("PostgresRelay: Stopped listening: %v", err)
+				}
+				return
+			}
+
+			var event Event
+			if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+				log.Printf("PostgresRelay: Ignoring malformed event: %v", err)
+				continue
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
@@ -0,0 +1,56 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnroshan2255/core-service/internal/tenant/tenanttest"
+)
+
+func TestPostgresRelayDeliversEvents(t *testing.T) {
+	relay := NewPostgresRelay(tenanttest.OpenPostgres(t), os.Getenv(tenanttest.PostgresEnv))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := relay.Listen(ctx)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	if err := relay.Publish(ctx, Event{Type: EventNotificationCreated, UserUUID: "user-1", Payload: map[string]interface{}{"id": 7}}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	select {
+	case event := <-events:
+		if event.Type != EventNotificationCreated || event.UserUUID != "user-1" || event.Payload["id"] != float64(7) {
+			t.Fatalf("got %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no event received")
+	}
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatalf("received an event after cancelling")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("events channel not closed after cancelling")
+	}
+}
+
+func TestPostgresRelayRejectsOversizedEvents(t *testing.T) {
+	relay := NewPostgresRelay(nil, "")
+	err := relay.Publish(context.Background(), Event{
+		Type:    EventNotificationCreated,
+		Payload: map[string]interface{}{"body": strings.Repeat("x", maxEventPayload)},
+	})
+	if !errors.Is(err, ErrEventTooLarge) {
+		t.Fatalf("got %v, want ErrEventTooLarge", err)
+	}
+}
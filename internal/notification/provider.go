@@ -1,13 +1,28 @@
 package notification
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"net/http"
+	"sort"
+	"time"
 )
 
-// Provider defines the interface for notification providers (email, SMS, push, etc.)
+// Attachment is a file sent along with a notification
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Content     []byte `json:"content"`
+}
+
+// Provider defines the interface for notification providers (email, SMS, push, etc.).
+// Providers that cannot carry files ignore attachments.
 type Provider interface {
-	SendNotification(ctx context.Context, recipient, subject string, data map[string]interface{}) error
+	SendNotification(ctx context.Context, recipient, subject string, data map[string]interface{}, attachments []Attachment) error
 }
 
 // EmailProvider is a basic email notification provider
@@ -20,9 +35,15 @@ func NewEmailProvider() *EmailProvider {
 }
 
 // SendNotification sends an email notification
-func (p *EmailProvider) SendNotification(ctx context.Context, recipient, subject string, data map[string]interface{}) error {
+func (p *EmailProvider) SendNotification(ctx context.Context, recipient, subject string, data map[string]interface{}, attachments []Attachment) error {
 	log.Printf("EmailProvider: Sending email to %s with subject: %s", recipient, subject)
-	log.Printf("EmailProvider: Notification data: %+v", data)
+	log.Printf("EmailProvider: Notification fields: %v", dataKeys(data))
+	if _, ok := data["unsubscribe_url"].(string); ok {
+		log.Printf("EmailProvider: List-Unsubscribe header set")
+	}
+	for _, attachment := range attachments {
+		log.Printf("EmailProvider: Attachment %s (%s, %d bytes)", attachment.Filename, attachment.ContentType, len(attachment.Content))
+	}
 	return nil
 }
 
@@ -35,7 +56,76 @@ func NewMockProvider() *MockProvider {
 }
 
 // SendNotification logs the notification without actually sending it
-func (p *MockProvider) SendNotification(ctx context.Context, recipient, subject string, data map[string]interface{}) error {
-	log.Printf("MockProvider: Would send notification to %s: %s - %+v", recipient, subject, data)
+func (p *MockProvider) SendNotification(ctx context.Context, recipient, subject string, data map[string]interface{}, attachments []Attachment) error {
+	log.Printf("MockProvider: Would send notification to %s: %s - fields %v (%d attachments)", recipient, subject, dataKeys(data), len(attachments))
+	return nil
+}
+
+// dataKeys returns the sorted field names of a notification payload. Providers log these
+// instead of the values, which can carry account links and unsubscribe tokens.
+func dataKeys(data map[string]interface{}) []string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WebhookProvider delivers notifications as JSON POST requests to a user-supplied URL.
+// Only public https endpoints are reachable; see ValidateWebhookURL.
+type WebhookProvider struct {
+	client *http.Client
+}
+
+// NewWebhookProvider creates a new webhook notification provider
+func NewWebhookProvider() *WebhookProvider {
+	return &WebhookProvider{
+		client: newWebhookClient(10 * time.Second),
+	}
+}
+
+// SendNotification posts the notification to the recipient URL
+func (p *WebhookProvider) SendNotification(ctx context.Context, recipient, subject string, data map[string]interface{}, attachments []Attachment) error {
+	// URLs stored before validation existed are re-checked here; the dialer also
+	// re-checks the address actually connected to
+	if err := ValidateWebhookURL(ctx, recipient); err != nil {
+		return RecipientFailure(err)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"subject":     subject,
+		"data":        data,
+		"attachments": attachments,
+	})
+	if err != nil {
+		return RecipientFailure(fmt.Errorf("failed to encode webhook payload: %w", err))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, recipient, bytes.NewReader(body))
+	if err != nil {
+		return RecipientFailure(fmt.Errorf("failed to create webhook request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidWebhookURL) {
+			return RecipientFailure(err)
+		}
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err := fmt.Errorf("webhook returned status %d", resp.StatusCode)
+		// 4xx means the endpoint itself refused the request; 408 and 429 are worth retrying
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+			return RecipientFailure(err)
+		}
+		return err
+	}
+
+	log.Printf("WebhookProvider: Delivered notification to %s: %s", recipient, subject)
 	return nil
 }
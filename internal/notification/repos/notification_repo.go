@@ -0,0 +1,370 @@
+package repos
+
+import (
+	"context"
+	"time"
+
+	"github.com/johnroshan2255/core-service/internal/notification/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	GetPreference(ctx context.Context, userUUID string) (*models.NotificationPreference, error)
+	UpdatePreference(ctx context.Context, pref *models.NotificationPreference) error
+
+	GetChannelPreferences(ctx context.Context, userUUID string) ([]models.ChannelPreference, error)
+	UpsertChannelPreference(ctx context.Context, pref *models.ChannelPreference) error
+
+	CreateOutboxMessages(ctx context.Context, msgs []models.OutboxMessage) error
+	ClaimDueOutboxMessages(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxMessage, error)
+	MarkOutboxSent(ctx context.Context, id uint) error
+	MarkOutboxFailed(ctx context.Context, id uint, attempts int, nextAttemptAt time.Time, lastError string, dead bool) error
+	RedactOutboxMessage(ctx context.Context, id uint) error
+	GetDeadLetters(ctx context.Context, limit, offset int) ([]models.OutboxMessage, error)
+	ReplayDeadLetter(ctx context.Context, id uint) error
+
+	CreateDeliveryAttempt(ctx context.Context, attempt *models.DeliveryAttempt) error
+	GetDeliveryAttempts(ctx context.Context, userUUID, notificationType string, limit, offset int) ([]models.DeliveryAttempt, error)
+
+	CreateInAppNotification(ctx context.Context, n *models.InAppNotification) error
+	GetInAppNotifications(ctx context.Context, userUUID string, unreadOnly bool, limit, offset int) ([]models.InAppNotification, error)
+	CountUnreadInAppNotifications(ctx context.Context, userUUID string) (int64, error)
+	MarkInAppNotificationRead(ctx context.Context, userUUID string, id uint) error
+	MarkAllInAppNotificationsRead(ctx context.Context, userUUID string) error
+	DeleteInAppNotification(ctx context.Context, userUUID string, id uint) error
+
+	IsEmailSuppressed(ctx context.Context, email string) (bool, error)
+	UpsertEmailSuppression(ctx context.Context, suppression *models.EmailSuppression) error
+
+	CreateScheduledNotification(ctx context.Context, n *models.ScheduledNotification) error
+	ClaimDueScheduledNotifications(ctx context.Context, limit int, lease time.Duration) ([]models.ScheduledNotification, error)
+	MarkScheduledNotification(ctx context.Context, id uint, status models.ScheduledStatus, attempts int, lastError string) error
+	CancelScheduledNotification(ctx context.Context, id uint) (int64, error)
+	CancelScheduledNotificationsByKey(ctx context.Context, userUUID, key string) (int64, error)
+}
+
+type GORMRepository struct {
+	db *gorm.DB
+}
+
+func NewGORMRepository(db *gorm.DB) *GORMRepository {
+	return &GORMRepository{
+		db: db,
+	}
+}
+
+func (r *GORMRepository) GetPreference(ctx context.Context, userUUID string) (*models.NotificationPreference, error) {
+	var pref models.NotificationPreference
+	if err := r.db.WithContext(ctx).Where("user_uuid = ?", userUUID).First(&pref).Error; err != nil {
+		return nil, err
+	}
+	return &pref, nil
+}
+
+func (r *GORMRepository) UpdatePreference(ctx context.Context, pref *models.NotificationPreference) error {
+	var existing models.NotificationPreference
+	err := r.db.WithContext(ctx).Where("user_uuid = ?", pref.UserUUID).First(&existing).Error
+	if err == gorm.ErrRecordNotFound {
+		return r.db.WithContext(ctx).Create(pref).Error
+	}
+	if err != nil {
+		return err
+	}
+	pref.ID = existing.ID
+	pref.CreatedAt = existing.CreatedAt
+	return r.db.WithContext(ctx).Save(pref).Error
+}
+
+func (r *GORMRepository) GetChannelPreferences(ctx context.Context, userUUID string) ([]models.ChannelPreference, error) {
+	var prefs []models.ChannelPreference
+	if err := r.db.WithContext(ctx).Where("user_uuid = ?", userUUID).Find(&prefs).Error; err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+func (r *GORMRepository) UpsertChannelPreference(ctx context.Context, pref *models.ChannelPreference) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_uuid"}, {Name: "notification_type"}, {Name: "channel"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(pref).Error
+}
+
+func (r *GORMRepository) CreateOutboxMessages(ctx context.Context, msgs []models.OutboxMessage) error {
+	return r.db.WithContext(ctx).Create(&msgs).Error
+}
+
+// ClaimDueOutboxMessages locks a batch of due messages and pushes their next attempt
+// past the lease so that concurrent workers and crashed deliveries don't double-send
+func (r *GORMRepository) ClaimDueOutboxMessages(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxMessage, error) {
+	var msgs []models.OutboxMessage
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", models.OutboxStatusPending).
+			Where("next_attempt_at <= ?", now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&msgs).Error; err != nil {
+			return err
+		}
+		if len(msgs) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(msgs))
+		for i, msg := range msgs {
+			ids[i] = msg.ID
+		}
+		return tx.Model(&models.OutboxMessage{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return msgs, nil
+}
+
+// MarkOutboxSent records a delivered message and drops its payload, which is no longer
+// needed and may hold secrets such as password reset links
+func (r *GORMRepository) MarkOutboxSent(ctx context.Context, id uint) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).Model(&models.OutboxMessage{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":      models.OutboxStatusSent,
+		"attempts":    gorm.Expr("attempts + 1"),
+		"sent_at":     &now,
+		"data":        "{}",
+		"attachments": "",
+		"redacted":    true,
+	}).Error
+}
+
+func (r *GORMRepository) MarkOutboxFailed(ctx context.Context, id uint, attempts int, nextAttemptAt time.Time, lastError string, dead bool) error {
+	status := models.OutboxStatusPending
+	if dead {
+		status = models.OutboxStatusDead
+	}
+	return r.db.WithContext(ctx).Model(&models.OutboxMessage{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          status,
+		"attempts":        attempts,
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      lastError,
+	}).Error
+}
+
+// RedactOutboxMessage drops a message's payload. Redacted dead letters can't be replayed.
+func (r *GORMRepository) RedactOutboxMessage(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Model(&models.OutboxMessage{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"data":        "{}",
+		"attachments": "",
+		"redacted":    true,
+	}).Error
+}
+
+func (r *GORMRepository) GetDeadLetters(ctx context.Context, limit, offset int) ([]models.OutboxMessage, error) {
+	var msgs []models.OutboxMessage
+	query := r.db.WithContext(ctx).Where("status = ?", models.OutboxStatusDead).Order("updated_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	if err := query.Find(&msgs).Error; err != nil {
+		return nil, err
+	}
+	return msgs, nil
+}
+
+func (r *GORMRepository) ReplayDeadLetter(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Model(&models.OutboxMessage{}).
+		Where("id = ? AND status = ? AND redacted = ?", id, models.OutboxStatusDead, false).
+		Updates(map[string]interface{}{
+			"status":          models.OutboxStatusPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+			"last_error":      "",
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *GORMRepository) CreateDeliveryAttempt(ctx context.Context, attempt *models.DeliveryAttempt) error {
+	return r.db.WithContext(ctx).Create(attempt).Error
+}
+
+func (r *GORMRepository) GetDeliveryAttempts(ctx context.Context, userUUID, notificationType string, limit, offset int) ([]models.DeliveryAttempt, error) {
+	var attempts []models.DeliveryAttempt
+	query := r.db.WithContext(ctx).Where("user_uuid = ?", userUUID)
+	if notificationType != "" {
+		query = query.Where("notification_type = ?", notificationType)
+	}
+	query = query.Order("attempted_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	if err := query.Find(&attempts).Error; err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+func (r *GORMRepository) CreateInAppNotification(ctx context.Context, n *models.InAppNotification) error {
+	return r.db.WithContext(ctx).Create(n).Error
+}
+
+func (r *GORMRepository) GetInAppNotifications(ctx context.Context, userUUID string, unreadOnly bool, limit, offset int) ([]models.InAppNotification, error) {
+	var notifications []models.InAppNotification
+	query := r.db.WithContext(ctx).Where("user_uuid = ?", userUUID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	query = query.Order("created_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	if err := query.Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (r *GORMRepository) CountUnreadInAppNotifications(ctx context.Context, userUUID string) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.InAppNotification{}).
+		Where("user_uuid = ? AND read_at IS NULL", userUUID).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *GORMRepository) MarkInAppNotificationRead(ctx context.Context, userUUID string, id uint) error {
+	var n models.InAppNotification
+	if err := r.db.WithContext(ctx).Where("id = ? AND user_uuid = ?", id, userUUID).First(&n).Error; err != nil {
+		return err
+	}
+	if n.ReadAt != nil {
+		return nil
+	}
+	return r.db.WithContext(ctx).Model(&n).Update("read_at", time.Now()).Error
+}
+
+func (r *GORMRepository) MarkAllInAppNotificationsRead(ctx context.Context, userUUID string) error {
+	return r.db.WithContext(ctx).Model(&models.InAppNotification{}).
+		Where("user_uuid = ? AND read_at IS NULL", userUUID).
+		Update("read_at", time.Now()).Error
+}
+
+func (r *GORMRepository) DeleteInAppNotification(ctx context.Context, userUUID string, id uint) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND user_uuid = ?", id, userUUID).Delete(&models.InAppNotification{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *GORMRepository) IsEmailSuppressed(ctx context.Context, email string) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.EmailSuppression{}).Where("email = ?", email).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *GORMRepository) UpsertEmailSuppression(ctx context.Context, suppression *models.EmailSuppression) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "email"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "detail", "updated_at"}),
+	}).Create(suppression).Error
+}
+
+func (r *GORMRepository) CreateScheduledNotification(ctx context.Context, n *models.ScheduledNotification) error {
+	return r.db.WithContext(ctx).Create(n).Error
+}
+
+// ClaimDueScheduledNotifications locks a batch of due notifications and leases them
+// so that concurrent dispatchers don't send the same notification twice
+func (r *GORMRepository) ClaimDueScheduledNotifications(ctx context.Context, limit int, lease time.Duration) ([]models.ScheduledNotification, error) {
+	var scheduled []models.ScheduledNotification
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", models.ScheduledStatusPending).
+			Where("send_at <= ?", now).
+			Where("locked_until IS NULL OR locked_until <= ?", now).
+			Order("send_at").
+			Limit(limit).
+			Find(&scheduled).Error; err != nil {
+			return err
+		}
+		if len(scheduled) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(scheduled))
+		for i, n := range scheduled {
+			ids[i] = n.ID
+		}
+		return tx.Model(&models.ScheduledNotification{}).Where("id IN ?", ids).Update("locked_until", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return scheduled, nil
+}
+
+// MarkScheduledNotification records the outcome of a send. Notifications left pending
+// keep their lease, so they are retried once it expires. It returns
+// gorm.ErrRecordNotFound if the notification is no longer pending, e.g. because it was
+// cancelled while being sent, so the cancellation isn't overwritten.
+func (r *GORMRepository) MarkScheduledNotification(ctx context.Context, id uint, status models.ScheduledStatus, attempts int, lastError string) error {
+	updates := map[string]interface{}{
+		"status":     status,
+		"attempts":   attempts,
+		"last_error": lastError,
+	}
+	if status != models.ScheduledStatusPending {
+		updates["locked_until"] = nil
+	}
+	result := r.db.WithContext(ctx).Model(&models.ScheduledNotification{}).
+		Where("id = ? AND status = ?", id, models.ScheduledStatusPending).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CancelScheduledNotification cancels a notification that hasn't been sent yet and
+// returns how many rows were cancelled (0 or 1)
+func (r *GORMRepository) CancelScheduledNotification(ctx context.Context, id uint) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.ScheduledNotification{}).
+		Where("id = ? AND status = ?", id, models.ScheduledStatusPending).
+		Update("status", models.ScheduledStatusCancelled)
+	return result.RowsAffected, result.Error
+}
+
+func (r *GORMRepository) CancelScheduledNotificationsByKey(ctx context.Context, userUUID, key string) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.ScheduledNotification{}).
+		Where("user_uuid = ? AND key = ? AND status = ?", userUUID, key, models.ScheduledStatusPending).
+		Update("status", models.ScheduledStatusCancelled)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,148 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/johnroshan2255/core-service/internal/notification/models"
+	"github.com/johnroshan2255/core-service/internal/tenant/tenanttest"
+	"gorm.io/gorm"
+)
+
+func newTestRepository(t *testing.T) (*GORMRepository, *gorm.DB) {
+	db := tenanttest.Open(t,
+		&models.NotificationPreference{},
+		&models.ChannelPreference{},
+		&models.OutboxMessage{},
+		&models.DeliveryAttempt{},
+		&models.InAppNotification{},
+		&models.ScheduledNotification{},
+	)
+	return NewGORMRepository(db), db
+}
+
+func TestPreferencesAreIsolatedByTenant(t *testing.T) {
+	repo, db := newTestRepository(t)
+
+	if err := repo.UpdatePreference(tenanttest.TenantB, &models.NotificationPreference{UserUUID: tenanttest.OwnerUUID, Timezone: "Europe/Paris"}); err != nil {
+		t.Fatalf("create preference: %v", err)
+	}
+	if err := repo.UpsertChannelPreference(tenanttest.TenantB, &models.ChannelPreference{UserUUID: tenanttest.OwnerUUID, NotificationType: "document_expiry", Channel: "email", Enabled: true}); err != nil {
+		t.Fatalf("create channel preference: %v", err)
+	}
+
+	if _, err := repo.GetPreference(tenanttest.TenantA, tenanttest.OwnerUUID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("GetPreference from another tenant: got %v, want ErrRecordNotFound", err)
+	}
+	if prefs, err := repo.GetChannelPreferences(tenanttest.TenantA, tenanttest.OwnerUUID); err != nil || len(prefs) != 0 {
+		t.Fatalf("GetChannelPreferences from another tenant: got %d rows, %v", len(prefs), err)
+	}
+
+	// The preference write doesn't see the owner's row and fails inserting a second one
+	// for the user; the channel preference upsert's conflict update is tenant-guarded
+	tenanttest.AssertWritesIsolated(t, db, []string{"notification_preferences", "channel_preferences"},
+		tenanttest.CrossTenantWrite{Name: "UpdatePreference", WantErr: true, Write: func(ctx context.Context) error {
+			return repo.UpdatePreference(ctx, &models.NotificationPreference{UserUUID: tenanttest.OwnerUUID, Timezone: "UTC"})
+		}},
+		tenanttest.CrossTenantWrite{Name: "UpsertChannelPreference", Write: func(ctx context.Context) error {
+			return repo.UpsertChannelPreference(ctx, &models.ChannelPreference{UserUUID: tenanttest.OwnerUUID, NotificationType: "document_expiry", Channel: "email", Enabled: false})
+		}},
+	)
+}
+
+func TestInboxIsIsolatedByTenant(t *testing.T) {
+	repo, _ := newTestRepository(t)
+
+	n := &models.InAppNotification{UserUUID: tenanttest.OwnerUUID, Title: "Welcome"}
+	if err := repo.CreateInAppNotification(tenanttest.TenantB, n); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if notifications, err := repo.GetInAppNotifications(tenanttest.TenantA, tenanttest.OwnerUUID, false, 0, 0); err != nil || len(notifications) != 0 {
+		t.Fatalf("GetInAppNotifications from another tenant: got %d rows, %v", len(notifications), err)
+	}
+	if count, err := repo.CountUnreadInAppNotifications(tenanttest.TenantA, tenanttest.OwnerUUID); err != nil || count != 0 {
+		t.Fatalf("CountUnreadInAppNotifications from another tenant = %d, %v", count, err)
+	}
+	if err := repo.MarkInAppNotificationRead(tenanttest.TenantA, tenanttest.OwnerUUID, n.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("MarkInAppNotificationRead from another tenant: got %v, want ErrRecordNotFound", err)
+	}
+	if err := repo.MarkAllInAppNotificationsRead(tenanttest.TenantA, tenanttest.OwnerUUID); err != nil {
+		t.Fatalf("MarkAllInAppNotificationsRead from another tenant: %v", err)
+	}
+	if err := repo.DeleteInAppNotification(tenanttest.TenantA, tenanttest.OwnerUUID, n.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("DeleteInAppNotification from another tenant: got %v, want ErrRecordNotFound", err)
+	}
+
+	if count, err := repo.CountUnreadInAppNotifications(tenanttest.TenantB, tenanttest.OwnerUUID); err != nil || count != 1 {
+		t.Fatalf("owner's unread count = %d, %v, want 1", count, err)
+	}
+}
+
+func TestOutboxIsIsolatedByTenant(t *testing.T) {
+	repo, _ := newTestRepository(t)
+
+	msgs := []models.OutboxMessage{{
+		NotificationType: "user_created",
+		UserUUID:         tenanttest.OwnerUUID,
+		Channel:          "email",
+		Recipient:        "owner@example.com",
+		Data:             `{"name":"owner"}`,
+		Status:           models.OutboxStatusDead,
+		NextAttemptAt:    time.Now(),
+	}}
+	if err := repo.CreateOutboxMessages(tenanttest.TenantB, msgs); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	id := msgs[0].ID
+
+	if dead, err := repo.GetDeadLetters(tenanttest.TenantA, 0, 0); err != nil || len(dead) != 0 {
+		t.Fatalf("GetDeadLetters from another tenant: got %d rows, %v", len(dead), err)
+	}
+	if err := repo.ReplayDeadLetter(tenanttest.TenantA, id); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("ReplayDeadLetter from another tenant: got %v, want ErrRecordNotFound", err)
+	}
+	if err := repo.RedactOutboxMessage(tenanttest.TenantA, id); err != nil {
+		t.Fatalf("RedactOutboxMessage from another tenant: %v", err)
+	}
+
+	dead, err := repo.GetDeadLetters(tenanttest.TenantB, 0, 0)
+	if err != nil || len(dead) != 1 || dead[0].Redacted || dead[0].Data != `{"name":"owner"}` {
+		t.Fatalf("another tenant changed the outbox message: %+v, %v", dead, err)
+	}
+
+	if err := repo.CreateDeliveryAttempt(tenanttest.TenantB, &models.DeliveryAttempt{OutboxMessageID: id, NotificationType: "user_created", UserUUID: tenanttest.OwnerUUID, Channel: "email", Recipient: "owner@example.com", Status: models.DeliveryStatusDelivered}); err != nil {
+		t.Fatalf("create delivery attempt: %v", err)
+	}
+	if attempts, err := repo.GetDeliveryAttempts(tenanttest.TenantA, tenanttest.OwnerUUID, "", 0, 0); err != nil || len(attempts) != 0 {
+		t.Fatalf("GetDeliveryAttempts from another tenant: got %d rows, %v", len(attempts), err)
+	}
+}
+
+func TestScheduledNotificationsAreIsolatedByTenant(t *testing.T) {
+	repo, _ := newTestRepository(t)
+
+	n := &models.ScheduledNotification{
+		EventType: "reminder",
+		UserUUID:  tenanttest.OwnerUUID,
+		Payload:   "{}",
+		Key:       "renewal",
+		SendAt:    time.Now().Add(time.Hour),
+		Status:    models.ScheduledStatusPending,
+	}
+	if err := repo.CreateScheduledNotification(tenanttest.TenantB, n); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if cancelled, err := repo.CancelScheduledNotification(tenanttest.TenantA, n.ID); err != nil || cancelled != 0 {
+		t.Fatalf("CancelScheduledNotification from another tenant cancelled %d, %v", cancelled, err)
+	}
+	if cancelled, err := repo.CancelScheduledNotificationsByKey(tenanttest.TenantA, tenanttest.OwnerUUID, "renewal"); err != nil || cancelled != 0 {
+		t.Fatalf("CancelScheduledNotificationsByKey from another tenant cancelled %d, %v", cancelled, err)
+	}
+	if cancelled, err := repo.CancelScheduledNotification(tenanttest.TenantB, n.ID); err != nil || cancelled != 1 {
+		t.Fatalf("owner's cancel cancelled %d, %v, want 1", cancelled, err)
+	}
+}
@@ -0,0 +1,176 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/johnroshan2255/core-service/internal/notification/models"
+	"gorm.io/gorm"
+)
+
+// Recipient identifies who a notification is addressed to
+type Recipient struct {
+	UserUUID string
+	Email    string
+}
+
+// delivery is a single channel/address pair a notification is sent to
+type delivery struct {
+	channel Channel
+	address string
+}
+
+// resolveDeliveries works out which channels a notification goes to based on the
+// user's stored channel preferences and opt-outs. When the user is in quiet hours
+// the returned time is when delivery may start; otherwise it is the zero time.
+func (s *NotificationService) resolveDeliveries(ctx context.Context, notificationType string, recipient Recipient) ([]delivery, time.Time, error) {
+	mandatory := IsMandatory(notificationType)
+
+	var pref *models.NotificationPreference
+	var channelPrefs []models.ChannelPreference
+	if s.repo != nil && recipient.UserUUID != "" {
+		p, err := s.repo.GetPreference(ctx, recipient.UserUUID)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return nil, time.Time{}, fmt.Errorf("failed to get notification preferences: %w", err)
+		}
+		pref = p
+
+		channelPrefs, err = s.repo.GetChannelPreferences(ctx, recipient.UserUUID)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("failed to get channel preferences: %w", err)
+		}
+	}
+
+	var notBefore time.Time
+	if now := time.Now(); !mandatory && pref != nil && inQuietHours(pref, now) {
+		notBefore = quietHoursEnd(pref, now)
+		log.Printf("NotificationService: Deferring %s notification for user %s until %s (quiet hours)", notificationType, recipient.UserUUID, notBefore.Format(time.RFC3339))
+	}
+
+	enabled := make(map[Channel]bool)
+	for _, channel := range defaultChannels {
+		enabled[channel] = true
+	}
+	prefType := preferenceType(notificationType)
+	for _, cp := range channelPrefs {
+		if cp.NotificationType != prefType {
+			continue
+		}
+		channel, ok := ParseChannel(cp.Channel)
+		if !ok {
+			continue
+		}
+		if cp.Enabled {
+			enabled[channel] = true
+		} else if !mandatory {
+			delete(enabled, channel)
+		}
+	}
+
+	if emailOnlyTypes[notificationType] {
+		enabled = map[Channel]bool{ChannelEmail: true}
+	}
+
+	var deliveries []delivery
+	for _, channel := range []Channel{ChannelEmail, ChannelSMS, ChannelWebhook, ChannelInApp} {
+		if !enabled[channel] {
+			continue
+		}
+		if _, ok := s.providers[channel]; !ok {
+			continue
+		}
+		address := channelAddress(channel, recipient, pref)
+		if address == "" {
+			log.Printf("NotificationService: No %s address for user %s, skipping channel", channel, recipient.UserUUID)
+			continue
+		}
+		if channel == ChannelEmail && s.isSuppressed(ctx, address) {
+			log.Printf("NotificationService: %s is suppressed, skipping email for user %s", address, recipient.UserUUID)
+			continue
+		}
+		deliveries = append(deliveries, delivery{channel: channel, address: address})
+	}
+
+	return deliveries, notBefore, nil
+}
+
+func channelAddress(channel Channel, recipient Recipient, pref *models.NotificationPreference) string {
+	switch channel {
+	case ChannelEmail:
+		return recipient.Email
+	case ChannelInApp:
+		return recipient.UserUUID
+	case ChannelSMS:
+		if pref != nil {
+			return pref.PhoneNumber
+		}
+	case ChannelWebhook:
+		if pref != nil {
+			return pref.WebhookURL
+		}
+	}
+	return ""
+}
+
+// inQuietHours reports whether now falls inside the user's quiet hours window.
+// Windows that wrap past midnight (e.g. 22:00-07:00) are supported.
+func inQuietHours(pref *models.NotificationPreference, now time.Time) bool {
+	if pref.QuietHoursStart == "" || pref.QuietHoursEnd == "" {
+		return false
+	}
+
+	start, err := parseClock(pref.QuietHoursStart)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(pref.QuietHoursEnd)
+	if err != nil {
+		return false
+	}
+
+	local := now.In(preferenceLocation(pref))
+	minutes := local.Hour()*60 + local.Minute()
+
+	if start == end {
+		return false
+	}
+	if start < end {
+		return minutes >= start && minutes < end
+	}
+	return minutes >= start || minutes < end
+}
+
+// quietHoursEnd returns the next time the user's quiet hours window closes after now
+func quietHoursEnd(pref *models.NotificationPreference, now time.Time) time.Time {
+	end, err := parseClock(pref.QuietHoursEnd)
+	if err != nil {
+		return now
+	}
+
+	local := now.In(preferenceLocation(pref))
+	next := time.Date(local.Year(), local.Month(), local.Day(), end/60, end%60, 0, 0, local.Location())
+	if !next.After(local) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+func preferenceLocation(pref *models.NotificationPreference) *time.Location {
+	if pref.Timezone != "" {
+		if loc, err := time.LoadLocation(pref.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// parseClock converts an "HH:MM" string into minutes since midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,235 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/johnroshan2255/core-service/internal/notification/models"
+	"github.com/johnroshan2255/core-service/internal/tenant"
+	"gorm.io/gorm"
+)
+
+const (
+	scheduledBatchSize    = 50
+	scheduledPollInterval = 15 * time.Second
+	scheduledLease        = 5 * time.Minute
+)
+
+// ErrScheduledNotificationNotFound is returned when cancelling a scheduled notification
+// that doesn't exist or is no longer pending
+var ErrScheduledNotificationNotFound = errors.New("scheduled notification not found or already sent")
+
+// Schedule stores a generic notification to be sent at sendAt. The notification is
+// validated now so bad payloads are rejected up front rather than at send time.
+// key is optional and lets the caller cancel without keeping the returned ID.
+func (s *NotificationService) Schedule(ctx context.Context, n Notification, sendAt time.Time, key string) (*models.ScheduledNotification, error) {
+	if n.EventType == "" {
+		return nil, fmt.Errorf("%w: event type is required", ErrInvalidNotification)
+	}
+	if n.Recipient.UserUUID == "" {
+		return nil, fmt.Errorf("%w: user UUID is required", ErrInvalidNotification)
+	}
+	if sendAt.IsZero() {
+		return nil, fmt.Errorf("%w: send time is required", ErrInvalidNotification)
+	}
+	if s.repo == nil {
+		return nil, fmt.Errorf("scheduled notifications are not available")
+	}
+	if n.Payload == nil {
+		n.Payload = make(map[string]interface{})
+	}
+
+	if err := s.schemas.Validate(n.EventType, n.Payload); err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(n.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode payload: %w", err)
+	}
+	ctx, err = s.userContext(ctx, n.Recipient.UserUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled := &models.ScheduledNotification{
+		EventType: n.EventType,
+		UserUUID:  n.Recipient.UserUUID,
+		Email:     n.Recipient.Email,
+		Locale:    n.Locale,
+		Payload:   string(payload),
+		Key:       key,
+		SendAt:    sendAt.UTC(),
+		Status:    models.ScheduledStatusPending,
+	}
+	if err := s.repo.CreateScheduledNotification(ctx, scheduled); err != nil {
+		return nil, fmt.Errorf("failed to schedule notification: %w", err)
+	}
+
+	log.Printf("NotificationService: Scheduled %s notification %d for user %s at %s", n.EventType, scheduled.ID, n.Recipient.UserUUID, scheduled.SendAt.Format(time.RFC3339))
+	return scheduled, nil
+}
+
+// CancelScheduled cancels a scheduled notification that hasn't been sent yet
+func (s *NotificationService) CancelScheduled(ctx context.Context, id uint) error {
+	if s.repo == nil {
+		return fmt.Errorf("scheduled notifications are not available")
+	}
+
+	cancelled, err := s.repo.CancelScheduledNotification(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to cancel scheduled notification: %w", err)
+	}
+	if cancelled == 0 {
+		return ErrScheduledNotificationNotFound
+	}
+
+	log.Printf("NotificationService: Cancelled scheduled notification %d", id)
+	return nil
+}
+
+// CancelScheduledByKey cancels every pending notification scheduled for the user
+// with key and returns how many were cancelled
+func (s *NotificationService) CancelScheduledByKey(ctx context.Context, userUUID, key string) (int64, error) {
+	if userUUID == "" {
+		return 0, fmt.Errorf("user UUID is required")
+	}
+	if key == "" {
+		return 0, fmt.Errorf("key is required")
+	}
+	if s.repo == nil {
+		return 0, fmt.Errorf("scheduled notifications are not available")
+	}
+
+	ctx, err := s.userContext(ctx, userUUID)
+	if err != nil {
+		return 0, err
+	}
+
+	cancelled, err := s.repo.CancelScheduledNotificationsByKey(ctx, userUUID, key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to cancel scheduled notifications: %w", err)
+	}
+
+	log.Printf("NotificationService: Cancelled %d scheduled notification(s) with key %s for user %s", cancelled, key, userUUID)
+	return cancelled, nil
+}
+
+// sendScheduled hands a due scheduled notification to Send
+func (s *NotificationService) sendScheduled(ctx context.Context, scheduled *models.ScheduledNotification) error {
+	payload := make(map[string]interface{})
+	if scheduled.Payload != "" {
+		if err := json.Unmarshal([]byte(scheduled.Payload), &payload); err != nil {
+			return fmt.Errorf("failed to decode payload: %w", err)
+		}
+	}
+
+	return s.Send(ctx, Notification{
+		EventType: scheduled.EventType,
+		Recipient: Recipient{
+			UserUUID: scheduled.UserUUID,
+			Email:    scheduled.Email,
+		},
+		Locale:  scheduled.Locale,
+		Payload: payload,
+	})
+}
+
+// ScheduledDispatcher sends scheduled notifications once they are due
+type ScheduledDispatcher struct {
+	service *NotificationService
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewScheduledDispatcher creates a dispatcher for scheduled notifications
+func NewScheduledDispatcher(service *NotificationService) *ScheduledDispatcher {
+	return &ScheduledDispatcher{
+		service: service,
+		stop:    make(chan struct{}),
+	}
+}
+
+// Start launches the dispatcher
+func (d *ScheduledDispatcher) Start(ctx context.Context) {
+	if d.service.repo == nil {
+		log.Printf("ScheduledDispatcher: Notification repository not configured, scheduled notifications disabled")
+		return
+	}
+
+	d.wg.Add(1)
+	go d.run(ctx)
+	log.Printf("ScheduledDispatcher: Started")
+}
+
+// Stop signals the dispatcher to exit and waits for the current batch to finish
+func (d *ScheduledDispatcher) Stop() {
+	close(d.stop)
+	d.wg.Wait()
+	log.Printf("ScheduledDispatcher: Stopped")
+}
+
+func (d *ScheduledDispatcher) run(ctx context.Context) {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(scheduledPollInterval)
+	defer ticker.Stop()
+
+	for {
+		for d.processBatch(ctx) == scheduledBatchSize {
+			select {
+			case <-d.stop:
+				return
+			default:
+			}
+		}
+
+		select {
+		case <-d.stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processBatch claims and sends one batch of due notifications and returns how many were claimed
+func (d *ScheduledDispatcher) processBatch(ctx context.Context) int {
+	due, err := d.service.repo.ClaimDueScheduledNotifications(tenant.Unscoped(ctx), scheduledBatchSize, scheduledLease)
+	if err != nil {
+		log.Printf("ScheduledDispatcher: Failed to claim scheduled notifications: %v", err)
+		return 0
+	}
+
+	for i := range due {
+		scheduled := &due[i]
+		ctx := tenant.WithTenant(ctx, scheduled.TenantID)
+
+		attempts := scheduled.Attempts + 1
+		status := models.ScheduledStatusSent
+		lastError := ""
+		if err := d.service.sendScheduled(ctx, scheduled); err != nil {
+			lastError = err.Error()
+			status = models.ScheduledStatusPending
+			if attempts >= d.service.maxAttempts {
+				status = models.ScheduledStatusFailed
+				log.Printf("ScheduledDispatcher: Giving up on scheduled notification %d after %d attempts: %v", scheduled.ID, attempts, err)
+			} else {
+				log.Printf("ScheduledDispatcher: Failed to send scheduled notification %d (attempt %d), retrying in %s: %v", scheduled.ID, attempts, scheduledLease, err)
+			}
+		}
+
+		if err := d.service.repo.MarkScheduledNotification(ctx, scheduled.ID, status, attempts, lastError); err == gorm.ErrRecordNotFound {
+			log.Printf("ScheduledDispatcher: Scheduled notification %d was cancelled while being sent, keeping it cancelled", scheduled.ID)
+		} else if err != nil {
+			log.Printf("ScheduledDispatcher: Failed to update scheduled notification %d: %v", scheduled.ID, err)
+		}
+	}
+	return len(due)
+}
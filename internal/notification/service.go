@@ -2,20 +2,433 @@ package notification
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"time"
+
+	"github.com/johnroshan2255/core-service/internal/notification/models"
+	"github.com/johnroshan2255/core-service/internal/notification/repos"
+	"github.com/johnroshan2255/core-service/internal/tenant"
+	"gorm.io/gorm"
+)
+
+// errSuppressed marks a delivery skipped because the address is on the suppression list
+var errSuppressed = errors.New("recipient is on the suppression list")
+
+// ErrDeadLetterNotFound is returned when replaying a message that isn't dead-lettered,
+// or whose payload was redacted because it carried a secret link
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+const (
+	defaultMaxAttempts        = 5
+	defaultMaxAttachmentBytes = 3 << 20
 )
 
 // NotificationService handles notification business logic
 type NotificationService struct {
-	provider Provider
+	providers   map[Channel]Provider
+	repo        repos.Repository
+	broker      *Broker
+	schemas     *SchemaRegistry
+	maxAttempts int
+
+	maxAttachmentBytes int64
+
+	unsubscribeSecret  []byte
+	unsubscribeBaseURL string
+
+	tenants TenantResolver
 }
 
-// NewNotificationService creates a new notification service
-func NewNotificationService(provider Provider) *NotificationService {
+// TenantResolver finds the tenant a user belongs to. Callers such as other services
+// over gRPC and the schedulers don't act for a tenant, so the recipient's tenant is
+// looked up before their preferences, inbox and outbox rows are touched.
+type TenantResolver interface {
+	UserTenant(ctx context.Context, userUUID string) (string, error)
+}
+
+// NewNotificationService creates a new notification service.
+// repo may be nil, in which case every notification goes to the default channels
+// and is sent synchronously instead of through the outbox.
+func NewNotificationService(providers map[Channel]Provider, repo repos.Repository) *NotificationService {
 	return &NotificationService{
-		provider: provider,
+		providers:   providers,
+		repo:        repo,
+		broker:      NewBroker(),
+		schemas:     NewSchemaRegistry(),
+		maxAttempts: defaultMaxAttempts,
+
+		maxAttachmentBytes: defaultMaxAttachmentBytes,
+	}
+}
+
+// SetTenantResolver sets how recipients' tenants are found for callers without one.
+// Without a resolver those callers work in the empty tenant.
+func (s *NotificationService) SetTenantResolver(resolver TenantResolver) {
+	s.tenants = resolver
+}
+
+// userContext scopes ctx to the user's tenant unless the caller already carries one
+func (s *NotificationService) userContext(ctx context.Context, userUUID string) (context.Context, error) {
+	if _, ok := tenant.FromContext(ctx); ok {
+		return ctx, nil
+	}
+	tenantID := ""
+	if s.tenants != nil {
+		var err error
+		if tenantID, err = s.tenants.UserTenant(tenant.Unscoped(ctx), userUUID); err != nil {
+			return nil, fmt.Errorf("failed to resolve tenant for user %s: %w", userUUID, err)
+		}
+	}
+	return tenant.WithTenant(ctx, tenantID), nil
+}
+
+// Schemas returns the registry of event types accepted by Send
+func (s *NotificationService) Schemas() *SchemaRegistry {
+	return s.schemas
+}
+
+// Subscribe streams real-time events for a user, or for every user when userUUID is empty
+func (s *NotificationService) Subscribe(userUUID string) (<-chan Event, func()) {
+	return s.broker.Subscribe(userUUID)
+}
+
+// RelayEvents shares real-time events with the service's other instances through relay
+// until ctx is done
+func (s *NotificationService) RelayEvents(ctx context.Context, relay EventRelay) {
+	s.broker.UseRelay(ctx, relay)
+}
+
+// PublishDocumentStatus pushes a document status change to real-time subscribers
+func (s *NotificationService) PublishDocumentStatus(userUUID string, documentID uint, documentName, oldStatus, newStatus string) {
+	s.broker.Publish(Event{
+		Type:     EventDocumentStatusChanged,
+		UserUUID: userUUID,
+		Payload: map[string]interface{}{
+			"document_id":   documentID,
+			"document_name": documentName,
+			"old_status":    oldStatus,
+			"new_status":    newStatus,
+		},
+	})
+}
+
+// SetMaxAttempts sets how many delivery attempts an outbox message gets before it is dead-lettered
+func (s *NotificationService) SetMaxAttempts(maxAttempts int) {
+	if maxAttempts > 0 {
+		s.maxAttempts = maxAttempts
+	}
+}
+
+// ProviderHealth reports the circuit breaker state of every provider, grouped by channel
+func (s *NotificationService) ProviderHealth() []ProviderHealth {
+	var health []ProviderHealth
+	for _, channel := range []Channel{ChannelEmail, ChannelSMS, ChannelWebhook, ChannelInApp} {
+		if chain, ok := s.providers[channel].(*FailoverProvider); ok {
+			health = append(health, chain.Health()...)
+		}
+	}
+	return health
+}
+
+// SetMaxAttachmentBytes sets the total attachment size allowed on a single notification
+func (s *NotificationService) SetMaxAttachmentBytes(maxBytes int64) {
+	if maxBytes > 0 {
+		s.maxAttachmentBytes = maxBytes
+	}
+}
+
+// limitAttachments keeps attachments until the size limit is reached. Document files
+// claim the budget before calendar invites so an .ics never crowds out the document it
+// belongs to. Attachments that don't fit are dropped so the notification itself still
+// goes out; the rest keep their order.
+func (s *NotificationService) limitAttachments(attachments []Attachment) []Attachment {
+	keep := make([]bool, len(attachments))
+	var total int64
+	for _, calendarPass := range []bool{false, true} {
+		for i, attachment := range attachments {
+			if isCalendarAttachment(attachment) != calendarPass {
+				continue
+			}
+			size := int64(len(attachment.Content))
+			if total+size > s.maxAttachmentBytes {
+				log.Printf("NotificationService: Dropping attachment %s (%d bytes), size limit of %d bytes reached", attachment.Filename, size, s.maxAttachmentBytes)
+				continue
+			}
+			total += size
+			keep[i] = true
+		}
+	}
+
+	var kept []Attachment
+	for i, attachment := range attachments {
+		if keep[i] {
+			kept = append(kept, attachment)
+		}
+	}
+	return kept
+}
+
+func isCalendarAttachment(attachment Attachment) bool {
+	return strings.HasPrefix(strings.ToLower(attachment.ContentType), "text/calendar")
+}
+
+// channelAttachments returns the attachments to send through channel. Only email can
+// carry files, so other channels never receive or store them.
+func channelAttachments(channel Channel, attachments []Attachment) []Attachment {
+	if channel != ChannelEmail {
+		return nil
+	}
+	return attachments
+}
+
+// dispatch fans a notification out to every channel resolved for the recipient.
+// With a repository configured the deliveries are written to the outbox and sent by
+// OutboxWorker; without one they are sent directly.
+func (s *NotificationService) dispatch(ctx context.Context, notificationType string, recipient Recipient, subject string, data map[string]interface{}, attachments []Attachment) error {
+	ctx, err := s.userContext(ctx, recipient.UserUUID)
+	if err != nil {
+		return err
+	}
+
+	deliveries, notBefore, err := s.resolveDeliveries(ctx, notificationType, recipient)
+	if err != nil {
+		return err
+	}
+
+	if len(deliveries) == 0 {
+		log.Printf("NotificationService: No deliverable channels for %s notification to user %s", notificationType, recipient.UserUUID)
+		return nil
+	}
+
+	data = s.withUnsubscribeURL(notificationType, recipient.UserUUID, data)
+
+	if s.repo != nil {
+		return s.enqueue(ctx, notificationType, recipient, deliveries, notBefore, subject, data, attachments)
+	}
+
+	var errs []error
+	for _, d := range deliveries {
+		if err := s.providers[d.channel].SendNotification(ctx, d.address, subject, data, channelAttachments(d.channel, attachments)); err != nil {
+			log.Printf("NotificationService: Failed to send %s notification via %s: %v", notificationType, d.channel, err)
+			errs = append(errs, fmt.Errorf("%s: %w", d.channel, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// enqueue stores one outbox message per delivery
+func (s *NotificationService) enqueue(ctx context.Context, notificationType string, recipient Recipient, deliveries []delivery, notBefore time.Time, subject string, data map[string]interface{}, attachments []Attachment) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification data: %w", err)
+	}
+
+	var emailAttachments string
+	if attachments := channelAttachments(ChannelEmail, attachments); len(attachments) > 0 {
+		encoded, err := json.Marshal(attachments)
+		if err != nil {
+			return fmt.Errorf("failed to encode attachments: %w", err)
+		}
+		emailAttachments = string(encoded)
+	}
+
+	if notBefore.IsZero() {
+		notBefore = time.Now()
+	}
+
+	msgs := make([]models.OutboxMessage, 0, len(deliveries))
+	for _, d := range deliveries {
+		var encodedAttachments string
+		if d.channel == ChannelEmail {
+			encodedAttachments = emailAttachments
+		}
+		msgs = append(msgs, models.OutboxMessage{
+			NotificationType: notificationType,
+			UserUUID:         recipient.UserUUID,
+			Channel:          string(d.channel),
+			Recipient:        d.address,
+			Subject:          subject,
+			Data:             string(payload),
+			Attachments:      encodedAttachments,
+			Status:           models.OutboxStatusPending,
+			MaxAttempts:      s.maxAttempts,
+			NextAttemptAt:    notBefore,
+		})
+	}
+
+	if err := s.repo.CreateOutboxMessages(ctx, msgs); err != nil {
+		return fmt.Errorf("failed to enqueue notification: %w", err)
+	}
+
+	log.Printf("NotificationService: Queued %s notification for user %s on %d channel(s)", notificationType, recipient.UserUUID, len(msgs))
+	return nil
+}
+
+// deliver hands a stored outbox message to its channel provider and records the attempt
+func (s *NotificationService) deliver(ctx context.Context, msg *models.OutboxMessage) error {
+	// The address may have been suppressed after the message was queued
+	if Channel(msg.Channel) == ChannelEmail && s.isSuppressed(ctx, msg.Recipient) {
+		log.Printf("NotificationService: Not sending message %d, %s is suppressed", msg.ID, msg.Recipient)
+		s.recordAttempt(ctx, msg, errSuppressed)
+		return nil
+	}
+
+	err := s.send(ctx, msg)
+	s.recordAttempt(ctx, msg, err)
+	return err
+}
+
+func (s *NotificationService) send(ctx context.Context, msg *models.OutboxMessage) error {
+	provider, ok := s.providers[Channel(msg.Channel)]
+	if !ok {
+		return fmt.Errorf("no provider configured for channel %s", msg.Channel)
+	}
+
+	data := make(map[string]interface{})
+	if msg.Data != "" {
+		if err := json.Unmarshal([]byte(msg.Data), &data); err != nil {
+			return fmt.Errorf("failed to decode notification data: %w", err)
+		}
+	}
+
+	var attachments []Attachment
+	if msg.Attachments != "" {
+		if err := json.Unmarshal([]byte(msg.Attachments), &attachments); err != nil {
+			return fmt.Errorf("failed to decode attachments: %w", err)
+		}
+	}
+
+	return provider.SendNotification(ctx, msg.Recipient, msg.Subject, data, channelAttachments(Channel(msg.Channel), attachments))
+}
+
+// recordAttempt stores the outcome of a delivery so support can trace what a user received
+func (s *NotificationService) recordAttempt(ctx context.Context, msg *models.OutboxMessage, sendErr error) {
+	attempt := &models.DeliveryAttempt{
+		OutboxMessageID:  msg.ID,
+		NotificationType: msg.NotificationType,
+		UserUUID:         msg.UserUUID,
+		Channel:          msg.Channel,
+		Recipient:        msg.Recipient,
+		Subject:          msg.Subject,
+		Attempt:          msg.Attempts + 1,
+		Status:           models.DeliveryStatusDelivered,
+		ProviderResponse: "accepted",
+		QueuedAt:         msg.CreatedAt,
+		AttemptedAt:      time.Now(),
+	}
+	if sendErr == errSuppressed {
+		attempt.Status = models.DeliveryStatusSuppressed
+		attempt.ProviderResponse = sendErr.Error()
+	} else if sendErr != nil {
+		attempt.Status = models.DeliveryStatusFailed
+		attempt.ProviderResponse = sendErr.Error()
+	}
+
+	if err := s.repo.CreateDeliveryAttempt(ctx, attempt); err != nil {
+		log.Printf("NotificationService: Failed to record delivery attempt for message %d: %v", msg.ID, err)
+	}
+}
+
+// GetHistory returns the delivery attempts made for a user, newest first
+func (s *NotificationService) GetHistory(ctx context.Context, userUUID, notificationType string, limit, offset int) ([]models.DeliveryAttempt, error) {
+	if userUUID == "" {
+		return nil, fmt.Errorf("user UUID is required")
+	}
+	if s.repo == nil {
+		return nil, fmt.Errorf("notification history is not available")
+	}
+
+	attempts, err := s.repo.GetDeliveryAttempts(ctx, userUUID, notificationType, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification history: %w", err)
 	}
+	return attempts, nil
+}
+
+// ListDeadLetters returns outbox messages that exhausted their delivery attempts
+func (s *NotificationService) ListDeadLetters(ctx context.Context, limit, offset int) ([]models.OutboxMessage, error) {
+	if s.repo == nil {
+		return nil, fmt.Errorf("notification outbox is not available")
+	}
+
+	msgs, err := s.repo.GetDeadLetters(ctx, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letters: %w", err)
+	}
+	return msgs, nil
+}
+
+// ReplayDeadLetter puts a dead-lettered message back in the outbox with a fresh attempt budget
+func (s *NotificationService) ReplayDeadLetter(ctx context.Context, id uint) error {
+	if s.repo == nil {
+		return fmt.Errorf("notification outbox is not available")
+	}
+
+	if err := s.repo.ReplayDeadLetter(ctx, id); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrDeadLetterNotFound
+		}
+		return fmt.Errorf("failed to replay dead letter: %w", err)
+	}
+
+	log.Printf("NotificationService: Replaying dead letter %d", id)
+	return nil
+}
+
+// Notification is a generic, schema-validated notification request
+type Notification struct {
+	EventType string
+	Recipient Recipient
+	Locale    string
+	Payload   map[string]interface{}
+}
+
+// Send validates a generic notification against its event schema, renders it in the
+// recipient's locale and dispatches it. New event types only need a registered schema.
+func (s *NotificationService) Send(ctx context.Context, n Notification) error {
+	if n.EventType == "" {
+		return fmt.Errorf("event type is required")
+	}
+	if n.Recipient.UserUUID == "" {
+		return fmt.Errorf("user UUID is required")
+	}
+	if n.Payload == nil {
+		n.Payload = make(map[string]interface{})
+	}
+
+	if err := s.schemas.Validate(n.EventType, n.Payload); err != nil {
+		return err
+	}
+
+	subject, body, err := s.schemas.Render(n.EventType, n.Locale, n.Payload)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("NotificationService: Processing %s event - UUID: %s, Email: %s, Locale: %s", n.EventType, n.Recipient.UserUUID, n.Recipient.Email, n.Locale)
+
+	notificationData := make(map[string]interface{}, len(n.Payload)+5)
+	for key, value := range n.Payload {
+		notificationData[key] = value
+	}
+	notificationData["type"] = n.EventType
+	notificationData["user_uuid"] = n.Recipient.UserUUID
+	notificationData["email"] = n.Recipient.Email
+	notificationData["locale"] = n.Locale
+	notificationData["message"] = body
+
+	if err := s.dispatch(ctx, n.EventType, n.Recipient, subject, notificationData, nil); err != nil {
+		log.Printf("NotificationService: Failed to send %s event: %v", n.EventType, err)
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+
+	log.Printf("NotificationService: Successfully sent %s event to user %s", n.EventType, n.Recipient.UserUUID)
+	return nil
 }
 
 // NotifyUserCreated sends a notification when a user is created
@@ -30,13 +443,14 @@ func (s *NotificationService) NotifyUserCreated(ctx context.Context, userUUID, e
 	log.Printf("NotificationService: Processing user created notification - UUID: %s, Email: %s, Username: %s", userUUID, email, username)
 
 	notificationData := map[string]interface{}{
-		"type":      "user_created",
+		"type":      TypeUserCreated,
 		"user_uuid": userUUID,
 		"email":     email,
 		"username":  username,
 	}
 
-	if err := s.provider.SendNotification(ctx, email, "Welcome to our platform!", notificationData); err != nil {
+	recipient := Recipient{UserUUID: userUUID, Email: email}
+	if err := s.dispatch(ctx, TypeUserCreated, recipient, "Welcome to our platform!", notificationData, nil); err != nil {
 		log.Printf("NotificationService: Failed to send user created notification: %v", err)
 		return fmt.Errorf("failed to send notification: %w", err)
 	}
@@ -45,7 +459,9 @@ func (s *NotificationService) NotifyUserCreated(ctx context.Context, userUUID, e
 	return nil
 }
 
-func (s *NotificationService) NotifyDocumentExpiry(ctx context.Context, userUUID, email, documentName, documentCategory, expiryDate string, daysUntilExpiry int32, isExpired bool, message string) error {
+// NotifyDocumentExpiry sends a notification about a single expiring or expired document,
+// optionally with the document itself or other files attached
+func (s *NotificationService) NotifyDocumentExpiry(ctx context.Context, userUUID, email, documentName, documentCategory, expiryDate string, daysUntilExpiry int32, isExpired bool, message string, attachments []Attachment) error {
 	if userUUID == "" {
 		return fmt.Errorf("user UUID is required")
 	}
@@ -60,7 +476,7 @@ func (s *NotificationService) NotifyDocumentExpiry(ctx context.Context, userUUID
 		userUUID, email, documentName, documentCategory, isExpired, daysUntilExpiry)
 
 	notificationData := map[string]interface{}{
-		"type":              "document_expiry",
+		"type":              TypeDocumentExpiry,
 		"user_uuid":         userUUID,
 		"email":             email,
 		"document_name":     documentName,
@@ -78,7 +494,8 @@ func (s *NotificationService) NotifyDocumentExpiry(ctx context.Context, userUUID
 		subject = fmt.Sprintf("Document Expiring Soon: %s", documentName)
 	}
 
-	if err := s.provider.SendNotification(ctx, email, subject, notificationData); err != nil {
+	recipient := Recipient{UserUUID: userUUID, Email: email}
+	if err := s.dispatch(ctx, TypeDocumentExpiry, recipient, subject, notificationData, s.limitAttachments(attachments)); err != nil {
 		log.Printf("NotificationService: Failed to send document expiry notification: %v", err)
 		return fmt.Errorf("failed to send notification: %w", err)
 	}
@@ -86,3 +503,277 @@ func (s *NotificationService) NotifyDocumentExpiry(ctx context.Context, userUUID
 	log.Printf("NotificationService: Successfully sent document expiry notification to %s", email)
 	return nil
 }
+
+// NotifyDocumentDigest sends a single notification covering all of a user's expiring and
+// expired documents, or one notification per document if the user prefers individual delivery
+func (s *NotificationService) NotifyDocumentDigest(ctx context.Context, userUUID, email string, items []DigestItem) error {
+	if userUUID == "" {
+		return fmt.Errorf("user UUID is required")
+	}
+	if email == "" {
+		return fmt.Errorf("email is required")
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("at least one document is required")
+	}
+
+	if s.expiryDelivery(ctx, userUUID) == models.ExpiryDeliveryIndividual {
+		log.Printf("NotificationService: User %s prefers individual expiry notifications, sending %d", userUUID, len(items))
+		var errs []error
+		for _, item := range items {
+			message := fmt.Sprintf("Your document '%s' (Category: %s) will expire in %d days on %s. Please renew it soon.",
+				item.DocumentName, item.DocumentCategory, item.DaysUntilExpiry, formatDate(item.ExpiryDate))
+			if item.IsExpired {
+				message = fmt.Sprintf("Your document '%s' (Category: %s) has expired on %s. Please renew it immediately.",
+					item.DocumentName, item.DocumentCategory, formatDate(item.ExpiryDate))
+			}
+			if err := s.NotifyDocumentExpiry(ctx, userUUID, email, item.DocumentName, item.DocumentCategory, item.ExpiryDate, item.DaysUntilExpiry, item.IsExpired, message, item.Attachments); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	log.Printf("NotificationService: Processing document digest - UUID: %s, Email: %s, Documents: %d", userUUID, email, len(items))
+
+	subject, body, err := renderDigest(items)
+	if err != nil {
+		return err
+	}
+
+	var attachments []Attachment
+	documents := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		attachments = append(attachments, item.Attachments...)
+		documents = append(documents, map[string]interface{}{
+			"document_name":     item.DocumentName,
+			"document_category": item.DocumentCategory,
+			"expiry_date":       item.ExpiryDate,
+			"days_until_expiry": item.DaysUntilExpiry,
+			"is_expired":        item.IsExpired,
+		})
+	}
+
+	notificationData := map[string]interface{}{
+		"type":      TypeDocumentDigest,
+		"user_uuid": userUUID,
+		"email":     email,
+		"documents": documents,
+		"message":   body,
+	}
+
+	recipient := Recipient{UserUUID: userUUID, Email: email}
+	if err := s.dispatch(ctx, TypeDocumentDigest, recipient, subject, notificationData, s.limitAttachments(attachments)); err != nil {
+		log.Printf("NotificationService: Failed to send document digest: %v", err)
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+
+	log.Printf("NotificationService: Successfully sent document digest to %s", email)
+	return nil
+}
+
+// expiryDelivery returns the user's preferred expiry delivery mode, defaulting to digest
+func (s *NotificationService) expiryDelivery(ctx context.Context, userUUID string) string {
+	if s.repo == nil {
+		return models.ExpiryDeliveryDigest
+	}
+	ctx, err := s.userContext(ctx, userUUID)
+	if err != nil {
+		return models.ExpiryDeliveryDigest
+	}
+
+	pref, err := s.repo.GetPreference(ctx, userUUID)
+	if err != nil || pref.ExpiryDelivery == "" {
+		return models.ExpiryDeliveryDigest
+	}
+	return pref.ExpiryDelivery
+}
+
+// NotifySecurityNotice sends a mandatory security notice that ignores opt-outs and quiet hours
+func (s *NotificationService) NotifySecurityNotice(ctx context.Context, userUUID, email, subject, message string) error {
+	if userUUID == "" {
+		return fmt.Errorf("user UUID is required")
+	}
+	if email == "" {
+		return fmt.Errorf("email is required")
+	}
+
+	log.Printf("NotificationService: Processing security notice - UUID: %s, Email: %s, Subject: %s", userUUID, email, subject)
+
+	notificationData := map[string]interface{}{
+		"type":      TypeSecurityNotice,
+		"user_uuid": userUUID,
+		"email":     email,
+		"message":   message,
+	}
+
+	recipient := Recipient{UserUUID: userUUID, Email: email}
+	if err := s.dispatch(ctx, TypeSecurityNotice, recipient, subject, notificationData, nil); err != nil {
+		log.Printf("NotificationService: Failed to send security notice: %v", err)
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+
+	log.Printf("NotificationService: Successfully sent security notice to %s", email)
+	return nil
+}
+
+// NotifyPasswordReset emails a password reset link. It is only ever sent by email.
+func (s *NotificationService) NotifyPasswordReset(ctx context.Context, userUUID, email, link string, expiresIn time.Duration) error {
+	message := fmt.Sprintf("We received a request to reset your password. Use the link below within %s to choose a new one:\n\n%s\n\n"+
+		"If you didn't ask for this, you can ignore this email; your password won't change.", formatDuration(expiresIn), link)
+	return s.notifyAccountLink(ctx, TypePasswordReset, userUUID, email, "Reset your password", message, link)
+}
+
+// NotifyEmailVerification emails a link confirming the user owns the address
+func (s *NotificationService) NotifyEmailVerification(ctx context.Context, userUUID, email, link string, expiresIn time.Duration) error {
+	message := fmt.Sprintf("Please confirm your email address by opening the link below within %s:\n\n%s", formatDuration(expiresIn), link)
+	return s.notifyAccountLink(ctx, TypeEmailVerify, userUUID, email, "Verify your email address", message, link)
+}
+
+func (s *NotificationService) notifyAccountLink(ctx context.Context, notificationType, userUUID, email, subject, message, link string) error {
+	if userUUID == "" {
+		return fmt.Errorf("user UUID is required")
+	}
+	if email == "" {
+		return fmt.Errorf("email is required")
+	}
+	if link == "" {
+		return fmt.Errorf("link is required")
+	}
+
+	// The link is a credential, so it is deliberately left out of the log line
+	log.Printf("NotificationService: Processing %s notification - UUID: %s, Email: %s", notificationType, userUUID, email)
+
+	notificationData := map[string]interface{}{
+		"type":      notificationType,
+		"user_uuid": userUUID,
+		"email":     email,
+		"message":   message,
+		"link":      link,
+	}
+
+	recipient := Recipient{UserUUID: userUUID, Email: email}
+	if err := s.dispatch(ctx, notificationType, recipient, subject, notificationData, nil); err != nil {
+		log.Printf("NotificationService: Failed to send %s notification: %v", notificationType, err)
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+
+	log.Printf("NotificationService: Successfully sent %s notification to %s", notificationType, email)
+	return nil
+}
+
+// formatDuration renders a link lifetime for email copy, e.g. "1 hour" or "2 days"
+func formatDuration(d time.Duration) string {
+	unit := func(n int, name string) string {
+		if n == 1 {
+			return "1 " + name
+		}
+		return fmt.Sprintf("%d %ss", n, name)
+	}
+	switch {
+	case d >= 48*time.Hour:
+		return unit(int(d/(24*time.Hour)), "day")
+	case d >= 2*time.Hour:
+		return unit(int(d/time.Hour), "hour")
+	case d >= time.Hour:
+		return "1 hour"
+	default:
+		return unit(int(d/time.Minute), "minute")
+	}
+}
+
+// GetPreferences returns the user's delivery settings and channel opt-ins/opt-outs
+func (s *NotificationService) GetPreferences(ctx context.Context, userUUID string) (*models.NotificationPreference, []models.ChannelPreference, error) {
+	if userUUID == "" {
+		return nil, nil, fmt.Errorf("user UUID is required")
+	}
+	if s.repo == nil {
+		return nil, nil, fmt.Errorf("notification preferences are not available")
+	}
+	ctx, err := s.userContext(ctx, userUUID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pref, err := s.repo.GetPreference(ctx, userUUID)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, nil, fmt.Errorf("failed to get notification preferences: %w", err)
+		}
+		pref = &models.NotificationPreference{UserUUID: userUUID, Timezone: "UTC", ExpiryDelivery: models.ExpiryDeliveryDigest}
+	}
+
+	channelPrefs, err := s.repo.GetChannelPreferences(ctx, userUUID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get channel preferences: %w", err)
+	}
+
+	return pref, channelPrefs, nil
+}
+
+// UpdatePreferences stores the user's delivery settings and channel opt-ins/opt-outs
+func (s *NotificationService) UpdatePreferences(ctx context.Context, userUUID string, pref *models.NotificationPreference, channelPrefs []models.ChannelPreference) error {
+	if userUUID == "" {
+		return fmt.Errorf("user UUID is required")
+	}
+	if s.repo == nil {
+		return fmt.Errorf("notification preferences are not available")
+	}
+	ctx, err := s.userContext(ctx, userUUID)
+	if err != nil {
+		return err
+	}
+
+	if pref != nil {
+		if pref.QuietHoursStart != "" || pref.QuietHoursEnd != "" {
+			if _, err := parseClock(pref.QuietHoursStart); err != nil {
+				return err
+			}
+			if _, err := parseClock(pref.QuietHoursEnd); err != nil {
+				return err
+			}
+		}
+		if pref.Timezone == "" {
+			pref.Timezone = "UTC"
+		}
+		switch pref.ExpiryDelivery {
+		case "":
+			pref.ExpiryDelivery = models.ExpiryDeliveryDigest
+		case models.ExpiryDeliveryDigest, models.ExpiryDeliveryIndividual:
+		default:
+			return fmt.Errorf("invalid expiry delivery mode: %s", pref.ExpiryDelivery)
+		}
+		if _, err := time.LoadLocation(pref.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone: %s", pref.Timezone)
+		}
+		if pref.WebhookURL != "" {
+			if err := ValidateWebhookURL(ctx, pref.WebhookURL); err != nil {
+				return err
+			}
+		}
+		if pref.PhoneNumber != "" && !isPhoneNumber(pref.PhoneNumber) {
+			return fmt.Errorf("invalid phone number: %s, use E.164 format such as +14155550123", pref.PhoneNumber)
+		}
+
+		pref.UserUUID = userUUID
+		if err := s.repo.UpdatePreference(ctx, pref); err != nil {
+			return fmt.Errorf("failed to update notification preferences: %w", err)
+		}
+	}
+
+	for i := range channelPrefs {
+		if _, ok := ParseChannel(channelPrefs[i].Channel); !ok {
+			return fmt.Errorf("unknown channel: %s", channelPrefs[i].Channel)
+		}
+		if channelPrefs[i].NotificationType == "" {
+			return fmt.Errorf("notification type is required")
+		}
+		channelPrefs[i].UserUUID = userUUID
+		if err := s.repo.UpsertChannelPreference(ctx, &channelPrefs[i]); err != nil {
+			return fmt.Errorf("failed to update channel preference: %w", err)
+		}
+	}
+
+	log.Printf("NotificationService: Updated notification preferences for user %s", userUUID)
+	return nil
+}
@@ -0,0 +1,204 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// SMTPConfig holds the settings for sending email through an SMTP server
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPProvider sends email notifications through an SMTP server
+type SMTPProvider struct {
+	config SMTPConfig
+}
+
+// NewSMTPProvider creates a new SMTP email provider
+func NewSMTPProvider(config SMTPConfig) (*SMTPProvider, error) {
+	if config.Host == "" {
+		return nil, fmt.Errorf("SMTP host is required")
+	}
+	if config.From == "" {
+		return nil, fmt.Errorf("SMTP from address is required")
+	}
+	if config.Port == "" {
+		config.Port = "587"
+	}
+	return &SMTPProvider{config: config}, nil
+}
+
+// SendNotification sends the notification as an email, with attachments encoded as MIME parts
+func (p *SMTPProvider) SendNotification(ctx context.Context, recipient, subject string, data map[string]interface{}, attachments []Attachment) error {
+	if recipient == "" || strings.ContainsAny(recipient, "\r\n") {
+		return RecipientFailure(fmt.Errorf("invalid email address %q", recipient))
+	}
+
+	body, _ := data["message"].(string)
+	unsubscribeURL, _ := data["unsubscribe_url"].(string)
+
+	msg, err := buildMessage(p.config.From, recipient, subject, body, unsubscribeURL, attachments)
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+
+	var auth smtp.Auth
+	if p.config.Username != "" {
+		auth = smtp.PlainAuth("", p.config.Username, p.config.Password, p.config.Host)
+	}
+
+	addr := net.JoinHostPort(p.config.Host, p.config.Port)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- p.sendMail(addr, auth, recipient, msg)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-errCh:
+		if err != nil {
+			if IsRecipientError(err) {
+				return err
+			}
+			return fmt.Errorf("failed to send email: %w", err)
+		}
+	}
+
+	log.Printf("SMTPProvider: Sent email to %s with subject: %s (%d attachments)", recipient, subject, len(attachments))
+	return nil
+}
+
+// sendMail is smtp.SendMail with the RCPT step separated out, so a server rejecting the
+// address with a 5xx is reported as a RecipientError instead of a provider failure
+func (p *SMTPProvider) sendMail(addr string, auth smtp.Auth, recipient string, msg []byte) error {
+	c, err := smtp.Dial(addr)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: p.config.Host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return fmt.Errorf("smtp: server doesn't support AUTH")
+		}
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(p.config.From); err != nil {
+		return err
+	}
+	if err := c.Rcpt(recipient); err != nil {
+		var smtpErr *textproto.Error
+		if errors.As(err, &smtpErr) && smtpErr.Code >= 500 {
+			return RecipientFailure(fmt.Errorf("recipient rejected: %w", err))
+		}
+		return err
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// buildMessage renders an RFC 5322 message. Without attachments the body is sent
+// as text/plain; otherwise the message is multipart/mixed with one base64 part per file.
+// With an unsubscribe URL the RFC 8058 one-click List-Unsubscribe headers are added.
+func buildMessage(from, to, subject, body, unsubscribeURL string, attachments []Attachment) ([]byte, error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	if unsubscribeURL != "" {
+		fmt.Fprintf(&buf, "List-Unsubscribe: <%s>\r\n", unsubscribeURL)
+		buf.WriteString("List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n")
+		body += "\r\n\r\nUnsubscribe: " + unsubscribeURL
+	}
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if len(attachments) == 0 {
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+		buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+		writeBase64(&buf, []byte(body))
+		return buf.Bytes(), nil
+	}
+
+	writer := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", writer.Boundary())
+
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	writeBase64(part, []byte(body))
+
+	for _, attachment := range attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		filename := strings.NewReplacer("\r", "", "\n", "", `"`, "").Replace(attachment.Filename)
+
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(contentType, map[string]string{"name": filename})},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": filename})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		writeBase64(part, attachment.Content)
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeBase64 writes data as base64 wrapped at 76 characters per line
+func writeBase64(w io.Writer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		w.Write([]byte(encoded[:76] + "\r\n"))
+		encoded = encoded[76:]
+	}
+	w.Write([]byte(encoded + "\r\n"))
+}
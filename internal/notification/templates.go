@@ -0,0 +1,66 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+)
+
+var templateFuncs = template.FuncMap{
+	"date": formatDate,
+}
+
+var digestTemplate = template.Must(template.New(TypeDocumentDigest).Funcs(templateFuncs).Parse(
+	`{{if .Expired}}The following documents have expired:
+{{range .Expired}}  - {{.DocumentName}} ({{.DocumentCategory}}) expired on {{date .ExpiryDate}}
+{{end}}
+{{end}}{{if .Expiring}}The following documents are expiring soon:
+{{range .Expiring}}  - {{.DocumentName}} ({{.DocumentCategory}}) expires in {{.DaysUntilExpiry}} days on {{date .ExpiryDate}}
+{{end}}
+{{end}}Please renew them to keep your records up to date.`))
+
+// DigestItem is one document included in an expiry digest
+type DigestItem struct {
+	DocumentName     string
+	DocumentCategory string
+	ExpiryDate       string
+	DaysUntilExpiry  int32
+	IsExpired        bool
+	Attachments      []Attachment
+}
+
+// renderDigest builds the subject and body of a document expiry digest
+func renderDigest(items []DigestItem) (string, string, error) {
+	var view struct {
+		Expired  []DigestItem
+		Expiring []DigestItem
+	}
+	for _, item := range items {
+		if item.IsExpired {
+			view.Expired = append(view.Expired, item)
+		} else {
+			view.Expiring = append(view.Expiring, item)
+		}
+	}
+
+	var body bytes.Buffer
+	if err := digestTemplate.Execute(&body, view); err != nil {
+		return "", "", fmt.Errorf("failed to render digest: %w", err)
+	}
+
+	subject := fmt.Sprintf("%d documents need your attention", len(items))
+	if len(items) == 1 {
+		subject = "1 document needs your attention"
+	}
+
+	return subject, body.String(), nil
+}
+
+// formatDate renders an ISO timestamp as a plain date, leaving other values untouched
+func formatDate(value string) string {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.Format("2006-01-02")
+	}
+	return value
+}
@@ -0,0 +1,106 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const defaultTwilioBaseURL = "https://api.twilio.com"
+
+// phoneNumberPattern matches E.164 numbers such as +14155550123
+var phoneNumberPattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// TwilioConfig holds the settings for sending SMS through the Twilio Messages API
+type TwilioConfig struct {
+	AccountSID string
+	AuthToken  string
+	From       string
+	// BaseURL overrides the API endpoint, for Twilio-compatible gateways
+	BaseURL string
+}
+
+// TwilioProvider sends SMS notifications through the Twilio Messages API
+type TwilioProvider struct {
+	config TwilioConfig
+	client *http.Client
+}
+
+// NewTwilioProvider creates a new Twilio SMS provider
+func NewTwilioProvider(config TwilioConfig) (*TwilioProvider, error) {
+	if config.AccountSID == "" || config.AuthToken == "" {
+		return nil, fmt.Errorf("Twilio account SID and auth token are required")
+	}
+	if config.From == "" {
+		return nil, fmt.Errorf("Twilio from number is required")
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = defaultTwilioBaseURL
+	}
+	return &TwilioProvider{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// SendNotification sends the notification's message as a text message, falling back
+// to the subject when there is no message. Attachments are ignored.
+func (p *TwilioProvider) SendNotification(ctx context.Context, recipient, subject string, data map[string]interface{}, attachments []Attachment) error {
+	if !isPhoneNumber(recipient) {
+		return RecipientFailure(fmt.Errorf("invalid phone number %q", recipient))
+	}
+
+	body, _ := data["message"].(string)
+	if body == "" {
+		body = subject
+	}
+	form := url.Values{
+		"To":   {recipient},
+		"From": {p.config.From},
+		"Body": {body},
+	}
+
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", strings.TrimRight(p.config.BaseURL, "/"), url.PathEscape(p.config.AccountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create SMS request: %w", err)
+	}
+	req.SetBasicAuth(p.config.AccountSID, p.config.AuthToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("SMS request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&apiErr)
+		err := fmt.Errorf("SMS gateway returned status %d: %d %s", resp.StatusCode, apiErr.Code, apiErr.Message)
+		// 400 means the gateway refused this message, e.g. an unreachable or opted-out
+		// number; auth failures, throttling and outages are worth retrying
+		if resp.StatusCode == http.StatusBadRequest {
+			return RecipientFailure(err)
+		}
+		return err
+	}
+
+	log.Printf("TwilioProvider: Sent SMS to %s: %s", recipient, subject)
+	return nil
+}
+
+// isPhoneNumber reports whether number is in E.164 format
+func isPhoneNumber(number string) bool {
+	return phoneNumberPattern.MatchString(number)
+}
@@ -0,0 +1,91 @@
+package notification
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTwilioProviderSendsMessage(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parse form: %v", err)
+		}
+		got = r
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	provider, err := NewTwilioProvider(TwilioConfig{AccountSID: "AC123", AuthToken: "secret", From: "+15005550006", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewTwilioProvider: %v", err)
+	}
+	if err := provider.SendNotification(context.Background(), "+14155550123", "Expiry", map[string]interface{}{"message": "Your passport expires soon"}, nil); err != nil {
+		t.Fatalf("SendNotification: %v", err)
+	}
+
+	if got.URL.Path != "/2010-04-01/Accounts/AC123/Messages.json" {
+		t.Errorf("path = %s", got.URL.Path)
+	}
+	if user, pass, ok := got.BasicAuth(); !ok || user != "AC123" || pass != "secret" {
+		t.Errorf("basic auth = %q, %q, %v", user, pass, ok)
+	}
+	if got.PostForm.Get("To") != "+14155550123" || got.PostForm.Get("From") != "+15005550006" || got.PostForm.Get("Body") != "Your passport expires soon" {
+		t.Errorf("form = %v", got.PostForm)
+	}
+}
+
+func TestTwilioProviderClassifiesFailures(t *testing.T) {
+	tests := []struct {
+		name          string
+		recipient     string
+		status        int
+		wantRecipient bool
+	}{
+		{"invalid number", "555-0123", http.StatusCreated, true},
+		{"refused message", "+14155550123", http.StatusBadRequest, true},
+		{"bad credentials", "+14155550123", http.StatusUnauthorized, false},
+		{"gateway outage", "+14155550123", http.StatusServiceUnavailable, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{"code": 21211, "message": "refused"}`))
+			}))
+			defer server.Close()
+
+			provider, err := NewTwilioProvider(TwilioConfig{AccountSID: "AC123", AuthToken: "secret", From: "+15005550006", BaseURL: server.URL})
+			if err != nil {
+				t.Fatalf("NewTwilioProvider: %v", err)
+			}
+			err = provider.SendNotification(context.Background(), tt.recipient, "Expiry", nil, nil)
+			if err == nil {
+				t.Fatalf("SendNotification succeeded, want an error")
+			}
+			if IsRecipientError(err) != tt.wantRecipient {
+				t.Fatalf("IsRecipientError(%v) = %v, want %v", err, !tt.wantRecipient, tt.wantRecipient)
+			}
+		})
+	}
+}
+
+func TestFactoryEnablesTwilioSMS(t *testing.T) {
+	factory, err := NewFactory(FactoryConfig{
+		Channels:     []string{"sms"},
+		SMSProviders: []string{"twilio"},
+		Twilio:       TwilioConfig{AccountSID: "AC123", AuthToken: "secret", From: "+15005550006"},
+	})
+	if err != nil {
+		t.Fatalf("NewFactory: %v", err)
+	}
+	if _, ok := factory.providers[ChannelSMS]; !ok {
+		t.Fatalf("sms channel not enabled")
+	}
+
+	if _, err := NewFactory(FactoryConfig{Channels: []string{"sms"}, SMSProviders: []string{"twilio"}}); err == nil {
+		t.Fatalf("NewFactory accepted twilio without credentials")
+	}
+}
@@ -0,0 +1,202 @@
+package notification
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/johnroshan2255/core-service/internal/notification/models"
+)
+
+// UnsubscribePath is where unsubscribe links point, relative to the public base URL
+const UnsubscribePath = "/api/v1/notifications/unsubscribe"
+
+// SetUnsubscribe enables unsubscribe links. secret signs the tokens and baseURL is the
+// public address of the HTTP API. Links are omitted while either is empty.
+func (s *NotificationService) SetUnsubscribe(secret, baseURL string) {
+	s.unsubscribeSecret = []byte(secret)
+	s.unsubscribeBaseURL = strings.TrimRight(baseURL, "/")
+}
+
+// UnsubscribeURL returns the one-click unsubscribe link for a user and notification
+// category, or "" when unsubscribe links are not configured
+func (s *NotificationService) UnsubscribeURL(userUUID, category string) string {
+	if len(s.unsubscribeSecret) == 0 || s.unsubscribeBaseURL == "" || userUUID == "" {
+		return ""
+	}
+	return s.unsubscribeBaseURL + UnsubscribePath + "?token=" + url.QueryEscape(s.unsubscribeToken(userUUID, category))
+}
+
+// unsubscribeToken signs "<user uuid>:<category>". The token doesn't expire so that
+// links in old emails keep working.
+func (s *NotificationService) unsubscribeToken(userUUID, category string) string {
+	payload := userUUID + ":" + category
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + base64.RawURLEncoding.EncodeToString(s.signUnsubscribe(payload))
+}
+
+func (s *NotificationService) signUnsubscribe(payload string) []byte {
+	mac := hmac.New(sha256.New, s.unsubscribeSecret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// ParseUnsubscribeToken verifies a token and returns the user and category it was issued for
+func (s *NotificationService) ParseUnsubscribeToken(token string) (string, string, error) {
+	if len(s.unsubscribeSecret) == 0 {
+		return "", "", fmt.Errorf("unsubscribe links are not configured")
+	}
+
+	encodedPayload, encodedSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", "", fmt.Errorf("invalid unsubscribe token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid unsubscribe token")
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil || !hmac.Equal(sig, s.signUnsubscribe(string(payload))) {
+		return "", "", fmt.Errorf("invalid unsubscribe token")
+	}
+
+	userUUID, category, ok := strings.Cut(string(payload), ":")
+	if !ok || userUUID == "" || category == "" {
+		return "", "", fmt.Errorf("invalid unsubscribe token")
+	}
+	return userUUID, category, nil
+}
+
+// Unsubscribe opts the token's user out of email for the token's category
+func (s *NotificationService) Unsubscribe(ctx context.Context, token string) (string, error) {
+	userUUID, category, err := s.ParseUnsubscribeToken(token)
+	if err != nil {
+		return "", err
+	}
+	if IsMandatory(category) {
+		return "", fmt.Errorf("%s notifications cannot be unsubscribed from", category)
+	}
+	if s.repo == nil {
+		return "", fmt.Errorf("notification preferences are not available")
+	}
+	ctx, err = s.userContext(ctx, userUUID)
+	if err != nil {
+		return "", err
+	}
+
+	pref := &models.ChannelPreference{
+		UserUUID:         userUUID,
+		NotificationType: category,
+		Channel:          string(ChannelEmail),
+		Enabled:          false,
+	}
+	if err := s.repo.UpsertChannelPreference(ctx, pref); err != nil {
+		return "", fmt.Errorf("failed to unsubscribe: %w", err)
+	}
+
+	log.Printf("NotificationService: User %s unsubscribed from %s emails", userUUID, category)
+	return category, nil
+}
+
+// withUnsubscribeURL adds the unsubscribe link to a copy of the notification data.
+// Mandatory notifications never get one.
+func (s *NotificationService) withUnsubscribeURL(notificationType, userUUID string, data map[string]interface{}) map[string]interface{} {
+	if IsMandatory(notificationType) {
+		return data
+	}
+	link := s.UnsubscribeURL(userUUID, preferenceType(notificationType))
+	if link == "" {
+		return data
+	}
+
+	withLink := make(map[string]interface{}, len(data)+1)
+	for k, v := range data {
+		withLink[k] = v
+	}
+	withLink["unsubscribe_url"] = link
+	return withLink
+}
+
+// Email event types reported by the mail provider
+const (
+	EmailEventBounce    = "bounce"
+	EmailEventComplaint = "complaint"
+)
+
+// EmailEvent is a bounce or complaint reported by the mail provider
+type EmailEvent struct {
+	Type       string `json:"type"`
+	Email      string `json:"email"`
+	BounceType string `json:"bounce_type"`
+	Reason     string `json:"reason"`
+}
+
+// HandleEmailEvent suppresses the address of a permanent bounce or a complaint.
+// Transient bounces are only logged; the outbox retries them.
+func (s *NotificationService) HandleEmailEvent(ctx context.Context, event EmailEvent) error {
+	email := normalizeEmail(event.Email)
+	if email == "" {
+		return fmt.Errorf("email is required")
+	}
+
+	var reason string
+	switch event.Type {
+	case EmailEventBounce:
+		if event.BounceType == "transient" {
+			log.Printf("NotificationService: Transient bounce for %s: %s", email, event.Reason)
+			return nil
+		}
+		reason = models.SuppressionReasonBounce
+	case EmailEventComplaint:
+		reason = models.SuppressionReasonComplaint
+	default:
+		return fmt.Errorf("unknown email event type: %s", event.Type)
+	}
+
+	return s.SuppressEmail(ctx, email, reason, event.Reason)
+}
+
+// SuppressEmail adds an address to the suppression list
+func (s *NotificationService) SuppressEmail(ctx context.Context, email, reason, detail string) error {
+	email = normalizeEmail(email)
+	if email == "" {
+		return fmt.Errorf("email is required")
+	}
+	if s.repo == nil {
+		return fmt.Errorf("suppression list is not available")
+	}
+
+	suppression := &models.EmailSuppression{
+		Email:  email,
+		Reason: reason,
+		Detail: detail,
+	}
+	if err := s.repo.UpsertEmailSuppression(ctx, suppression); err != nil {
+		return fmt.Errorf("failed to suppress email: %w", err)
+	}
+
+	log.Printf("NotificationService: Suppressed %s (%s)", email, reason)
+	return nil
+}
+
+// isSuppressed reports whether email is on the suppression list. Lookup errors are
+// logged and treated as not suppressed so a database hiccup doesn't drop mail.
+func (s *NotificationService) isSuppressed(ctx context.Context, email string) bool {
+	if s.repo == nil {
+		return false
+	}
+	suppressed, err := s.repo.IsEmailSuppressed(ctx, normalizeEmail(email))
+	if err != nil {
+		log.Printf("NotificationService: Failed to check suppression list for %s: %v", email, err)
+		return false
+	}
+	return suppressed
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,98 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrInvalidWebhookURL is returned for webhook URLs that aren't public https endpoints
+var ErrInvalidWebhookURL = errors.New("invalid webhook URL")
+
+// ValidateWebhookURL checks that a user-supplied webhook URL is https and that its host
+// only resolves to public addresses, so webhooks can't reach internal services or
+// cloud metadata endpoints
+func ValidateWebhookURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" || u.User != nil {
+		return fmt.Errorf("%w: must be an https URL", ErrInvalidWebhookURL)
+	}
+
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if !isPublicIP(ip) {
+			return fmt.Errorf("%w: %s is not a public address", ErrInvalidWebhookURL, host)
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("%w: cannot resolve %s", ErrInvalidWebhookURL, host)
+	}
+	for _, addr := range addrs {
+		if !isPublicIP(addr.IP) {
+			return fmt.Errorf("%w: %s resolves to a non-public address", ErrInvalidWebhookURL, host)
+		}
+	}
+	return nil
+}
+
+// isPublicIP rejects loopback, private, link-local, CGNAT, multicast and unspecified addresses
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		// 0.0.0.0/8 and the 100.64.0.0/10 carrier-grade NAT range
+		if ip4[0] == 0 || (ip4[0] == 100 && ip4[1]&0xc0 == 64) {
+			return false
+		}
+	}
+	return true
+}
+
+// newWebhookClient returns an HTTP client whose dialer re-checks every resolved address,
+// so a host that passed ValidateWebhookURL can't later be re-pointed at an internal one
+func newWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("%w: refusing to connect to %s", ErrInvalidWebhookURL, host)
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// No proxy: the dialer must see the webhook's own address
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if req.URL.Scheme != "https" {
+				return fmt.Errorf("%w: redirect to a non-https URL", ErrInvalidWebhookURL)
+			}
+			if len(via) >= 3 {
+				return errors.New("too many webhook redirects")
+			}
+			return nil
+		},
+	}
+}
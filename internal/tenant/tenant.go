@@ -0,0 +1,208 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// column is the tenant column every tenant-owned table carries
+const column = "tenant_id"
+
+var (
+	// ErrNoTenant is returned for statements on a tenant-owned table whose context was
+	// neither scoped with WithTenant nor marked with Unscoped
+	ErrNoTenant = errors.New("tenant: no tenant in context for a tenant-owned table")
+	// ErrRawSQL is returned for raw SQL run with a tenant-scoped context. The plugin
+	// can't add the tenant check to it, so it has to run Unscoped and filter tenant_id
+	// itself.
+	ErrRawSQL = errors.New("tenant: raw SQL can't be scoped to a tenant")
+)
+
+type contextKey struct{}
+
+// unscoped is stored under contextKey by Unscoped
+type unscoped struct{}
+
+// WithTenant returns a context whose database queries only see rows of tenantID. An
+// empty tenantID is a tenant of its own: it sees only rows with an empty tenant_id.
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, tenantID)
+}
+
+// Unscoped returns a context whose database queries see every tenant's rows. It is
+// for system work that isn't done on behalf of one tenant: gRPC calls from other
+// services, schedulers, the outbox worker, migrations and the lookups that find a
+// caller's tenant in the first place.
+func Unscoped(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, unscoped{})
+}
+
+// IsUnscoped reports whether ctx was marked with Unscoped
+func IsUnscoped(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	_, ok := ctx.Value(contextKey{}).(unscoped)
+	return ok
+}
+
+// FromContext returns the tenant set with WithTenant. ok is false for contexts that
+// were never scoped and for Unscoped contexts.
+func FromContext(ctx context.Context) (tenantID string, ok bool) {
+	if ctx == nil {
+		return "", false
+	}
+	tenantID, ok = ctx.Value(contextKey{}).(string)
+	return tenantID, ok
+}
+
+// Scope restricts a query to the context's tenant, for queries the plugin can't see
+// into, such as Table("...") queries without a model
+func Scope(ctx context.Context) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if IsUnscoped(ctx) {
+			return db
+		}
+		tenantID, ok := FromContext(ctx)
+		if !ok {
+			db.AddError(ErrNoTenant)
+			return db
+		}
+		return db.Where(column+" = ?", tenantID)
+	}
+}
+
+// Plugin enforces tenant isolation for every model with a tenant_id column. When the
+// statement's context carries a tenant, queries, updates and deletes are limited to
+// that tenant's rows and new rows are stamped with it, overwriting whatever the caller
+// set. Statements on those models fail with ErrNoTenant unless the context is scoped
+// or Unscoped, and raw SQL fails with ErrRawSQL under a tenant-scoped context.
+type Plugin struct{}
+
+func (Plugin) Name() string {
+	return "tenant"
+}
+
+func (Plugin) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("tenant:create", stampTenant); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().Before("gorm:query").Register("tenant:query", scopeTenant); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("tenant:update", func(db *gorm.DB) {
+		stampTenant(db)
+		scopeTenant(db)
+	}); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("tenant:delete", scopeTenant); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register("tenant:row", scopeTenant); err != nil {
+		return err
+	}
+	return db.Callback().Raw().Before("gorm:raw").Register("tenant:raw", checkRaw)
+}
+
+// checkRaw rejects raw SQL under a tenant-scoped context. Raw and Exec build their SQL
+// up front, so a non-empty statement SQL before the gorm callbacks run means raw SQL.
+// Contexts that aren't scoped, such as the migrator's, may run it.
+func checkRaw(db *gorm.DB) {
+	if db.Error != nil || db.Statement.SQL.Len() == 0 {
+		return
+	}
+	if _, ok := FromContext(db.Statement.Context); ok {
+		db.AddError(ErrRawSQL)
+	}
+}
+
+// tenantField returns the statement's tenant column and the tenant to apply, or nil
+// when the model isn't tenant-owned or the context is Unscoped. It records
+// ErrNoTenant on the statement for a tenant-owned model without either.
+func tenantField(db *gorm.DB) (*schema.Field, string) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return nil, ""
+	}
+	field := db.Statement.Schema.LookUpField(column)
+	if field == nil {
+		return nil, ""
+	}
+	ctx := db.Statement.Context
+	if IsUnscoped(ctx) {
+		return nil, ""
+	}
+	tenantID, ok := FromContext(ctx)
+	if !ok {
+		db.AddError(ErrNoTenant)
+		return nil, ""
+	}
+	return field, tenantID
+}
+
+func scopeTenant(db *gorm.DB) {
+	if checkRaw(db); db.Error != nil || db.Statement.SQL.Len() > 0 {
+		return
+	}
+	field, tenantID := tenantField(db)
+	if field == nil {
+		return
+	}
+	eq := clause.Eq{Column: clause.Column{Table: db.Statement.Table, Name: field.DBName}, Value: tenantID}
+
+	// Group the existing conditions so an Or() in them can't escape the tenant check
+	if c, ok := db.Statement.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok && len(where.Exprs) > 0 {
+			c.Expression = clause.Where{Exprs: []clause.Expression{clause.And(where.Exprs...), eq}}
+			db.Statement.Clauses["WHERE"] = c
+			return
+		}
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{eq}})
+}
+
+// stampTenant sets the tenant on the rows being written so a caller can't move a row
+// into another tenant, and guards upserts against overwriting another tenant's row
+func stampTenant(db *gorm.DB) {
+	field, tenantID := tenantField(db)
+	if field == nil {
+		return
+	}
+
+	ctx := db.Statement.Context
+	switch value := db.Statement.ReflectValue; value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := field.Set(ctx, reflect.Indirect(value.Index(i)), tenantID); err != nil {
+				db.AddError(err)
+				return
+			}
+		}
+	case reflect.Struct:
+		if err := field.Set(ctx, value, tenantID); err != nil {
+			db.AddError(err)
+			return
+		}
+	}
+	if updates, ok := db.Statement.Dest.(map[string]interface{}); ok {
+		if _, set := updates[field.DBName]; set {
+			updates[field.DBName] = tenantID
+		}
+	}
+
+	if c, ok := db.Statement.Clauses["ON CONFLICT"]; ok {
+		if onConflict, ok := c.Expression.(clause.OnConflict); ok && !onConflict.DoNothing {
+			onConflict.Where.Exprs = append(onConflict.Where.Exprs, clause.Eq{
+				Column: clause.Column{Table: db.Statement.Table, Name: field.DBName},
+				Value:  tenantID,
+			})
+			c.Expression = onConflict
+			db.Statement.Clauses["ON CONFLICT"] = c
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package tenant_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/johnroshan2255/core-service/internal/tenant"
+	"github.com/johnroshan2255/core-service/internal/tenant/tenanttest"
+)
+
+type note struct {
+	ID       uint
+	TenantID string
+	Body     string
+}
+
+type setting struct {
+	ID    uint
+	Value string
+}
+
+func TestPluginRejectsUnscopedContext(t *testing.T) {
+	db := tenanttest.Open(t, &note{}, &setting{})
+	ctx := context.Background()
+
+	if err := db.WithContext(ctx).Create(&note{Body: "x"}).Error; !errors.Is(err, tenant.ErrNoTenant) {
+		t.Fatalf("create without tenant: got %v, want ErrNoTenant", err)
+	}
+	var notes []note
+	if err := db.WithContext(ctx).Find(&notes).Error; !errors.Is(err, tenant.ErrNoTenant) {
+		t.Fatalf("query without tenant: got %v, want ErrNoTenant", err)
+	}
+	if err := db.WithContext(ctx).Where("id = ?", 1).Delete(&note{}).Error; !errors.Is(err, tenant.ErrNoTenant) {
+		t.Fatalf("delete without tenant: got %v, want ErrNoTenant", err)
+	}
+	var count int64
+	if err := db.WithContext(ctx).Model(&note{}).Count(&count).Error; !errors.Is(err, tenant.ErrNoTenant) {
+		t.Fatalf("count without tenant: got %v, want ErrNoTenant", err)
+	}
+
+	// Tables without a tenant column don't need one
+	if err := db.WithContext(ctx).Create(&setting{Value: "x"}).Error; err != nil {
+		t.Fatalf("create untenanted row: %v", err)
+	}
+}
+
+func TestPluginScopesToTenant(t *testing.T) {
+	db := tenanttest.Open(t, &note{})
+
+	if err := db.WithContext(tenanttest.TenantA).Create(&note{Body: "a"}).Error; err != nil {
+		t.Fatalf("create a: %v", err)
+	}
+	// The tenant set on the row is overwritten with the context's
+	b := note{Body: "b", TenantID: "tenant-a"}
+	if err := db.WithContext(tenanttest.TenantB).Create(&b).Error; err != nil {
+		t.Fatalf("create b: %v", err)
+	}
+	if b.TenantID != "tenant-b" {
+		t.Fatalf("created row tenant = %q, want tenant-b", b.TenantID)
+	}
+
+	var notes []note
+	if err := db.WithContext(tenanttest.TenantA).Where("id = ?", b.ID).Or("body = ?", "b").Find(&notes).Error; err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if len(notes) != 0 {
+		t.Fatalf("tenant a saw %d of tenant b's rows", len(notes))
+	}
+
+	var all []note
+	if err := db.WithContext(tenant.Unscoped(context.Background())).Find(&all).Error; err != nil {
+		t.Fatalf("unscoped query: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("unscoped query returned %d rows, want 2", len(all))
+	}
+}
+
+func TestPluginRejectsRawSQLForTenant(t *testing.T) {
+	db := tenanttest.Open(t, &note{})
+
+	if err := db.WithContext(tenanttest.TenantA).Exec("DELETE FROM notes").Error; !errors.Is(err, tenant.ErrRawSQL) {
+		t.Fatalf("exec: got %v, want ErrRawSQL", err)
+	}
+	var notes []note
+	if err := db.WithContext(tenanttest.TenantA).Raw("SELECT * FROM notes").Scan(&notes).Error; !errors.Is(err, tenant.ErrRawSQL) {
+		t.Fatalf("raw query: got %v, want ErrRawSQL", err)
+	}
+	if err := db.WithContext(tenant.Unscoped(context.Background())).Exec("DELETE FROM notes").Error; err != nil {
+		t.Fatalf("unscoped exec: %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+// Package tenanttest sets up databases for tests of tenant-scoped repositories
+package tenanttest
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/johnroshan2255/core-service/internal/tenant"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// PostgresEnv names the variable holding the DSN of a Postgres database for the
+// Postgres-backed tests. They are skipped when it is unset.
+const PostgresEnv = "TEST_DATABASE_URL"
+
+// OwnerUUID is the user whose rows the isolation tests create in TenantB
+const OwnerUUID = "6f1c1d8e-3f4a-4c55-9d1e-0a7b2c3d4e5f"
+
+// ownerTenant is the tenant of TenantB
+const ownerTenant = "tenant-b"
+
+// Contexts for the two tenants the isolation tests play against each other
+var (
+	TenantA = tenant.WithTenant(context.Background(), "tenant-a")
+	TenantB = tenant.WithTenant(context.Background(), ownerTenant)
+)
+
+// Open returns an in-memory SQLite database with the tenant plugin installed and the
+// given models migrated. It is closed when the test ends.
+func Open(t testing.TB, models ...interface{}) *gorm.DB {
+	t.Helper()
+
+	db := open(t, sqlite.Open(":memory:"))
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("get database handle: %v", err)
+	}
+	// Every connection to :memory: is a separate database
+	sqlDB.SetMaxOpenConns(1)
+
+	migrate(t, db, models...)
+	return db
+}
+
+// OpenPostgres returns a database in a fresh schema of the Postgres database named by
+// TEST_DATABASE_URL, with the tenant plugin installed and the given models migrated.
+// The schema is dropped when the test ends.
+func OpenPostgres(t testing.TB, models ...interface{}) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv(PostgresEnv)
+	if dsn == "" {
+		t.Skipf("%s not set", PostgresEnv)
+	}
+
+	schema := fmt.Sprintf("tenanttest_%d", time.Now().UnixNano())
+	admin := open(t, postgres.Open(dsn))
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := admin.Exec("DROP SCHEMA " + schema + " CASCADE").Error; err != nil {
+			t.Logf("drop schema %s: %v", schema, err)
+		}
+	})
+
+	db := open(t, postgres.Open(withSearchPath(dsn, schema)))
+	migrate(t, db, models...)
+	return db
+}
+
+// withSearchPath sets the search path of a URL or keyword/value DSN
+func withSearchPath(dsn, schema string) string {
+	if u, err := url.Parse(dsn); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		query := u.Query()
+		query.Set("search_path", schema)
+		u.RawQuery = query.Encode()
+		return u.String()
+	}
+	return dsn + " search_path=" + schema
+}
+
+func open(t testing.TB, dialector gorm.Dialector) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	if err := db.Use(tenant.Plugin{}); err != nil {
+		t.Fatalf("install tenant plugin: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("get database handle: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func migrate(t testing.TB, db *gorm.DB, models ...interface{}) {
+	t.Helper()
+
+	if err := db.WithContext(tenant.Unscoped(context.Background())).AutoMigrate(models...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+}
+
+// CrossTenantWrite is a write TenantA attempts against rows TenantB owns
+type CrossTenantWrite struct {
+	Name  string
+	Write func(ctx context.Context) error
+	// WantErr is set for writes that should fail, such as on a unique index, rather
+	// than succeed without matching any of TenantB's rows
+	WantErr bool
+}
+
+// AssertWritesIsolated runs each write as TenantA and fails the test unless it returned
+// the expected result and left every TenantB row in tables unchanged
+func AssertWritesIsolated(t testing.TB, db *gorm.DB, tables []string, writes ...CrossTenantWrite) {
+	t.Helper()
+
+	for _, write := range writes {
+		before := ownerRows(t, db, tables)
+		err := write.Write(TenantA)
+		if write.WantErr && err == nil {
+			t.Errorf("%s from another tenant succeeded, want an error", write.Name)
+		}
+		if !write.WantErr && err != nil {
+			t.Errorf("%s from another tenant: %v", write.Name, err)
+		}
+		if after := ownerRows(t, db, tables); !reflect.DeepEqual(before, after) {
+			t.Errorf("%s from another tenant changed the owner's rows:\nbefore %v\nafter  %v", write.Name, before, after)
+		}
+	}
+}
+
+// ownerRows reads TenantB's rows of tables, soft-deleted ones included
+func ownerRows(t testing.TB, db *gorm.DB, tables []string) map[string][]map[string]interface{} {
+	t.Helper()
+
+	rows := make(map[string][]map[string]interface{}, len(tables))
+	for _, table := range tables {
+		var tableRows []map[string]interface{}
+		if err := db.WithContext(tenant.Unscoped(context.Background())).
+			Table(table).
+			Where("tenant_id = ?", ownerTenant).
+			Order("id").
+			Find(&tableRows).Error; err != nil {
+			t.Fatalf("read %s: %v", table, err)
+		}
+		rows[table] = tableRows
+	}
+	return rows
+}
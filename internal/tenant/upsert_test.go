@@ -0,0 +1,63 @@
+package tenant_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/johnroshan2255/core-service/internal/tenant/tenanttest"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type account struct {
+	ID       uint
+	TenantID string
+	Email    string `gorm:"uniqueIndex"`
+	Name     string
+}
+
+// upsertName inserts an account or renames the one with the same email
+func upsertName(db *gorm.DB, ctx context.Context, email, name string) error {
+	return db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "email"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name"}),
+	}).Create(&account{Email: email, Name: name}).Error
+}
+
+func TestPluginGuardsUpserts(t *testing.T) {
+	for name, open := range map[string]func(testing.TB, ...interface{}) *gorm.DB{
+		"sqlite":   tenanttest.Open,
+		"postgres": tenanttest.OpenPostgres,
+	} {
+		t.Run(name, func(t *testing.T) {
+			db := open(t, &account{})
+
+			owner := account{Email: "owner@example.com", Name: "Owner"}
+			if err := db.WithContext(tenanttest.TenantB).Create(&owner).Error; err != nil {
+				t.Fatalf("create: %v", err)
+			}
+
+			tenanttest.AssertWritesIsolated(t, db, []string{"accounts"},
+				tenanttest.CrossTenantWrite{Name: "upsert on a unique column", Write: func(ctx context.Context) error {
+					return upsertName(db, ctx, owner.Email, "Hijacked")
+				}},
+				// Save updates nothing in the caller's tenant and falls back to an upsert on the primary key
+				tenanttest.CrossTenantWrite{Name: "Save", Write: func(ctx context.Context) error {
+					return db.WithContext(ctx).Save(&account{ID: owner.ID, Email: "hijack@example.com", Name: "Hijacked"}).Error
+				}},
+			)
+
+			// The owner's own upserts still update the row
+			if err := upsertName(db, tenanttest.TenantB, owner.Email, "Renamed"); err != nil {
+				t.Fatalf("owner upsert: %v", err)
+			}
+			var got account
+			if err := db.WithContext(tenanttest.TenantB).First(&got, owner.ID).Error; err != nil {
+				t.Fatalf("reload: %v", err)
+			}
+			if got.Name != "Renamed" {
+				t.Fatalf("owner upsert left name %q, want Renamed", got.Name)
+			}
+		})
+	}
+}
@@ -5,47 +5,142 @@ import (
 	"crypto/tls"
 	"fmt"
 	"log"
+	"net"
+	"strings"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
 
 	notificationv1 "github.com/johnroshan2255/core-service/proto/notification/v1"
 )
 
+const (
+	defaultCallTimeout      = 10 * time.Second
+	defaultRetryMaxAttempts = 4
+	defaultKeepaliveTime    = 30 * time.Second
+	defaultKeepaliveTimeout = 10 * time.Second
+)
+
+// serviceConfig balances calls round robin across every notification address and
+// retries calls that fail before reaching a server. Retries are only attempted for
+// UNAVAILABLE, which gRPC returns when the request was not processed.
+const serviceConfig = `{
+	"loadBalancingConfig": [{"round_robin": {}}],
+	"methodConfig": [{
+		"name": [{"service": "notification.v1.NotificationService"}],
+		"waitForReady": true,
+		"retryPolicy": {
+			"maxAttempts": %d,
+			"initialBackoff": "0.2s",
+			"maxBackoff": "2s",
+			"backoffMultiplier": 2,
+			"retryableStatusCodes": ["UNAVAILABLE"]
+		}
+	}]
+}`
+
+// ClientConfig tunes the notification client connection
+type ClientConfig struct {
+	// CallTimeout is the deadline applied to unary calls whose context has none
+	CallTimeout time.Duration
+	// RetryMaxAttempts is the total number of attempts per call, including the first
+	RetryMaxAttempts int
+	// KeepaliveTime is how often idle connections are pinged
+	KeepaliveTime time.Duration
+	// KeepaliveTimeout is how long to wait for a ping ack before closing the connection
+	KeepaliveTimeout time.Duration
+	// TLS replaces the default system-roots TLS config when TLS is enabled, e.g. to
+	// present a client certificate for mutual TLS
+	TLS *tls.Config
+}
+
 // ClientFactory creates gRPC clients for inter-service communication
 type ClientFactory struct {
 	serviceKey string
-	grpcAddr   string
+	grpcAddrs  []string
 	useTLS     bool
+	config     ClientConfig
 }
 
-// NewClientFactory creates a new gRPC client factory
-func NewClientFactory(grpcAddr, serviceKey string, useTLS bool) *ClientFactory {
+// NewClientFactory creates a new gRPC client factory. grpcAddr may be a
+// comma-separated list of notification service addresses to balance across.
+func NewClientFactory(grpcAddr, serviceKey string, useTLS bool, config ClientConfig) *ClientFactory {
+	var addrs []string
+	for _, addr := range strings.Split(grpcAddr, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+
+	if config.CallTimeout <= 0 {
+		config.CallTimeout = defaultCallTimeout
+	}
+	if config.RetryMaxAttempts <= 0 {
+		config.RetryMaxAttempts = defaultRetryMaxAttempts
+	}
+	if config.KeepaliveTime <= 0 {
+		config.KeepaliveTime = defaultKeepaliveTime
+	}
+	if config.KeepaliveTimeout <= 0 {
+		config.KeepaliveTimeout = defaultKeepaliveTimeout
+	}
+
 	return &ClientFactory{
 		serviceKey: serviceKey,
-		grpcAddr:   grpcAddr,
+		grpcAddrs:  addrs,
 		useTLS:     useTLS,
+		config:     config,
 	}
 }
 
 // CreateClient creates a gRPC client connection with authentication
 func (f *ClientFactory) CreateClient(ctx context.Context) (*grpc.ClientConn, error) {
+	if len(f.grpcAddrs) == 0 {
+		return nil, fmt.Errorf("notification service address is required")
+	}
+
 	var creds credentials.TransportCredentials
 
 	if f.useTLS {
-		config := &tls.Config{
-			InsecureSkipVerify: false,
+		config := f.config.TLS
+		if config == nil {
+			config = &tls.Config{
+				InsecureSkipVerify: false,
+			}
 		}
 		creds = credentials.NewTLS(config)
-		log.Printf("Creating gRPC client with TLS to %s", f.grpcAddr)
+		log.Printf("Creating gRPC client with TLS to %s", strings.Join(f.grpcAddrs, ", "))
 	} else {
 		creds = insecure.NewCredentials()
-		log.Printf("WARNING: Creating gRPC client without TLS to %s", f.grpcAddr)
+		log.Printf("WARNING: Creating gRPC client without TLS to %s", strings.Join(f.grpcAddrs, ", "))
 	}
 
-	conn, err := grpc.NewClient(f.grpcAddr, grpc.WithTransportCredentials(creds))
+	// A manual resolver hands every configured address to the round_robin balancer.
+	// The target name is a placeholder, so each address carries the name its
+	// certificate is verified against and that is sent as the authority.
+	addresses := make([]resolver.Address, 0, len(f.grpcAddrs))
+	for _, addr := range f.grpcAddrs {
+		addresses = append(addresses, resolver.Address{Addr: addr, ServerName: f.serverName(addr)})
+	}
+	r := manual.NewBuilderWithScheme("notification")
+	r.InitialState(resolver.State{Addresses: addresses})
+
+	conn, err := grpc.NewClient(r.Scheme()+":///notification",
+		grpc.WithResolvers(r),
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultServiceConfig(fmt.Sprintf(serviceConfig, f.config.RetryMaxAttempts)),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                f.config.KeepaliveTime,
+			Timeout:             f.config.KeepaliveTimeout,
+			PermitWithoutStream: true,
+		}),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create gRPC client: %w", err)
 	}
@@ -53,19 +148,29 @@ func (f *ClientFactory) CreateClient(ctx context.Context) (*grpc.ClientConn, err
 	return conn, nil
 }
 
+// serverName returns the configured TLS server name, or the host of addr without one
+func (f *ClientFactory) serverName(addr string) string {
+	if f.config.TLS != nil && f.config.TLS.ServerName != "" {
+		return f.config.TLS.ServerName
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
 // CreateContextWithAuth adds service key to context metadata for authentication
 func (f *ClientFactory) CreateContextWithAuth(ctx context.Context) context.Context {
 	if f.serviceKey == "" {
 		return ctx
 	}
-	md := metadata.New(map[string]string{
-		"service-key": f.serviceKey,
-	})
-	return metadata.NewOutgoingContext(ctx, md)
+	return metadata.AppendToOutgoingContext(ctx, "service-key", f.serviceKey)
 }
 
 // Client is a gRPC client for the notification service
-// This is used by other services within the same project to call notification service via gRPC
+// This is used by other services within the same project to call notification service via gRPC.
+// A Client holds one connection and is safe for concurrent use; create it once and share it.
 type Client struct {
 	conn    *grpc.ClientConn
 	factory *ClientFactory
@@ -97,9 +202,20 @@ func (c *Client) Close() error {
 	return nil
 }
 
+// callContext authenticates the call and applies the default deadline when the
+// caller didn't set one
+func (c *Client) callContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx = c.factory.CreateContextWithAuth(ctx)
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.factory.config.CallTimeout)
+}
+
 // NotifyUserCreated calls the notification service to send a user creation notification
 func (c *Client) NotifyUserCreated(ctx context.Context, userUUID, email, username string) error {
-	ctx = c.factory.CreateContextWithAuth(ctx)
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
 
 	req := &notificationv1.UserCreatedRequest{
 		UserUuid: userUUID,
@@ -122,3 +238,140 @@ func (c *Client) NotifyUserCreated(ctx context.Context, userUUID, email, usernam
 	return nil
 }
 
+// NotifyDocumentExpiry calls the notification service to send a single document expiry notification
+func (c *Client) NotifyDocumentExpiry(ctx context.Context, req *notificationv1.DocumentExpiryRequest) error {
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
+
+	resp, err := c.client.NotifyDocumentExpiry(ctx, req)
+	if err != nil {
+		log.Printf("NotificationClient: Failed to notify document expiry: %v", err)
+		return fmt.Errorf("failed to notify document expiry: %w", err)
+	}
+
+	if !resp.Success {
+		log.Printf("NotificationClient: Notification service returned failure: %s", resp.Message)
+		return fmt.Errorf("notification service error: %s", resp.Message)
+	}
+
+	return nil
+}
+
+// NotifyDocumentDigest calls the notification service to send a user's expiry digest
+func (c *Client) NotifyDocumentDigest(ctx context.Context, req *notificationv1.DocumentDigestRequest) error {
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
+
+	resp, err := c.client.NotifyDocumentDigest(ctx, req)
+	if err != nil {
+		log.Printf("NotificationClient: Failed to notify document digest: %v", err)
+		return fmt.Errorf("failed to notify document digest: %w", err)
+	}
+
+	if !resp.Success {
+		log.Printf("NotificationClient: Notification service returned failure: %s", resp.Message)
+		return fmt.Errorf("notification service error: %s", resp.Message)
+	}
+
+	return nil
+}
+
+// SendNotification calls the notification service to send a generic, schema-validated notification
+func (c *Client) SendNotification(ctx context.Context, req *notificationv1.SendNotificationRequest) error {
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
+
+	resp, err := c.client.SendNotification(ctx, req)
+	if err != nil {
+		log.Printf("NotificationClient: Failed to send %s notification: %v", req.EventType, err)
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+
+	if !resp.Success {
+		log.Printf("NotificationClient: Notification service returned failure: %s", resp.Message)
+		return fmt.Errorf("notification service error: %s", resp.Message)
+	}
+
+	return nil
+}
+
+// ScheduleNotification schedules a generic notification for later delivery
+func (c *Client) ScheduleNotification(ctx context.Context, req *notificationv1.ScheduleNotificationRequest) (*notificationv1.ScheduleNotificationResponse, error) {
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
+
+	resp, err := c.client.ScheduleNotification(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to schedule notification: %w", err)
+	}
+	return resp, nil
+}
+
+// CancelScheduledNotification cancels scheduled notifications by ID, or by user and key
+func (c *Client) CancelScheduledNotification(ctx context.Context, req *notificationv1.CancelScheduledNotificationRequest) (int64, error) {
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
+
+	resp, err := c.client.CancelScheduledNotification(ctx, req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to cancel scheduled notification: %w", err)
+	}
+	return resp.Cancelled, nil
+}
+
+// ListDeadLetters returns notifications that exhausted their delivery attempts
+func (c *Client) ListDeadLetters(ctx context.Context, req *notificationv1.ListDeadLettersRequest) (*notificationv1.ListDeadLettersResponse, error) {
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
+
+	resp, err := c.client.ListDeadLetters(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	return resp, nil
+}
+
+// ReplayDeadLetter re-queues a dead-lettered notification
+func (c *Client) ReplayDeadLetter(ctx context.Context, id uint64) error {
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
+
+	if _, err := c.client.ReplayDeadLetter(ctx, &notificationv1.ReplayDeadLetterRequest{Id: id}); err != nil {
+		return fmt.Errorf("failed to replay dead letter: %w", err)
+	}
+	return nil
+}
+
+// ListNotificationHistory returns the delivery attempts made for a user
+func (c *Client) ListNotificationHistory(ctx context.Context, req *notificationv1.ListNotificationHistoryRequest) (*notificationv1.ListNotificationHistoryResponse, error) {
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
+
+	resp, err := c.client.ListNotificationHistory(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notification history: %w", err)
+	}
+	return resp, nil
+}
+
+// GetProviderHealth reports the circuit breaker state of every notification provider
+func (c *Client) GetProviderHealth(ctx context.Context) ([]*notificationv1.ProviderHealth, error) {
+	ctx, cancel := c.callContext(ctx)
+	defer cancel()
+
+	resp, err := c.client.GetProviderHealth(ctx, &notificationv1.GetProviderHealthRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider health: %w", err)
+	}
+	return resp.Providers, nil
+}
+
+// StreamEvents subscribes to notification events. No default deadline is applied;
+// the stream ends when ctx is cancelled.
+func (c *Client) StreamEvents(ctx context.Context, req *notificationv1.StreamEventsRequest) (grpc.ServerStreamingClient[notificationv1.NotificationEvent], error) {
+	stream, err := c.client.StreamEvents(c.factory.CreateContextWithAuth(ctx), req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stream events: %w", err)
+	}
+	return stream, nil
+}
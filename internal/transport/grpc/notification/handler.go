@@ -2,27 +2,51 @@ package notification
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"time"
 
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
 
+	"github.com/johnroshan2255/core-service/internal/middleware"
 	"github.com/johnroshan2255/core-service/internal/notification"
 	notificationv1 "github.com/johnroshan2255/core-service/proto/notification/v1"
 )
 
+// maxListLimit caps the page size of list RPCs
+const maxListLimit = 200
+
 // Handler implements the gRPC notification service
 type Handler struct {
 	notificationv1.UnimplementedNotificationServiceServer
-	service *notification.NotificationService
+	service         *notification.NotificationService
+	authServiceName string
 }
 
-// NewHandler creates a new notification gRPC handler
-func NewHandler(notificationService *notification.NotificationService) *Handler {
+// NewHandler creates a new notification gRPC handler. authServiceName is the service
+// credential name allowed to send mandatory notification types; when empty no caller may.
+func NewHandler(notificationService *notification.NotificationService, authServiceName string) *Handler {
 	return &Handler{
-		service: notificationService,
+		service:         notificationService,
+		authServiceName: authServiceName,
+	}
+}
+
+// authorizeEventType keeps mandatory types, which bypass opt-outs and quiet hours, to the
+// auth service so other backends can't use them to reach users who opted out
+func (h *Handler) authorizeEventType(ctx context.Context, eventType string) error {
+	if !notification.IsMandatory(eventType) {
+		return nil
 	}
+	caller, ok := middleware.ServiceIdentityFromContext(ctx)
+	if !ok || h.authServiceName == "" || caller.Name != h.authServiceName {
+		return status.Errorf(codes.PermissionDenied, "%s notifications may only be sent by the auth service", eventType)
+	}
+	return nil
 }
 
 // NotifyUserCreated handles the gRPC call for user creation notifications
@@ -67,7 +91,7 @@ func (h *Handler) NotifyDocumentExpiry(ctx context.Context, req *notificationv1.
 	log.Printf("NotificationHandler: Received NotifyDocumentExpiry request - UUID: %s, Email: %s, Document: %s, Category: %s, Expired: %v",
 		req.UserUuid, req.Email, req.DocumentName, req.DocumentCategory, req.IsExpired)
 
-	err := h.service.NotifyDocumentExpiry(ctx, req.UserUuid, req.Email, req.DocumentName, req.DocumentCategory, req.ExpiryDate, req.DaysUntilExpiry, req.IsExpired, req.Message)
+	err := h.service.NotifyDocumentExpiry(ctx, req.UserUuid, req.Email, req.DocumentName, req.DocumentCategory, req.ExpiryDate, req.DaysUntilExpiry, req.IsExpired, req.Message, toAttachments(req.Attachments))
 	if err != nil {
 		log.Printf("NotificationHandler: Error processing document expiry notification: %v", err)
 		return &notificationv1.DocumentExpiryResponse{
@@ -83,3 +107,362 @@ func (h *Handler) NotifyDocumentExpiry(ctx context.Context, req *notificationv1.
 	}, nil
 }
 
+// NotifyDocumentDigest handles the gRPC call for grouped document expiry notifications
+func (h *Handler) NotifyDocumentDigest(ctx context.Context, req *notificationv1.DocumentDigestRequest) (*notificationv1.DocumentDigestResponse, error) {
+	if req.UserUuid == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "user_uuid is required")
+	}
+	if req.Email == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "email is required")
+	}
+	if len(req.Documents) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "documents are required")
+	}
+
+	log.Printf("NotificationHandler: Received NotifyDocumentDigest request - UUID: %s, Email: %s, Documents: %d",
+		req.UserUuid, req.Email, len(req.Documents))
+
+	items := make([]notification.DigestItem, 0, len(req.Documents))
+	for _, doc := range req.Documents {
+		if doc.DocumentName == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "document_name is required")
+		}
+		items = append(items, notification.DigestItem{
+			DocumentName:     doc.DocumentName,
+			DocumentCategory: doc.DocumentCategory,
+			ExpiryDate:       doc.ExpiryDate,
+			DaysUntilExpiry:  doc.DaysUntilExpiry,
+			IsExpired:        doc.IsExpired,
+			Attachments:      toAttachments(doc.Attachments),
+		})
+	}
+
+	err := h.service.NotifyDocumentDigest(ctx, req.UserUuid, req.Email, items)
+	if err != nil {
+		log.Printf("NotificationHandler: Error processing document digest: %v", err)
+		return &notificationv1.DocumentDigestResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to send notification: %v", err),
+		}, status.Errorf(codes.Internal, "failed to process notification: %v", err)
+	}
+
+	log.Printf("NotificationHandler: Successfully processed document digest for %s", req.Email)
+	return &notificationv1.DocumentDigestResponse{
+		Success: true,
+		Message: "Notification sent successfully",
+	}, nil
+}
+
+// SendNotification handles the generic, schema-validated notification RPC
+func (h *Handler) SendNotification(ctx context.Context, req *notificationv1.SendNotificationRequest) (*notificationv1.SendNotificationResponse, error) {
+	if req.EventType == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "event_type is required")
+	}
+	if req.Recipient == nil || req.Recipient.UserUuid == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "recipient.user_uuid is required")
+	}
+
+	log.Printf("NotificationHandler: Received SendNotification request - Event: %s, UUID: %s, Locale: %s",
+		req.EventType, req.Recipient.UserUuid, req.Locale)
+
+	var payload map[string]interface{}
+	if req.Payload != nil {
+		payload = req.Payload.AsMap()
+	}
+
+	n := notification.Notification{
+		EventType: req.EventType,
+		Recipient: notification.Recipient{
+			UserUUID: req.GetRecipient().GetUserUuid(),
+			Email:    req.GetRecipient().GetEmail(),
+		},
+		Locale:  req.Locale,
+		Payload: payload,
+	}
+
+	if err := h.authorizeEventType(ctx, n.EventType); err != nil {
+		return nil, err
+	}
+
+	if err := h.service.Send(ctx, n); err != nil {
+		if errors.Is(err, notification.ErrInvalidNotification) {
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+		log.Printf("NotificationHandler: Error processing %s event: %v", req.EventType, err)
+		return &notificationv1.SendNotificationResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to send notification: %v", err),
+		}, status.Errorf(codes.Internal, "failed to process notification: %v", err)
+	}
+
+	log.Printf("NotificationHandler: Successfully processed %s event for %s", req.EventType, req.Recipient.UserUuid)
+	return &notificationv1.SendNotificationResponse{
+		Success: true,
+		Message: "Notification sent successfully",
+	}, nil
+}
+
+// ListDeadLetters returns notifications that exhausted their delivery attempts
+func (h *Handler) ListDeadLetters(ctx context.Context, req *notificationv1.ListDeadLettersRequest) (*notificationv1.ListDeadLettersResponse, error) {
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = 50
+	} else if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	msgs, err := h.service.ListDeadLetters(ctx, limit, int(req.Offset))
+	if err != nil {
+		log.Printf("NotificationHandler: Error listing dead letters: %v", err)
+		return nil, status.Errorf(codes.Internal, "failed to list dead letters: %v", err)
+	}
+
+	resp := &notificationv1.ListDeadLettersResponse{
+		DeadLetters: make([]*notificationv1.DeadLetter, 0, len(msgs)),
+	}
+	for _, msg := range msgs {
+		resp.DeadLetters = append(resp.DeadLetters, &notificationv1.DeadLetter{
+			Id:               uint64(msg.ID),
+			NotificationType: msg.NotificationType,
+			UserUuid:         msg.UserUUID,
+			Channel:          msg.Channel,
+			Recipient:        msg.Recipient,
+			Subject:          msg.Subject,
+			Attempts:         int32(msg.Attempts),
+			LastError:        msg.LastError,
+			CreatedAt:        msg.CreatedAt.Format(time.RFC3339),
+			UpdatedAt:        msg.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return resp, nil
+}
+
+// ReplayDeadLetter re-queues a dead-lettered notification for delivery
+func (h *Handler) ReplayDeadLetter(ctx context.Context, req *notificationv1.ReplayDeadLetterRequest) (*notificationv1.ReplayDeadLetterResponse, error) {
+	if req.Id == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "id is required")
+	}
+
+	log.Printf("NotificationHandler: Received ReplayDeadLetter request - ID: %d", req.Id)
+
+	if err := h.service.ReplayDeadLetter(ctx, uint(req.Id)); err != nil {
+		log.Printf("NotificationHandler: Error replaying dead letter: %v", err)
+		code := codes.Internal
+		if errors.Is(err, notification.ErrDeadLetterNotFound) {
+			code = codes.NotFound
+		}
+		return &notificationv1.ReplayDeadLetterResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to replay dead letter: %v", err),
+		}, status.Errorf(code, "failed to replay dead letter: %v", err)
+	}
+
+	return &notificationv1.ReplayDeadLetterResponse{
+		Success: true,
+		Message: "Dead letter re-queued successfully",
+	}, nil
+}
+
+// ListNotificationHistory returns the delivery attempts made for a user
+func (h *Handler) ListNotificationHistory(ctx context.Context, req *notificationv1.ListNotificationHistoryRequest) (*notificationv1.ListNotificationHistoryResponse, error) {
+	if req.UserUuid == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "user_uuid is required")
+	}
+
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = 50
+	} else if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	attempts, err := h.service.GetHistory(ctx, req.UserUuid, req.NotificationType, limit, int(req.Offset))
+	if err != nil {
+		log.Printf("NotificationHandler: Error listing notification history: %v", err)
+		return nil, status.Errorf(codes.Internal, "failed to list notification history: %v", err)
+	}
+
+	resp := &notificationv1.ListNotificationHistoryResponse{
+		Attempts: make([]*notificationv1.NotificationAttempt, 0, len(attempts)),
+	}
+	for _, attempt := range attempts {
+		resp.Attempts = append(resp.Attempts, &notificationv1.NotificationAttempt{
+			Id:               uint64(attempt.ID),
+			NotificationType: attempt.NotificationType,
+			Channel:          attempt.Channel,
+			Recipient:        attempt.Recipient,
+			Subject:          attempt.Subject,
+			Attempt:          int32(attempt.Attempt),
+			Status:           string(attempt.Status),
+			ProviderResponse: attempt.ProviderResponse,
+			QueuedAt:         attempt.QueuedAt.Format(time.RFC3339),
+			AttemptedAt:      attempt.AttemptedAt.Format(time.RFC3339),
+		})
+	}
+
+	return resp, nil
+}
+
+// StreamEvents pushes real-time notification events to a backend until it disconnects
+func (h *Handler) StreamEvents(req *notificationv1.StreamEventsRequest, stream grpc.ServerStreamingServer[notificationv1.NotificationEvent]) error {
+	types := make(map[string]bool, len(req.EventTypes))
+	for _, t := range req.EventTypes {
+		types[t] = true
+	}
+
+	events, cancel := h.service.Subscribe(req.UserUuid)
+	defer cancel()
+
+	log.Printf("NotificationHandler: Started event stream - UUID: %q, Types: %v", req.UserUuid, req.EventTypes)
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("NotificationHandler: Event stream closed - UUID: %q", req.UserUuid)
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if len(types) > 0 && !types[event.Type] {
+				continue
+			}
+
+			payload, err := structpb.NewStruct(event.Payload)
+			if err != nil {
+				log.Printf("NotificationHandler: Failed to encode %s event payload: %v", event.Type, err)
+				continue
+			}
+
+			if err := stream.Send(&notificationv1.NotificationEvent{
+				Type:      event.Type,
+				UserUuid:  event.UserUUID,
+				Payload:   payload,
+				CreatedAt: event.CreatedAt.Format(time.RFC3339),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// GetProviderHealth reports the circuit breaker state of every notification provider
+func (h *Handler) GetProviderHealth(ctx context.Context, req *notificationv1.GetProviderHealthRequest) (*notificationv1.GetProviderHealthResponse, error) {
+	health := h.service.ProviderHealth()
+
+	resp := &notificationv1.GetProviderHealthResponse{
+		Providers: make([]*notificationv1.ProviderHealth, 0, len(health)),
+	}
+	for _, p := range health {
+		provider := &notificationv1.ProviderHealth{
+			Channel:             string(p.Channel),
+			Provider:            p.Provider,
+			State:               p.State,
+			ConsecutiveFailures: int32(p.ConsecutiveFailures),
+			Sent:                p.Sent,
+			Failed:              p.Failed,
+			LastError:           p.LastError,
+		}
+		if p.LastFailureAt != nil {
+			provider.LastFailureAt = p.LastFailureAt.Format(time.RFC3339)
+		}
+		if p.OpenedAt != nil {
+			provider.OpenedAt = p.OpenedAt.Format(time.RFC3339)
+		}
+		resp.Providers = append(resp.Providers, provider)
+	}
+
+	return resp, nil
+}
+
+// ScheduleNotification stores a generic notification to be sent later
+func (h *Handler) ScheduleNotification(ctx context.Context, req *notificationv1.ScheduleNotificationRequest) (*notificationv1.ScheduleNotificationResponse, error) {
+	var sendAt time.Time
+	switch {
+	case req.SendAt != "":
+		var err error
+		sendAt, err = time.Parse(time.RFC3339, req.SendAt)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "send_at must be an RFC3339 timestamp")
+		}
+	case req.DelaySeconds > 0:
+		sendAt = time.Now().Add(time.Duration(req.DelaySeconds) * time.Second)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "send_at or delay_seconds is required")
+	}
+
+	var payload map[string]interface{}
+	if req.Payload != nil {
+		payload = req.Payload.AsMap()
+	}
+
+	n := notification.Notification{
+		EventType: req.EventType,
+		Recipient: notification.Recipient{
+			UserUUID: req.GetRecipient().GetUserUuid(),
+			Email:    req.GetRecipient().GetEmail(),
+		},
+		Locale:  req.Locale,
+		Payload: payload,
+	}
+
+	if err := h.authorizeEventType(ctx, n.EventType); err != nil {
+		return nil, err
+	}
+
+	scheduled, err := h.service.Schedule(ctx, n, sendAt, req.Key)
+	if err != nil {
+		if errors.Is(err, notification.ErrInvalidNotification) {
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+		log.Printf("NotificationHandler: Error scheduling %s event: %v", req.EventType, err)
+		return nil, status.Errorf(codes.Internal, "failed to schedule notification: %v", err)
+	}
+
+	return &notificationv1.ScheduleNotificationResponse{
+		Id:     uint64(scheduled.ID),
+		SendAt: scheduled.SendAt.Format(time.RFC3339),
+	}, nil
+}
+
+// CancelScheduledNotification cancels pending scheduled notifications by ID or by user and key
+func (h *Handler) CancelScheduledNotification(ctx context.Context, req *notificationv1.CancelScheduledNotificationRequest) (*notificationv1.CancelScheduledNotificationResponse, error) {
+	if req.Id != 0 {
+		if err := h.service.CancelScheduled(ctx, uint(req.Id)); err != nil {
+			if errors.Is(err, notification.ErrScheduledNotificationNotFound) {
+				return nil, status.Errorf(codes.NotFound, "%v", err)
+			}
+			log.Printf("NotificationHandler: Error cancelling scheduled notification %d: %v", req.Id, err)
+			return nil, status.Errorf(codes.Internal, "failed to cancel scheduled notification: %v", err)
+		}
+		return &notificationv1.CancelScheduledNotificationResponse{Cancelled: 1}, nil
+	}
+
+	if req.UserUuid == "" || req.Key == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "id, or user_uuid and key, are required")
+	}
+
+	cancelled, err := h.service.CancelScheduledByKey(ctx, req.UserUuid, req.Key)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to cancel scheduled notifications: %v", err)
+	}
+	return &notificationv1.CancelScheduledNotificationResponse{Cancelled: cancelled}, nil
+}
+
+// toAttachments converts proto attachments to the notification package type
+func toAttachments(attachments []*notificationv1.Attachment) []notification.Attachment {
+	if len(attachments) == 0 {
+		return nil
+	}
+	result := make([]notification.Attachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		result = append(result, notification.Attachment{
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			Content:     attachment.Content,
+		})
+	}
+	return result
+}
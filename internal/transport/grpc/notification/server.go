@@ -1,44 +1,59 @@
 package notification
 
 import (
-	"crypto/tls"
 	"fmt"
 	"log"
 	"net"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
 
+	"github.com/johnroshan2255/core-service/internal/certs"
 	"github.com/johnroshan2255/core-service/internal/config"
 	"github.com/johnroshan2255/core-service/internal/middleware"
 	"github.com/johnroshan2255/core-service/internal/notification"
 	notificationv1 "github.com/johnroshan2255/core-service/proto/notification/v1"
 )
 
-// NewServer creates and configures the gRPC server with TLS
-func NewServer(cfg *config.Config) (*grpc.Server, net.Listener, error) {
-	authInterceptor := middleware.NewBackendAuthInterceptor(cfg.ServiceKey)
+// NewServer creates and configures the gRPC server with TLS. idempotencyStore may be
+// nil when no database is configured, in which case requests are not deduplicated.
+func NewServer(cfg *config.Config, serviceCredentials *middleware.ServiceCredentials, idempotencyStore middleware.IdempotencyStore) (*grpc.Server, net.Listener, error) {
+	authInterceptor := middleware.NewBackendAuthInterceptor(serviceCredentials)
+	if idempotencyStore == nil {
+		log.Printf("WARNING: no database configured; gRPC idempotency keys will not be enforced")
+	}
+	idempotencyInterceptor := middleware.NewIdempotencyInterceptor(idempotencyStore, cfg.IdempotencyWindow)
 
 	serverOpts := []grpc.ServerOption{
-		grpc.UnaryInterceptor(authInterceptor.UnaryInterceptor()),
+		grpc.ChainUnaryInterceptor(authInterceptor.UnaryInterceptor(), idempotencyInterceptor.UnaryInterceptor()),
 		grpc.StreamInterceptor(authInterceptor.StreamInterceptor()),
+		// Let clients keep idle connections alive with pings; see ClientConfig.KeepaliveTime
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             15 * time.Second,
+			PermitWithoutStream: true,
+		}),
 	}
 
 	if cfg.TLSEnabled {
-		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		reloader, err := certs.NewReloader(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSCAFile)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to load TLS certificates: %w", err)
+			return nil, nil, err
 		}
 
-		tlsConfig := &tls.Config{
-			Certificates: []tls.Certificate{cert},
-			ClientAuth:   tls.NoClientCert,
-			MinVersion:   tls.VersionTLS12,
+		tlsConfig, err := reloader.ServerConfig(cfg.TLSRequireClientCert)
+		if err != nil {
+			return nil, nil, err
 		}
 
 		creds := credentials.NewTLS(tlsConfig)
 		serverOpts = append(serverOpts, grpc.Creds(creds))
-		log.Printf("gRPC server configured with TLS")
+		if cfg.TLSRequireClientCert {
+			log.Printf("gRPC server configured with mutual TLS")
+		} else {
+			log.Printf("gRPC server configured with TLS")
+		}
 	} else {
 		log.Printf("WARNING: gRPC server running without TLS (not recommended for production)")
 	}
@@ -60,20 +75,20 @@ func NewServer(cfg *config.Config) (*grpc.Server, net.Listener, error) {
 }
 
 // SetupServer registers notification gRPC service on the server
-func SetupServer(grpcServer *grpc.Server, service *notification.NotificationService) {
-	handler := NewHandler(service)
+func SetupServer(grpcServer *grpc.Server, cfg *config.Config, service *notification.NotificationService) {
+	handler := NewHandler(service, cfg.AuthServiceName)
 	notificationv1.RegisterNotificationServiceServer(grpcServer, handler)
 	log.Printf("Notification gRPC service registered")
 }
 
 // StartGRPCServer starts the gRPC server for notification service
-func StartGRPCServer(cfg *config.Config, service *notification.NotificationService) {
-	grpcServer, grpcListener, err := NewServer(cfg)
+func StartGRPCServer(cfg *config.Config, service *notification.NotificationService, serviceCredentials *middleware.ServiceCredentials, idempotencyStore middleware.IdempotencyStore) {
+	grpcServer, grpcListener, err := NewServer(cfg, serviceCredentials, idempotencyStore)
 	if err != nil {
 		log.Fatalf("failed to setup gRPC server: %v", err)
 	}
 
-	SetupServer(grpcServer, service)
+	SetupServer(grpcServer, cfg, service)
 
 	grpcPort := cfg.GRPCPort
 	if grpcPort == "" {
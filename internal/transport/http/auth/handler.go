@@ -0,0 +1,518 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/johnroshan2255/core-service/internal/auth"
+	"github.com/johnroshan2255/core-service/internal/middleware"
+)
+
+// Handler handles HTTP requests for registration, login and token refresh
+type Handler struct {
+	service *auth.Service
+}
+
+func NewHandler(service *auth.Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+func (h *Handler) Register(c *gin.Context) {
+	var req struct {
+		Email       string `json:"email" binding:"required"`
+		Username    string `json:"username" binding:"required"`
+		Password    string `json:"password" binding:"required"`
+		FirstName   string `json:"first_name"`
+		LastName    string `json:"last_name"`
+		PhoneNumber string `json:"phone_number"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, tokens, err := h.service.Register(c.Request.Context(), auth.RegisterInput{
+		Email:       req.Email,
+		Username:    req.Username,
+		Password:    req.Password,
+		FirstName:   req.FirstName,
+		LastName:    req.LastName,
+		PhoneNumber: req.PhoneNumber,
+	}, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, auth.ErrEmailTaken) || errors.Is(err, auth.ErrUsernameTaken) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, auth.ErrInvalidRegistration) || errors.Is(err, auth.ErrInvalidPassword) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("AuthHandler: Registration failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Registration failed"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data": gin.H{
+			"user": gin.H{
+				"uuid":     user.UUID,
+				"email":    user.Email,
+				"username": user.Username,
+			},
+			"tokens": tokens,
+		},
+	})
+}
+
+func (h *Handler) Login(c *gin.Context) {
+	var req struct {
+		Email    string `json:"email" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.Login(c.Request.Context(), req.Email, req.Password, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("AuthHandler: Login failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Login failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// CompleteMFALogin answers the challenge returned by Login. A wrong code returns 401
+// with a fresh mfa_token to retry with; the old one can't be used again. Too many
+// wrong codes lock the user's second factor and return 429.
+func (h *Handler) CompleteMFALogin(c *gin.Context) {
+	var req struct {
+		MFAToken     string `json:"mfa_token" binding:"required"`
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recovery_code"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.CompleteMFALogin(c.Request.Context(), req.MFAToken, req.Code, req.RecoveryCode, clientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidMFACode):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error(), "data": result})
+		case errors.Is(err, auth.ErrInvalidMFAToken):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, auth.ErrMFALocked):
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		case errors.Is(err, auth.ErrMFACodeRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			log.Printf("AuthHandler: MFA login failed: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Login failed"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+func (h *Handler) Refresh(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := h.service.Refresh(c.Request.Context(), req.RefreshToken, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidRefreshToken) || errors.Is(err, auth.ErrRefreshTokenReused) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("AuthHandler: Token refresh failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Token refresh failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    tokens,
+	})
+}
+
+func (h *Handler) Logout(c *gin.Context) {
+	var req struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		if errors.Is(err, auth.ErrInvalidRefreshToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Logged out successfully",
+	})
+}
+
+// ForgotPassword always reports success so it can't be used to probe for accounts
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	var req struct {
+		Email string `json:"email" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.RequestPasswordReset(c.Request.Context(), req.Email); err != nil {
+		if errors.Is(err, auth.ErrLinksNotConfigured) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Password reset is not available"})
+			return
+		}
+		log.Printf("AuthHandler: Password reset request failed: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "If an account exists for that email, a reset link has been sent",
+	})
+}
+
+func (h *Handler) ResetPassword(c *gin.Context) {
+	var req struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.ResetPassword(c.Request.Context(), req.Token, req.Password); err != nil {
+		if errors.Is(err, auth.ErrInvalidActionToken) || errors.Is(err, auth.ErrInvalidPassword) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("AuthHandler: Password reset failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Password reset failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Password reset successfully",
+	})
+}
+
+func (h *Handler) RequestEmailVerification(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+		return
+	}
+
+	if err := h.service.RequestEmailVerification(c.Request.Context(), uuid); err != nil {
+		switch {
+		case errors.Is(err, auth.ErrEmailAlreadyVerified):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, auth.ErrLinksNotConfigured):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Email verification is not available"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Verification email sent",
+	})
+}
+
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	var req struct {
+		Token string `json:"token" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		if errors.Is(err, auth.ErrInvalidActionToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Email verified successfully",
+	})
+}
+
+func (h *Handler) ListSessions(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok || claims.UUID() == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+		return
+	}
+
+	sessions, err := h.service.ListSessions(c.Request.Context(), claims.UUID())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	data := make([]gin.H, len(sessions))
+	for i, session := range sessions {
+		data[i] = gin.H{
+			"id":           session.ID,
+			"user_agent":   session.UserAgent,
+			"ip_address":   session.IPAddress,
+			"created_at":   session.CreatedAt,
+			"last_used_at": session.LastUsedAt,
+			"expires_at":   session.ExpiresAt,
+			"current":      session.ID == claims.SessionID,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    data,
+	})
+}
+
+func (h *Handler) RevokeSession(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+		return
+	}
+
+	if err := h.service.RevokeSession(c.Request.Context(), uuid, c.Param("id")); err != nil {
+		if errors.Is(err, auth.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Session revoked successfully",
+	})
+}
+
+// RevokeAllSessions signs the user out everywhere. With ?keep_current=true the session
+// making the request stays signed in.
+func (h *Handler) RevokeAllSessions(c *gin.Context) {
+	claims, ok := middleware.GetClaims(c)
+	if !ok || claims.UUID() == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+		return
+	}
+
+	var except string
+	if c.Query("keep_current") == "true" {
+		except = claims.SessionID
+	}
+
+	revoked, err := h.service.RevokeAllSessions(c.Request.Context(), claims.UUID(), except)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    gin.H{"revoked": revoked},
+	})
+}
+
+func (h *Handler) GetMFAStatus(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+		return
+	}
+
+	status, err := h.service.GetMFAStatus(c.Request.Context(), uuid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    status,
+	})
+}
+
+func (h *Handler) EnrollTOTP(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+		return
+	}
+
+	enrollment, err := h.service.EnrollTOTP(c.Request.Context(), uuid)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    enrollment,
+	})
+}
+
+func (h *Handler) ConfirmTOTP(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+		return
+	}
+
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := h.service.ConfirmTOTP(c.Request.Context(), uuid, req.Code)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    gin.H{"recovery_codes": codes},
+	})
+}
+
+func (h *Handler) DisableTOTP(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+		return
+	}
+
+	var req struct {
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recovery_code"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.DisableTOTP(c.Request.Context(), uuid, req.Code, req.RecoveryCode); err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+func (h *Handler) RegenerateRecoveryCodes(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+		return
+	}
+
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := h.service.RegenerateRecoveryCodes(c.Request.Context(), uuid, req.Code)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    gin.H{"recovery_codes": codes},
+	})
+}
+
+func respondMFAError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, auth.ErrMFANotConfigured):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Two-factor authentication is not available"})
+	case errors.Is(err, auth.ErrMFAAlreadyEnabled), errors.Is(err, auth.ErrMFANotEnabled), errors.Is(err, auth.ErrMFANotEnrolled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, auth.ErrInvalidMFACode), errors.Is(err, auth.ErrMFACodeRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, auth.ErrMFALocked):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func clientInfo(c *gin.Context) auth.ClientInfo {
+	return auth.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+}
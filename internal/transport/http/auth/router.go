@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/johnroshan2255/core-service/internal/auth"
+	"github.com/johnroshan2255/core-service/internal/middleware"
+)
+
+// SetupRoutes adds authentication routes to the provided router
+func SetupRoutes(router *gin.Engine, authService *auth.Service) {
+	authHandler := NewHandler(authService)
+
+	api := router.Group("/api/v1")
+	{
+		authRoutes := api.Group("/auth")
+		{
+			authRoutes.POST("/register", authHandler.Register)
+			authRoutes.POST("/login", authHandler.Login)
+			authRoutes.POST("/login/mfa", authHandler.CompleteMFALogin)
+			authRoutes.POST("/refresh", authHandler.Refresh)
+			authRoutes.POST("/logout", authHandler.Logout)
+			authRoutes.POST("/password/forgot", authHandler.ForgotPassword)
+			authRoutes.POST("/password/reset", authHandler.ResetPassword)
+			authRoutes.POST("/email/verification", middleware.AuthMiddleware(), authHandler.RequestEmailVerification)
+			authRoutes.POST("/email/verify", authHandler.VerifyEmail)
+
+			sessions := authRoutes.Group("/sessions")
+			sessions.Use(middleware.AuthMiddleware())
+			{
+				sessions.GET("", authHandler.ListSessions)
+				sessions.DELETE("", authHandler.RevokeAllSessions)
+				sessions.DELETE("/:id", authHandler.RevokeSession)
+			}
+
+			mfa := authRoutes.Group("/mfa")
+			mfa.Use(middleware.AuthMiddleware())
+			{
+				mfa.GET("", authHandler.GetMFAStatus)
+				mfa.POST("/totp/enroll", authHandler.EnrollTOTP)
+				mfa.POST("/totp/confirm", authHandler.ConfirmTOTP)
+				mfa.DELETE("/totp", authHandler.DisableTOTP)
+				mfa.POST("/recovery-codes", authHandler.RegenerateRecoveryCodes)
+			}
+		}
+	}
+}
@@ -1,25 +1,32 @@
 package document
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/johnroshan2255/core-service/internal/document/calendar"
 	"github.com/johnroshan2255/core-service/internal/document/models"
 	"github.com/johnroshan2255/core-service/internal/document/service"
+	"github.com/johnroshan2255/core-service/internal/middleware"
 )
 
 type Handler struct {
 	service *service.Service
 	uploadPath string
+	// publicBaseURL is where clients reach this service, used to build calendar feed URLs
+	publicBaseURL string
 }
 
-func NewHandler(documentService *service.Service, uploadPath string) *Handler {
+func NewHandler(documentService *service.Service, uploadPath, publicBaseURL string) *Handler {
 	if uploadPath == "" {
 		uploadPath = "./uploads/documents"
 	}
@@ -29,21 +36,16 @@ func NewHandler(documentService *service.Service, uploadPath string) *Handler {
 	}
 	
 	return &Handler{
-		service:    documentService,
-		uploadPath: uploadPath,
+		service:       documentService,
+		uploadPath:    uploadPath,
+		publicBaseURL: strings.TrimRight(publicBaseURL, "/"),
 	}
 }
 
 func (h *Handler) UploadDocument(c *gin.Context) {
-	userUUID, exists := c.Get("user_uuid")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
-		return
-	}
-
-	uuid, ok := userUUID.(string)
+	uuid, ok := middleware.UserUUID(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user UUID format"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
 		return
 	}
 
@@ -133,15 +135,9 @@ func (h *Handler) UploadDocument(c *gin.Context) {
 }
 
 func (h *Handler) GetDocument(c *gin.Context) {
-	userUUID, exists := c.Get("user_uuid")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
-		return
-	}
-
-	uuid, ok := userUUID.(string)
+	uuid, ok := middleware.UserUUID(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user UUID format"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
 		return
 	}
 
@@ -164,16 +160,42 @@ func (h *Handler) GetDocument(c *gin.Context) {
 	})
 }
 
-func (h *Handler) ListDocuments(c *gin.Context) {
-	userUUID, exists := c.Get("user_uuid")
-	if !exists {
+// GetAnyDocument returns a document owned by any user. The route requires the
+// documents:read_any permission.
+func (h *Handler) GetAnyDocument(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
+	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
 		return
 	}
 
-	uuid, ok := userUUID.(string)
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		return
+	}
+
+	doc, err := h.service.GetAnyDocument(c.Request.Context(), uuid, uint(id))
+	if err != nil {
+		if errors.Is(err, service.ErrDocumentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("DocumentHandler: Failed to get document %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get document"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    doc,
+	})
+}
+
+func (h *Handler) ListDocuments(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user UUID format"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
 		return
 	}
 
@@ -203,15 +225,9 @@ func (h *Handler) ListDocuments(c *gin.Context) {
 }
 
 func (h *Handler) UpdateDocument(c *gin.Context) {
-	userUUID, exists := c.Get("user_uuid")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
-		return
-	}
-
-	uuid, ok := userUUID.(string)
+	uuid, ok := middleware.UserUUID(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user UUID format"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
 		return
 	}
 
@@ -263,15 +279,9 @@ func (h *Handler) UpdateDocument(c *gin.Context) {
 }
 
 func (h *Handler) DeleteDocument(c *gin.Context) {
-	userUUID, exists := c.Get("user_uuid")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
-		return
-	}
-
-	uuid, ok := userUUID.(string)
+	uuid, ok := middleware.UserUUID(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user UUID format"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
 		return
 	}
 
@@ -302,3 +312,78 @@ func (h *Handler) DeleteDocument(c *gin.Context) {
 		"message": "Document deleted successfully",
 	})
 }
+
+func (h *Handler) GetDocumentCalendar(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		return
+	}
+
+	ics, err := h.service.GetDocumentCalendar(c.Request.Context(), uuid, uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrDocumentNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrNoExpiryDate):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			log.Printf("DocumentHandler: Failed to build calendar for document %d: %v", id, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build calendar"})
+		}
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="document-%d-expiry.ics"`, id))
+	c.Data(http.StatusOK, calendar.ContentType, ics)
+}
+
+// RotateCalendarToken issues a new secret calendar feed URL, invalidating the previous one
+func (h *Handler) RotateCalendarToken(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+		return
+	}
+
+	token, err := h.service.RotateCalendarToken(c.Request.Context(), uuid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	path := fmt.Sprintf("/api/v1/calendar/%s.ics", token)
+	data := gin.H{"feed_path": path}
+	// The URL comes from configuration, never the request's Host header, so a forged
+	// Host can't make clients subscribe to someone else's server
+	if h.publicBaseURL != "" {
+		data["feed_url"] = h.publicBaseURL + path
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// GetCalendarFeed serves the ICS feed of upcoming expiries. It is authenticated by
+// the secret token in the URL so calendar apps can subscribe without a JWT.
+func (h *Handler) GetCalendarFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	ics, err := h.service.GetCalendarFeed(c.Request.Context(), token)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=3600")
+	c.Data(http.StatusOK, calendar.ContentType, ics)
+}
@@ -6,17 +6,20 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/johnroshan2255/core-service/internal/auth"
 	"github.com/johnroshan2255/core-service/internal/document/service"
 	"github.com/johnroshan2255/core-service/internal/middleware"
 )
 
-func SetupRoutes(router *gin.Engine, documentService *service.Service) {
+// SetupRoutes adds document routes. publicBaseURL is the externally visible base URL
+// used for calendar feed links; feed_url is left out of responses when it is empty.
+func SetupRoutes(router *gin.Engine, documentService *service.Service, publicBaseURL string) {
 	uploadPath := os.Getenv("DOCUMENT_UPLOAD_PATH")
 	if uploadPath == "" {
 		uploadPath = "./uploads/documents"
 	}
 
-	documentHandler := NewHandler(documentService, uploadPath)
+	documentHandler := NewHandler(documentService, uploadPath, publicBaseURL)
 
 	api := router.Group("/api/v1")
 	{
@@ -28,6 +31,19 @@ func SetupRoutes(router *gin.Engine, documentService *service.Service) {
 			documents.GET("/:id", documentHandler.GetDocument)
 			documents.PUT("/:id", documentHandler.UpdateDocument)
 			documents.DELETE("/:id", documentHandler.DeleteDocument)
+			documents.GET("/:id/calendar.ics", documentHandler.GetDocumentCalendar)
+		}
+
+		admin := api.Group("/admin/documents")
+		admin.Use(middleware.AuthMiddleware(), middleware.RequirePermission(auth.PermissionDocumentsReadAny))
+		{
+			admin.GET("/:id", documentHandler.GetAnyDocument)
+		}
+
+		calendar := api.Group("/calendar")
+		{
+			calendar.POST("/token", middleware.AuthMiddleware(), documentHandler.RotateCalendarToken)
+			calendar.GET("/:token", documentHandler.GetCalendarFeed)
 		}
 	}
 }
@@ -41,7 +57,7 @@ func SetupRouter(documentService *service.Service) *gin.Engine {
 
 	router.SetTrustedProxies([]string{})
 
-	SetupRoutes(router, documentService)
+	SetupRoutes(router, documentService, os.Getenv("PUBLIC_BASE_URL"))
 
 	return router
 }
@@ -1,2 +1,244 @@
 package invoice
 
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/johnroshan2255/core-service/internal/invoice"
+	"github.com/johnroshan2255/core-service/internal/middleware"
+)
+
+// maxListLimit caps the page size of list endpoints
+const maxListLimit = 200
+
+type Handler struct {
+	service *invoice.Service
+}
+
+func NewHandler(invoiceService *invoice.Service) *Handler {
+	return &Handler{
+		service: invoiceService,
+	}
+}
+
+// ListMyInvoices returns the caller's own issued invoices
+func (h *Handler) ListMyInvoices(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+		return
+	}
+
+	limit, offset := pagination(c)
+	invoices, err := h.service.ListUserInvoices(c.Request.Context(), uuid, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    invoices,
+	})
+}
+
+func (h *Handler) GetMyInvoice(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+		return
+	}
+
+	inv, err := h.service.GetUserInvoice(c.Request.Context(), uuid, c.Param("uuid"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    inv,
+	})
+}
+
+// ListInvoices returns invoices for any user, optionally filtered by ?user_uuid= and ?status=
+func (h *Handler) ListInvoices(c *gin.Context) {
+	limit, offset := pagination(c)
+	invoices, err := h.service.ListInvoices(c.Request.Context(), invoice.ListFilter{
+		UserUUID: c.Query("user_uuid"),
+		Status:   invoice.Status(c.Query("status")),
+	}, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    invoices,
+	})
+}
+
+func (h *Handler) GetInvoice(c *gin.Context) {
+	inv, err := h.service.GetInvoice(c.Request.Context(), c.Param("uuid"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    inv,
+	})
+}
+
+func (h *Handler) CreateInvoice(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+		return
+	}
+
+	var req struct {
+		UserUUID    string `json:"user_uuid" binding:"required"`
+		Number      string `json:"number"`
+		AmountCents int64  `json:"amount_cents" binding:"required"`
+		Currency    string `json:"currency"`
+		Description string `json:"description"`
+		DueDate     string `json:"due_date"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	inv := &invoice.Invoice{
+		UserUUID:    req.UserUUID,
+		Number:      req.Number,
+		AmountCents: req.AmountCents,
+		Currency:    req.Currency,
+		Description: req.Description,
+	}
+	if req.DueDate != "" {
+		dueDate, err := time.Parse("2006-01-02", req.DueDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "due_date must be YYYY-MM-DD"})
+			return
+		}
+		inv.DueDate = &dueDate
+	}
+
+	created, err := h.service.CreateInvoice(c.Request.Context(), uuid, inv)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    created,
+	})
+}
+
+func (h *Handler) UpdateInvoice(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+		return
+	}
+
+	var req struct {
+		AmountCents *int64  `json:"amount_cents"`
+		Currency    *string `json:"currency"`
+		Description *string `json:"description"`
+		DueDate     string  `json:"due_date"`
+		Status      string  `json:"status"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates := make(map[string]interface{})
+	if req.AmountCents != nil {
+		updates["amount_cents"] = *req.AmountCents
+	}
+	if req.Currency != nil {
+		updates["currency"] = *req.Currency
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.DueDate != "" {
+		dueDate, err := time.Parse("2006-01-02", req.DueDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "due_date must be YYYY-MM-DD"})
+			return
+		}
+		updates["due_date"] = &dueDate
+	}
+	if req.Status != "" {
+		updates["status"] = req.Status
+	}
+
+	updated, err := h.service.UpdateInvoice(c.Request.Context(), uuid, c.Param("uuid"), updates)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    updated,
+	})
+}
+
+func (h *Handler) DeleteInvoice(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+		return
+	}
+
+	if err := h.service.DeleteInvoice(c.Request.Context(), uuid, c.Param("uuid")); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Invoice deleted successfully",
+	})
+}
+
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, invoice.ErrInvoiceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, invoice.ErrInvoiceLocked), errors.Is(err, invoice.ErrInvalidTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+func pagination(c *gin.Context) (int, int) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		limit = 10
+	} else if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
@@ -1,2 +1,35 @@
 package invoice
 
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/johnroshan2255/core-service/internal/auth"
+	"github.com/johnroshan2255/core-service/internal/invoice"
+	"github.com/johnroshan2255/core-service/internal/middleware"
+)
+
+// SetupRoutes adds invoice routes to the provided router. Users can read their own
+// invoices; managing them requires the invoices permissions.
+func SetupRoutes(router *gin.Engine, invoiceService *invoice.Service) {
+	invoiceHandler := NewHandler(invoiceService)
+
+	api := router.Group("/api/v1")
+	{
+		invoices := api.Group("/invoices")
+		invoices.Use(middleware.AuthMiddleware())
+		{
+			invoices.GET("", invoiceHandler.ListMyInvoices)
+			invoices.GET("/:uuid", invoiceHandler.GetMyInvoice)
+		}
+
+		admin := api.Group("/admin/invoices")
+		admin.Use(middleware.AuthMiddleware())
+		{
+			admin.GET("", middleware.RequirePermission(auth.PermissionInvoicesRead), invoiceHandler.ListInvoices)
+			admin.GET("/:uuid", middleware.RequirePermission(auth.PermissionInvoicesRead), invoiceHandler.GetInvoice)
+			admin.POST("", middleware.RequirePermission(auth.PermissionInvoicesWrite), invoiceHandler.CreateInvoice)
+			admin.PUT("/:uuid", middleware.RequirePermission(auth.PermissionInvoicesWrite), invoiceHandler.UpdateInvoice)
+			admin.DELETE("/:uuid", middleware.RequirePermission(auth.PermissionInvoicesWrite), invoiceHandler.DeleteInvoice)
+		}
+	}
+}
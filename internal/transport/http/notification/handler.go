@@ -1,13 +1,22 @@
 package notification
 
 import (
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/johnroshan2255/core-service/internal/middleware"
 	"github.com/johnroshan2255/core-service/internal/notification"
+	"github.com/johnroshan2255/core-service/internal/notification/models"
 )
 
+// maxListLimit caps the page size of list endpoints
+const maxListLimit = 200
+
 // Handler handles HTTP requests for notifications
 type Handler struct {
 	service *notification.NotificationService
@@ -48,3 +57,294 @@ func (h *Handler) HandleUserCreated(c *gin.Context) {
 	})
 }
 
+// HandleDocumentExpiry handles HTTP POST request for document expiry notification
+func (h *Handler) HandleDocumentExpiry(c *gin.Context) {
+	var req struct {
+		UserUUID         string                    `json:"user_uuid" binding:"required"`
+		Email            string                    `json:"email" binding:"required,email"`
+		DocumentName     string                    `json:"document_name" binding:"required"`
+		DocumentCategory string                    `json:"document_category"`
+		ExpiryDate       string                    `json:"expiry_date"`
+		DaysUntilExpiry  int32                     `json:"days_until_expiry"`
+		IsExpired        bool                      `json:"is_expired"`
+		Message          string                    `json:"message"`
+		Attachments      []notification.Attachment `json:"attachments"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.service.NotifyDocumentExpiry(ctx, req.UserUUID, req.Email, req.DocumentName, req.DocumentCategory, req.ExpiryDate, req.DaysUntilExpiry, req.IsExpired, req.Message, req.Attachments); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to send notification",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Notification sent successfully",
+	})
+}
+
+// GetPreferences returns the authenticated user's notification preferences
+func (h *Handler) GetPreferences(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+		return
+	}
+
+	pref, channels, err := h.service.GetPreferences(c.Request.Context(), uuid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"preferences": pref,
+			"channels":    channels,
+		},
+	})
+}
+
+// UpdatePreferences stores the authenticated user's notification preferences
+func (h *Handler) UpdatePreferences(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+		return
+	}
+
+	var req struct {
+		Preferences *models.NotificationPreference `json:"preferences"`
+		Channels    []models.ChannelPreference     `json:"channels"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.UpdatePreferences(c.Request.Context(), uuid, req.Preferences, req.Channels); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Notification preferences updated successfully",
+	})
+}
+
+// ListHistory returns the authenticated user's notification delivery history
+func (h *Handler) ListHistory(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+		return
+	}
+
+	limitStr := c.DefaultQuery("limit", "10")
+	offsetStr := c.DefaultQuery("offset", "0")
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		limit = 10
+	} else if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	offset, err := strconv.Atoi(offsetStr)
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	history, err := h.service.GetHistory(c.Request.Context(), uuid, c.Query("type"), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    history,
+	})
+}
+
+// ListInbox returns the authenticated user's in-app notifications
+func (h *Handler) ListInbox(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+		return
+	}
+
+	limitStr := c.DefaultQuery("limit", "10")
+	offsetStr := c.DefaultQuery("offset", "0")
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		limit = 10
+	} else if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	offset, err := strconv.Atoi(offsetStr)
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	unreadOnly := c.Query("unread") == "true"
+
+	notifications, err := h.service.ListInbox(c.Request.Context(), uuid, unreadOnly, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    notifications,
+	})
+}
+
+// CountUnread returns the number of unread in-app notifications
+func (h *Handler) CountUnread(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+		return
+	}
+
+	count, err := h.service.CountUnread(c.Request.Context(), uuid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    gin.H{"unread": count},
+	})
+}
+
+// MarkRead marks a single in-app notification as read
+func (h *Handler) MarkRead(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	if err := h.service.MarkRead(c.Request.Context(), uuid, uint(id)); err != nil {
+		if errors.Is(err, notification.ErrInboxNotificationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Notification marked as read",
+	})
+}
+
+// MarkAllRead marks all of the user's in-app notifications as read
+func (h *Handler) MarkAllRead(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+		return
+	}
+
+	if err := h.service.MarkAllRead(c.Request.Context(), uuid); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "All notifications marked as read",
+	})
+}
+
+// DeleteInboxNotification removes an in-app notification
+func (h *Handler) DeleteInboxNotification(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	if err := h.service.DeleteInboxNotification(c.Request.Context(), uuid, uint(id)); err != nil {
+		if errors.Is(err, notification.ErrInboxNotificationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Notification deleted successfully",
+	})
+}
+
+// Stream pushes the authenticated user's real-time events over Server-Sent Events
+func (h *Handler) Stream(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+		return
+	}
+
+	events, cancel := h.service.Subscribe(uuid)
+	defer cancel()
+
+	heartbeat := time.NewTicker(30 * time.Second)
+	defer heartbeat.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Type, event)
+			return true
+		case <-heartbeat.C:
+			c.SSEvent("ping", gin.H{"time": time.Now().Format(time.RFC3339)})
+			return true
+		}
+	})
+}
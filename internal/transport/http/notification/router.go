@@ -8,23 +8,52 @@ import (
 
 	"github.com/johnroshan2255/core-service/internal/notification"
 	"github.com/johnroshan2255/core-service/internal/config"
+	"github.com/johnroshan2255/core-service/internal/middleware"
+	notificationv1 "github.com/johnroshan2255/core-service/proto/notification/v1"
 )
 
-// SetupRoutes adds notification routes to the provided router
-func SetupRoutes(router *gin.Engine, notificationService *notification.NotificationService) {
+// SetupRoutes adds notification routes to the provided router. Service routes accept
+// the same credentials as the gRPC server, checked against the gRPC method they mirror.
+func SetupRoutes(router *gin.Engine, cfg *config.Config, notificationService *notification.NotificationService, serviceCredentials *middleware.ServiceCredentials) {
 	notificationHandler := NewHandler(notificationService)
+	serviceAuth := func(method string) gin.HandlerFunc {
+		return middleware.ServiceAuthMiddleware(serviceCredentials, method, cfg.ServiceSigningSecret, cfg.ServiceSignatureMaxSkew)
+	}
 
 	api := router.Group("/api/v1")
 	{
 		notifications := api.Group("/notifications")
 		{
-			notifications.POST("/user-created", notificationHandler.HandleUserCreated)
+			notifications.GET("", middleware.AuthMiddleware(), notificationHandler.ListHistory)
+			notifications.GET("/stream", middleware.AuthMiddleware(), notificationHandler.Stream)
+			notifications.POST("/user-created", serviceAuth(notificationv1.NotificationService_NotifyUserCreated_FullMethodName), notificationHandler.HandleUserCreated)
+			notifications.POST("/document-expiry", serviceAuth(notificationv1.NotificationService_NotifyDocumentExpiry_FullMethodName), notificationHandler.HandleDocumentExpiry)
+			notifications.GET("/unsubscribe", notificationHandler.UnsubscribePage)
+			notifications.POST("/unsubscribe", notificationHandler.Unsubscribe)
+			notifications.POST("/email-events", requireWebhookSecret(cfg.EmailEventsSecret), notificationHandler.HandleEmailEvents)
+
+			preferences := notifications.Group("/preferences")
+			preferences.Use(middleware.AuthMiddleware())
+			{
+				preferences.GET("", notificationHandler.GetPreferences)
+				preferences.PUT("", notificationHandler.UpdatePreferences)
+			}
+
+			inbox := notifications.Group("/inbox")
+			inbox.Use(middleware.AuthMiddleware())
+			{
+				inbox.GET("", notificationHandler.ListInbox)
+				inbox.GET("/unread-count", notificationHandler.CountUnread)
+				inbox.POST("/read-all", notificationHandler.MarkAllRead)
+				inbox.POST("/:id/read", notificationHandler.MarkRead)
+				inbox.DELETE("/:id", notificationHandler.DeleteInboxNotification)
+			}
 		}
 	}
 }
 
 // SetupRouter creates and configures the HTTP router with notification routes
-func SetupRouter(notificationService *notification.NotificationService) *gin.Engine {
+func SetupRouter(cfg *config.Config, notificationService *notification.NotificationService, serviceCredentials *middleware.ServiceCredentials) *gin.Engine {
 	if os.Getenv("GIN_MODE") == "" {
 		gin.SetMode(gin.ReleaseMode)
 	}
@@ -33,14 +62,14 @@ func SetupRouter(notificationService *notification.NotificationService) *gin.Eng
 
 	router.SetTrustedProxies([]string{})
 
-	SetupRoutes(router, notificationService)
+	SetupRoutes(router, cfg, notificationService, serviceCredentials)
 
 	return router
 }
 
 // StartHTTPServer starts the HTTP server for notification service
-func StartHTTPServer(cfg *config.Config, service *notification.NotificationService) {
-	router := SetupRouter(service)
+func StartHTTPServer(cfg *config.Config, service *notification.NotificationService, serviceCredentials *middleware.ServiceCredentials) {
+	router := SetupRouter(cfg, service, serviceCredentials)
 
 	port := cfg.Port
 	if port == "" {
@@ -0,0 +1,125 @@
+package notification
+
+import (
+	"crypto/subtle"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/johnroshan2255/core-service/internal/notification"
+)
+
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Unsubscribe</title></head>
+<body>
+{{if .Error}}<p>{{.Error}}</p>
+{{else if .Done}}<p>You have been unsubscribed from {{.Category}} emails.</p>
+{{else}}<form method="post">
+<p>Stop receiving {{.Category}} emails?</p>
+<input type="hidden" name="token" value="{{.Token}}">
+<button type="submit">Unsubscribe</button>
+</form>
+{{end}}</body>
+</html>
+`))
+
+type unsubscribeView struct {
+	Token    string
+	Category string
+	Done     bool
+	Error    string
+}
+
+// UnsubscribePage shows a confirmation form. It doesn't unsubscribe by itself because
+// mail scanners follow links in emails.
+func (h *Handler) UnsubscribePage(c *gin.Context) {
+	token := c.Query("token")
+	_, category, err := h.service.ParseUnsubscribeToken(token)
+	if err != nil {
+		renderUnsubscribe(c, http.StatusBadRequest, unsubscribeView{Error: "This unsubscribe link is invalid."})
+		return
+	}
+
+	renderUnsubscribe(c, http.StatusOK, unsubscribeView{Token: token, Category: categoryLabel(category)})
+}
+
+// Unsubscribe handles both the confirmation form and RFC 8058 one-click requests
+// sent by mail clients
+func (h *Handler) Unsubscribe(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		token = c.PostForm("token")
+	}
+
+	category, err := h.service.Unsubscribe(c.Request.Context(), token)
+	if err != nil {
+		log.Printf("NotificationHandler: Unsubscribe failed: %v", err)
+		renderUnsubscribe(c, http.StatusBadRequest, unsubscribeView{Error: "We couldn't unsubscribe you with this link."})
+		return
+	}
+
+	renderUnsubscribe(c, http.StatusOK, unsubscribeView{Category: categoryLabel(category), Done: true})
+}
+
+func categoryLabel(category string) string {
+	return strings.ReplaceAll(category, "_", " ")
+}
+
+func renderUnsubscribe(c *gin.Context, status int, view unsubscribeView) {
+	c.Status(status)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := unsubscribePage.Execute(c.Writer, view); err != nil {
+		log.Printf("NotificationHandler: Failed to render unsubscribe page: %v", err)
+	}
+}
+
+// HandleEmailEvents ingests bounce and complaint events from the mail provider
+func (h *Handler) HandleEmailEvents(c *gin.Context) {
+	var req struct {
+		Events []notification.EmailEvent `json:"events" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	processed := 0
+	for _, event := range req.Events {
+		if err := h.service.HandleEmailEvent(c.Request.Context(), event); err != nil {
+			log.Printf("NotificationHandler: Failed to handle %s event for %s: %v", event.Type, event.Email, err)
+			continue
+		}
+		processed++
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"received":  len(req.Events),
+			"processed": processed,
+		},
+	})
+}
+
+// requireWebhookSecret rejects requests whose X-Webhook-Secret header doesn't match
+// secret. Without a configured secret the endpoint is disabled.
+func requireWebhookSecret(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if secret == "" {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Email event ingestion is not configured"})
+			c.Abort()
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Webhook-Secret")), []byte(secret)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid webhook secret"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -1,14 +1,20 @@
 package http
 
 import (
+	"expvar"
 	"os"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/johnroshan2255/core-service/internal/auth"
 	"github.com/johnroshan2255/core-service/internal/config"
 	"github.com/johnroshan2255/core-service/internal/document/service"
+	"github.com/johnroshan2255/core-service/internal/invoice"
+	"github.com/johnroshan2255/core-service/internal/middleware"
 	"github.com/johnroshan2255/core-service/internal/notification"
+	authhttp "github.com/johnroshan2255/core-service/internal/transport/http/auth"
 	documenthttp "github.com/johnroshan2255/core-service/internal/transport/http/document"
+	invoicehttp "github.com/johnroshan2255/core-service/internal/transport/http/invoice"
 	notificationhttp "github.com/johnroshan2255/core-service/internal/transport/http/notification"
 	userhttp "github.com/johnroshan2255/core-service/internal/transport/http/user"
 	userservice "github.com/johnroshan2255/core-service/internal/user/service"
@@ -18,6 +24,11 @@ type Services struct {
 	NotificationService *notification.NotificationService
 	UserService         *userservice.Service
 	DocumentService     *service.Service
+	AuthService         *auth.Service
+	InvoiceService      *invoice.Service
+
+	// ServiceCredentials authenticates backend services on the service routes
+	ServiceCredentials *middleware.ServiceCredentials
 }
 
 func SetupRouter(cfg *config.Config, services *Services) *gin.Engine {
@@ -36,8 +47,13 @@ func SetupRouter(cfg *config.Config, services *Services) *gin.Engine {
 		})
 	}
 
+	if cfg.MetricsEnabled {
+		// Provider health and counters are operational details, so only admins may read them
+		router.GET("/debug/vars", middleware.AuthMiddleware(), middleware.RequirePermission(auth.PermissionMetricsRead), gin.WrapH(expvar.Handler()))
+	}
+
 	if services.NotificationService != nil {
-		notificationhttp.SetupRoutes(router, services.NotificationService)
+		notificationhttp.SetupRoutes(router, cfg, services.NotificationService, services.ServiceCredentials)
 	}
 
 	if services.UserService != nil {
@@ -45,7 +61,15 @@ func SetupRouter(cfg *config.Config, services *Services) *gin.Engine {
 	}
 
 	if services.DocumentService != nil {
-		documenthttp.SetupRoutes(router, services.DocumentService)
+		documenthttp.SetupRoutes(router, services.DocumentService, cfg.PublicBaseURL)
+	}
+
+	if services.AuthService != nil {
+		authhttp.SetupRoutes(router, services.AuthService)
+	}
+
+	if services.InvoiceService != nil {
+		invoicehttp.SetupRoutes(router, services.InvoiceService)
 	}
 
 	return router
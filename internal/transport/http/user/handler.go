@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/johnroshan2255/core-service/internal/middleware"
 	"github.com/johnroshan2255/core-service/internal/user/models"
 	"github.com/johnroshan2255/core-service/internal/user/service"
 )
@@ -21,15 +22,9 @@ func NewHandler(userService *service.Service) *Handler {
 }
 
 func (h *Handler) GetProfile(c *gin.Context) {
-	userUUID, exists := c.Get("user_uuid")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
-		return
-	}
-
-	uuid, ok := userUUID.(string)
+	uuid, ok := middleware.UserUUID(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user UUID format"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
 		return
 	}
 
@@ -45,16 +40,34 @@ func (h *Handler) GetProfile(c *gin.Context) {
 	})
 }
 
-func (h *Handler) UpdateProfile(c *gin.Context) {
-	userUUID, exists := c.Get("user_uuid")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
+// ListUsers returns all users. The route requires the users:read permission.
+func (h *Handler) ListUsers(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	users, err := h.service.ListUsers(c.Request.Context(), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	uuid, ok := userUUID.(string)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    users,
+	})
+}
+
+func (h *Handler) UpdateProfile(c *gin.Context) {
+	uuid, ok := middleware.UserUUID(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user UUID format"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
 		return
 	}
 
@@ -96,15 +109,9 @@ func (h *Handler) UpdateProfile(c *gin.Context) {
 }
 
 func (h *Handler) GetCompanyDetails(c *gin.Context) {
-	userUUID, exists := c.Get("user_uuid")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
-		return
-	}
-
-	uuid, ok := userUUID.(string)
+	uuid, ok := middleware.UserUUID(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user UUID format"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
 		return
 	}
 
@@ -121,15 +128,9 @@ func (h *Handler) GetCompanyDetails(c *gin.Context) {
 }
 
 func (h *Handler) UpdateCompanyDetails(c *gin.Context) {
-	userUUID, exists := c.Get("user_uuid")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
-		return
-	}
-
-	uuid, ok := userUUID.(string)
+	uuid, ok := middleware.UserUUID(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user UUID format"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
 		return
 	}
 
@@ -151,15 +152,9 @@ func (h *Handler) UpdateCompanyDetails(c *gin.Context) {
 }
 
 func (h *Handler) GetPaymentDetails(c *gin.Context) {
-	userUUID, exists := c.Get("user_uuid")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
-		return
-	}
-
-	uuid, ok := userUUID.(string)
+	uuid, ok := middleware.UserUUID(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user UUID format"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
 		return
 	}
 
@@ -176,15 +171,9 @@ func (h *Handler) GetPaymentDetails(c *gin.Context) {
 }
 
 func (h *Handler) UpdatePaymentDetails(c *gin.Context) {
-	userUUID, exists := c.Get("user_uuid")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
-		return
-	}
-
-	uuid, ok := userUUID.(string)
+	uuid, ok := middleware.UserUUID(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user UUID format"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
 		return
 	}
 
@@ -206,15 +195,9 @@ func (h *Handler) UpdatePaymentDetails(c *gin.Context) {
 }
 
 func (h *Handler) GetPaymentHistory(c *gin.Context) {
-	userUUID, exists := c.Get("user_uuid")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
-		return
-	}
-
-	uuid, ok := userUUID.(string)
+	uuid, ok := middleware.UserUUID(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user UUID format"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
 		return
 	}
 
@@ -244,15 +227,9 @@ func (h *Handler) GetPaymentHistory(c *gin.Context) {
 }
 
 func (h *Handler) CreatePaymentHistory(c *gin.Context) {
-	userUUID, exists := c.Get("user_uuid")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
-		return
-	}
-
-	uuid, ok := userUUID.(string)
+	uuid, ok := middleware.UserUUID(c)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user UUID format"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User UUID not found in token"})
 		return
 	}
 
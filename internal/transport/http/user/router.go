@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/johnroshan2255/core-service/internal/auth"
 	"github.com/johnroshan2255/core-service/internal/middleware"
 	"github.com/johnroshan2255/core-service/internal/user/service"
 	"github.com/johnroshan2255/core-service/internal/config"
@@ -28,10 +29,16 @@ func SetupRoutes(router *gin.Engine, userService *service.Service) {
 			users.PUT("/company", userHandler.UpdateCompanyDetails)
 
 			users.GET("/payment", userHandler.GetPaymentDetails)
-			users.PUT("/payment", userHandler.UpdatePaymentDetails)
+			users.PUT("/payment", middleware.RequireVerifiedEmail(), middleware.RequireAMR("mfa"), userHandler.UpdatePaymentDetails)
 
 			users.GET("/payments/history", userHandler.GetPaymentHistory)
-			users.POST("/payments/history", userHandler.CreatePaymentHistory)
+			users.POST("/payments/history", middleware.RequireVerifiedEmail(), userHandler.CreatePaymentHistory)
+		}
+
+		admin := api.Group("/admin/users")
+		admin.Use(middleware.AuthMiddleware(), middleware.RequirePermission(auth.PermissionUsersRead))
+		{
+			admin.GET("", userHandler.ListUsers)
 		}
 	}
 }
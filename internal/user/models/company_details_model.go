@@ -9,6 +9,7 @@ import (
 type CompanyDetails struct {
 	ID          uint           `gorm:"primaryKey" json:"id"`
 	UserUUID    string         `gorm:"type:uuid;uniqueIndex;not null" json:"user_uuid"`
+	TenantID    string         `gorm:"type:varchar(255);index;not null;default:''" json:"tenant_id"`
 	CompanyName string         `gorm:"type:varchar(255)" json:"company_name"`
 	Industry    string         `gorm:"type:varchar(100)" json:"industry"`
 	Website     string         `gorm:"type:varchar(255)" json:"website"`
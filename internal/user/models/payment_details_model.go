@@ -9,6 +9,7 @@ import (
 type PaymentDetails struct {
 	ID            uint           `gorm:"primaryKey" json:"id"`
 	UserUUID      string         `gorm:"type:uuid;uniqueIndex;not null" json:"user_uuid"`
+	TenantID      string         `gorm:"type:varchar(255);index;not null;default:''" json:"tenant_id"`
 	PaymentMethod string         `gorm:"type:varchar(50)" json:"payment_method"`
 	CardLast4     string         `gorm:"type:varchar(4)" json:"card_last4"`
 	CardBrand     string         `gorm:"type:varchar(50)" json:"card_brand"`
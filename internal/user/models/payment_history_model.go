@@ -9,6 +9,7 @@ import (
 type PaymentHistory struct {
 	ID            uint           `gorm:"primaryKey" json:"id"`
 	UserUUID      string         `gorm:"type:uuid;index;not null" json:"user_uuid"`
+	TenantID      string         `gorm:"type:varchar(255);index;not null;default:''" json:"tenant_id"`
 	TransactionID string         `gorm:"type:varchar(255);uniqueIndex" json:"transaction_id"`
 	Amount        float64        `gorm:"type:decimal(10,2);not null" json:"amount"`
 	Currency      string         `gorm:"type:varchar(3);default:'USD'" json:"currency"`
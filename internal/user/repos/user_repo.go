@@ -2,17 +2,29 @@ package repos
 
 import (
 	"context"
+	"errors"
 
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/johnroshan2255/core-service/internal/tenant"
 	"github.com/johnroshan2255/core-service/internal/user/models"
 	"gorm.io/gorm"
 )
 
+// uniqueViolation is the Postgres error code for a unique index violation
+const uniqueViolation = "23505"
+
+var (
+	ErrEmailExists    = errors.New("a user with this email already exists")
+	ErrUsernameExists = errors.New("a user with this username already exists")
+)
+
 type Repository interface {
 	GetByUUID(ctx context.Context, userUUID string) (*models.User, error)
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
 	Create(ctx context.Context, user *models.User) error
 	Update(ctx context.Context, user *models.User) error
 	Delete(ctx context.Context, userUUID string) error
+	List(ctx context.Context, limit, offset int) ([]models.User, error)
 
 	GetCompanyDetails(ctx context.Context, userUUID string) (*models.CompanyDetails, error)
 	UpdateCompanyDetails(ctx context.Context, company *models.CompanyDetails) error
@@ -50,8 +62,34 @@ func (r *GORMRepository) GetByEmail(ctx context.Context, email string) (*models.
 	return &user, nil
 }
 
+// UserTenant returns the tenant userUUID belongs to, or the empty tenant for a user
+// this service doesn't know. It looks across tenants, for callers that don't have one.
+func (r *GORMRepository) UserTenant(ctx context.Context, userUUID string) (string, error) {
+	var user models.User
+	err := r.db.WithContext(tenant.Unscoped(ctx)).Select("tenant_id").Where("uuid = ?", userUUID).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return user.TenantID, nil
+}
+
+// Create inserts user. A taken email or username is reported by the unique indexes as
+// ErrEmailExists or ErrUsernameExists, which also covers concurrent registrations.
 func (r *GORMRepository) Create(ctx context.Context, user *models.User) error {
-	return r.db.WithContext(ctx).Create(user).Error
+	err := r.db.WithContext(ctx).Create(user).Error
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		switch pgErr.ConstraintName {
+		case "idx_users_email":
+			return ErrEmailExists
+		case "idx_users_username":
+			return ErrUsernameExists
+		}
+	}
+	return err
 }
 
 func (r *GORMRepository) Update(ctx context.Context, user *models.User) error {
@@ -62,6 +100,21 @@ func (r *GORMRepository) Delete(ctx context.Context, userUUID string) error {
 	return r.db.WithContext(ctx).Where("uuid = ?", userUUID).Delete(&models.User{}).Error
 }
 
+func (r *GORMRepository) List(ctx context.Context, limit, offset int) ([]models.User, error) {
+	var users []models.User
+	query := r.db.WithContext(ctx).Order("created_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+	if err := query.Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 func (r *GORMRepository) GetCompanyDetails(ctx context.Context, userUUID string) (*models.CompanyDetails, error) {
 	var company models.CompanyDetails
 	if err := r.db.WithContext(ctx).Where("user_uuid = ?", userUUID).First(&company).Error; err != nil {
@@ -0,0 +1,105 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/johnroshan2255/core-service/internal/tenant"
+	"github.com/johnroshan2255/core-service/internal/tenant/tenanttest"
+	"github.com/johnroshan2255/core-service/internal/user/models"
+	"gorm.io/gorm"
+)
+
+func newTestRepository(t *testing.T) (*GORMRepository, *gorm.DB) {
+	db := tenanttest.Open(t,
+		&models.User{},
+		&models.CompanyDetails{},
+		&models.PaymentDetails{},
+		&models.PaymentHistory{},
+	)
+	return NewGORMRepository(db), db
+}
+
+func TestUsersAreIsolatedByTenant(t *testing.T) {
+	repo, db := newTestRepository(t)
+
+	user := &models.User{UUID: tenanttest.OwnerUUID, Email: "owner@example.com", Username: "owner", PasswordHash: "hash", FirstName: "Owner"}
+	if err := repo.Create(tenanttest.TenantB, user); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if _, err := repo.GetByUUID(tenanttest.TenantA, tenanttest.OwnerUUID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("GetByUUID from another tenant: got %v, want ErrRecordNotFound", err)
+	}
+	if _, err := repo.GetByEmail(tenanttest.TenantA, "owner@example.com"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("GetByEmail from another tenant: got %v, want ErrRecordNotFound", err)
+	}
+	if users, err := repo.List(tenanttest.TenantA, 0, 0); err != nil || len(users) != 0 {
+		t.Fatalf("List from another tenant: got %d users, %v", len(users), err)
+	}
+
+	tenanttest.AssertWritesIsolated(t, db, []string{"users"},
+		tenanttest.CrossTenantWrite{Name: "Update", Write: func(ctx context.Context) error {
+			return repo.Update(ctx, &models.User{UUID: tenanttest.OwnerUUID, FirstName: "Hijacked"})
+		}},
+		tenanttest.CrossTenantWrite{Name: "Delete", Write: func(ctx context.Context) error { return repo.Delete(ctx, tenanttest.OwnerUUID) }},
+	)
+}
+
+func TestUserDetailsAreIsolatedByTenant(t *testing.T) {
+	repo, db := newTestRepository(t)
+
+	if err := repo.UpdateCompanyDetails(tenanttest.TenantB, &models.CompanyDetails{UserUUID: tenanttest.OwnerUUID, CompanyName: "Owner Ltd"}); err != nil {
+		t.Fatalf("create company: %v", err)
+	}
+	if err := repo.UpdatePaymentDetails(tenanttest.TenantB, &models.PaymentDetails{UserUUID: tenanttest.OwnerUUID, BillingName: "Owner"}); err != nil {
+		t.Fatalf("create payment details: %v", err)
+	}
+	if err := repo.CreatePaymentHistory(tenanttest.TenantB, &models.PaymentHistory{UserUUID: tenanttest.OwnerUUID, TransactionID: "tx-1", Amount: 10, Status: "paid"}); err != nil {
+		t.Fatalf("create payment history: %v", err)
+	}
+
+	if _, err := repo.GetCompanyDetails(tenanttest.TenantA, tenanttest.OwnerUUID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("GetCompanyDetails from another tenant: got %v, want ErrRecordNotFound", err)
+	}
+	if _, err := repo.GetPaymentDetails(tenanttest.TenantA, tenanttest.OwnerUUID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("GetPaymentDetails from another tenant: got %v, want ErrRecordNotFound", err)
+	}
+	if history, err := repo.GetPaymentHistory(tenanttest.TenantA, tenanttest.OwnerUUID, 0, 0); err != nil || len(history) != 0 {
+		t.Fatalf("GetPaymentHistory from another tenant: got %d rows, %v", len(history), err)
+	}
+
+	// Another tenant doesn't see the owner's row, so it tries to insert its own and
+	// fails on the unique user_uuid
+	tenanttest.AssertWritesIsolated(t, db, []string{"company_details", "payment_details"},
+		tenanttest.CrossTenantWrite{Name: "UpdateCompanyDetails", WantErr: true, Write: func(ctx context.Context) error {
+			return repo.UpdateCompanyDetails(ctx, &models.CompanyDetails{UserUUID: tenanttest.OwnerUUID, CompanyName: "Hijacked"})
+		}},
+		tenanttest.CrossTenantWrite{Name: "UpdatePaymentDetails", WantErr: true, Write: func(ctx context.Context) error {
+			return repo.UpdatePaymentDetails(ctx, &models.PaymentDetails{UserUUID: tenanttest.OwnerUUID, BillingName: "Hijacked"})
+		}},
+	)
+	if history, err := repo.GetPaymentHistory(tenanttest.TenantB, tenanttest.OwnerUUID, 0, 0); err != nil || len(history) != 1 {
+		t.Fatalf("owner's payment history: got %d rows, %v", len(history), err)
+	}
+}
+
+func TestUserTenantLooksAcrossTenants(t *testing.T) {
+	repo, _ := newTestRepository(t)
+
+	if tenantID, err := repo.UserTenant(context.Background(), tenanttest.OwnerUUID); err != nil || tenantID != "" {
+		t.Fatalf("UserTenant for an unknown user = %q, %v, want the empty tenant", tenantID, err)
+	}
+
+	user := &models.User{UUID: tenanttest.OwnerUUID, Email: "owner@example.com", Username: "owner", PasswordHash: "hash"}
+	if err := repo.Create(tenant.Unscoped(context.Background()), user); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := repo.Update(tenant.Unscoped(context.Background()), &models.User{UUID: tenanttest.OwnerUUID, TenantID: "tenant-b"}); err != nil {
+		t.Fatalf("assign tenant: %v", err)
+	}
+	if tenantID, err := repo.UserTenant(tenanttest.TenantA, tenanttest.OwnerUUID); err != nil || tenantID != "tenant-b" {
+		t.Fatalf("UserTenant = %q, %v, want tenant-b", tenantID, err)
+	}
+}
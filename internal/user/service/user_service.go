@@ -70,6 +70,16 @@ func (s *Service) UpdateProfile(ctx context.Context, userUUID string, updates ma
 	return nil
 }
 
+// ListUsers returns every user, newest first. Callers must check the caller may see them.
+func (s *Service) ListUsers(ctx context.Context, limit, offset int) ([]models.User, error) {
+	users, err := s.repo.List(ctx, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return users, nil
+}
+
 func (s *Service) GetCompanyDetails(ctx context.Context, userUUID string) (*models.CompanyDetails, error) {
 	if userUUID == "" {
 		return nil, fmt.Errorf("user UUID is required")
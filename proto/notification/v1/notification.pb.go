@@ -9,6 +9,7 @@ package notificationv1
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -23,12 +24,13 @@ const (
 
 // UserCreatedRequest contains information about a newly created user
 type UserCreatedRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserUuid      string                 `protobuf:"bytes,1,opt,name=user_uuid,json=userUuid,proto3" json:"user_uuid,omitempty"` // UUID of the created user
-	Email         string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`                       // User's email address
-	Username      string                 `protobuf:"bytes,3,opt,name=username,proto3" json:"username,omitempty"`                 // User's username
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	UserUuid       string                 `protobuf:"bytes,1,opt,name=user_uuid,json=userUuid,proto3" json:"user_uuid,omitempty"`                   // UUID of the created user
+	Email          string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`                                         // User's email address
+	Username       string                 `protobuf:"bytes,3,opt,name=username,proto3" json:"username,omitempty"`                                   // User's username
+	IdempotencyKey string                 `protobuf:"bytes,4,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"` // Optional key; repeated calls with the same key return the original result
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *UserCreatedRequest) Reset() {
@@ -82,6 +84,13 @@ func (x *UserCreatedRequest) GetUsername() string {
 	return ""
 }
 
+func (x *UserCreatedRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
 // UserCreatedResponse confirms the notification was processed
 type UserCreatedResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -135,6 +144,67 @@ func (x *UserCreatedResponse) GetMessage() string {
 	return ""
 }
 
+// Attachment is a file sent along with a notification
+type Attachment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`                          // File name shown to the recipient
+	ContentType   string                 `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"` // MIME type of the file (defaults to application/octet-stream)
+	Content       []byte                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`                            // Raw file contents
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Attachment) Reset() {
+	*x = Attachment{}
+	mi := &file_notification_v1_notification_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Attachment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Attachment) ProtoMessage() {}
+
+func (x *Attachment) ProtoReflect() protoreflect.Message {
+	mi := &file_notification_v1_notification_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Attachment.ProtoReflect.Descriptor instead.
+func (*Attachment) Descriptor() ([]byte, []int) {
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Attachment) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *Attachment) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *Attachment) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
 // DocumentExpiryRequest contains information about document expiry
 type DocumentExpiryRequest struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
@@ -146,13 +216,15 @@ type DocumentExpiryRequest struct {
 	DaysUntilExpiry  int32                  `protobuf:"varint,6,opt,name=days_until_expiry,json=daysUntilExpiry,proto3" json:"days_until_expiry,omitempty"` // Days until expiry (negative if expired)
 	IsExpired        bool                   `protobuf:"varint,7,opt,name=is_expired,json=isExpired,proto3" json:"is_expired,omitempty"`                     // Whether the document has already expired
 	Message          string                 `protobuf:"bytes,8,opt,name=message,proto3" json:"message,omitempty"`                                           // Custom message about the expiry
+	IdempotencyKey   string                 `protobuf:"bytes,9,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`       // Optional key; repeated calls with the same key return the original result
+	Attachments      []*Attachment          `protobuf:"bytes,10,rep,name=attachments,proto3" json:"attachments,omitempty"`                                  // Files sent with the notification, e.g. the document itself
 	unknownFields    protoimpl.UnknownFields
 	sizeCache        protoimpl.SizeCache
 }
 
 func (x *DocumentExpiryRequest) Reset() {
 	*x = DocumentExpiryRequest{}
-	mi := &file_notification_v1_notification_proto_msgTypes[2]
+	mi := &file_notification_v1_notification_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -164,7 +236,7 @@ func (x *DocumentExpiryRequest) String() string {
 func (*DocumentExpiryRequest) ProtoMessage() {}
 
 func (x *DocumentExpiryRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_notification_v1_notification_proto_msgTypes[2]
+	mi := &file_notification_v1_notification_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -177,7 +249,7 @@ func (x *DocumentExpiryRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DocumentExpiryRequest.ProtoReflect.Descriptor instead.
 func (*DocumentExpiryRequest) Descriptor() ([]byte, []int) {
-	return file_notification_v1_notification_proto_rawDescGZIP(), []int{2}
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *DocumentExpiryRequest) GetUserUuid() string {
@@ -236,6 +308,20 @@ func (x *DocumentExpiryRequest) GetMessage() string {
 	return ""
 }
 
+func (x *DocumentExpiryRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *DocumentExpiryRequest) GetAttachments() []*Attachment {
+	if x != nil {
+		return x.Attachments
+	}
+	return nil
+}
+
 // DocumentExpiryResponse confirms the notification was processed
 type DocumentExpiryResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -247,7 +333,7 @@ type DocumentExpiryResponse struct {
 
 func (x *DocumentExpiryResponse) Reset() {
 	*x = DocumentExpiryResponse{}
-	mi := &file_notification_v1_notification_proto_msgTypes[3]
+	mi := &file_notification_v1_notification_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -259,7 +345,7 @@ func (x *DocumentExpiryResponse) String() string {
 func (*DocumentExpiryResponse) ProtoMessage() {}
 
 func (x *DocumentExpiryResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_notification_v1_notification_proto_msgTypes[3]
+	mi := &file_notification_v1_notification_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -272,7 +358,7 @@ func (x *DocumentExpiryResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DocumentExpiryResponse.ProtoReflect.Descriptor instead.
 func (*DocumentExpiryResponse) Descriptor() ([]byte, []int) {
-	return file_notification_v1_notification_proto_rawDescGZIP(), []int{3}
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *DocumentExpiryResponse) GetSuccess() bool {
@@ -289,35 +375,1678 @@ func (x *DocumentExpiryResponse) GetMessage() string {
 	return ""
 }
 
-var File_notification_v1_notification_proto protoreflect.FileDescriptor
+// DigestDocument describes one document included in a digest
+type DigestDocument struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	DocumentName     string                 `protobuf:"bytes,1,opt,name=document_name,json=documentName,proto3" json:"document_name,omitempty"`             // Name of the document
+	DocumentCategory string                 `protobuf:"bytes,2,opt,name=document_category,json=documentCategory,proto3" json:"document_category,omitempty"` // Category of the document (warranty, pollution_certificate, etc.)
+	ExpiryDate       string                 `protobuf:"bytes,3,opt,name=expiry_date,json=expiryDate,proto3" json:"expiry_date,omitempty"`                   // Expiry date in ISO format
+	DaysUntilExpiry  int32                  `protobuf:"varint,4,opt,name=days_until_expiry,json=daysUntilExpiry,proto3" json:"days_until_expiry,omitempty"` // Days until expiry (negative if expired)
+	IsExpired        bool                   `protobuf:"varint,5,opt,name=is_expired,json=isExpired,proto3" json:"is_expired,omitempty"`                     // Whether the document has already expired
+	Attachments      []*Attachment          `protobuf:"bytes,6,rep,name=attachments,proto3" json:"attachments,omitempty"`                                   // Files sent with the digest, e.g. the document itself
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
 
-const file_notification_v1_notification_proto_rawDesc = "" +
-	"\n" +
-	"\"notification/v1/notification.proto\x12\x0fnotification.v1\"c\n" +
-	"\x12UserCreatedRequest\x12\x1b\n" +
-	"\tuser_uuid\x18\x01 \x01(\tR\buserUuid\x12\x14\n" +
-	"\x05email\x18\x02 \x01(\tR\x05email\x12\x1a\n" +
-	"\busername\x18\x03 \x01(\tR\busername\"I\n" +
-	"\x13UserCreatedResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"\xa2\x02\n" +
-	"\x15DocumentExpiryRequest\x12\x1b\n" +
-	"\tuser_uuid\x18\x01 \x01(\tR\buserUuid\x12\x14\n" +
-	"\x05email\x18\x02 \x01(\tR\x05email\x12#\n" +
-	"\rdocument_name\x18\x03 \x01(\tR\fdocumentName\x12+\n" +
-	"\x11document_category\x18\x04 \x01(\tR\x10documentCategory\x12\x1f\n" +
-	"\vexpiry_date\x18\x05 \x01(\tR\n" +
-	"expiryDate\x12*\n" +
-	"\x11days_until_expiry\x18\x06 \x01(\x05R\x0fdaysUntilExpiry\x12\x1d\n" +
-	"\n" +
-	"is_expired\x18\a \x01(\bR\tisExpired\x12\x18\n" +
-	"\amessage\x18\b \x01(\tR\amessage\"L\n" +
-	"\x16DocumentExpiryResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage2\xde\x01\n" +
+func (x *DigestDocument) Reset() {
+	*x = DigestDocument{}
+	mi := &file_notification_v1_notification_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DigestDocument) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DigestDocument) ProtoMessage() {}
+
+func (x *DigestDocument) ProtoReflect() protoreflect.Message {
+	mi := &file_notification_v1_notification_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DigestDocument.ProtoReflect.Descriptor instead.
+func (*DigestDocument) Descriptor() ([]byte, []int) {
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DigestDocument) GetDocumentName() string {
+	if x != nil {
+		return x.DocumentName
+	}
+	return ""
+}
+
+func (x *DigestDocument) GetDocumentCategory() string {
+	if x != nil {
+		return x.DocumentCategory
+	}
+	return ""
+}
+
+func (x *DigestDocument) GetExpiryDate() string {
+	if x != nil {
+		return x.ExpiryDate
+	}
+	return ""
+}
+
+func (x *DigestDocument) GetDaysUntilExpiry() int32 {
+	if x != nil {
+		return x.DaysUntilExpiry
+	}
+	return 0
+}
+
+func (x *DigestDocument) GetIsExpired() bool {
+	if x != nil {
+		return x.IsExpired
+	}
+	return false
+}
+
+func (x *DigestDocument) GetAttachments() []*Attachment {
+	if x != nil {
+		return x.Attachments
+	}
+	return nil
+}
+
+// DocumentDigestRequest groups a user's expiring and expired documents
+type DocumentDigestRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	UserUuid       string                 `protobuf:"bytes,1,opt,name=user_uuid,json=userUuid,proto3" json:"user_uuid,omitempty"`                   // UUID of the document owner
+	Email          string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`                                         // User's email address
+	Documents      []*DigestDocument      `protobuf:"bytes,3,rep,name=documents,proto3" json:"documents,omitempty"`                                 // Documents to include in the digest
+	IdempotencyKey string                 `protobuf:"bytes,4,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"` // Optional key; repeated calls with the same key return the original result
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *DocumentDigestRequest) Reset() {
+	*x = DocumentDigestRequest{}
+	mi := &file_notification_v1_notification_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DocumentDigestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DocumentDigestRequest) ProtoMessage() {}
+
+func (x *DocumentDigestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_notification_v1_notification_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DocumentDigestRequest.ProtoReflect.Descriptor instead.
+func (*DocumentDigestRequest) Descriptor() ([]byte, []int) {
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DocumentDigestRequest) GetUserUuid() string {
+	if x != nil {
+		return x.UserUuid
+	}
+	return ""
+}
+
+func (x *DocumentDigestRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *DocumentDigestRequest) GetDocuments() []*DigestDocument {
+	if x != nil {
+		return x.Documents
+	}
+	return nil
+}
+
+func (x *DocumentDigestRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+// DocumentDigestResponse confirms the digest was processed
+type DocumentDigestResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"` // Whether the notification was sent successfully
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`  // Optional message about the notification
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DocumentDigestResponse) Reset() {
+	*x = DocumentDigestResponse{}
+	mi := &file_notification_v1_notification_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DocumentDigestResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DocumentDigestResponse) ProtoMessage() {}
+
+func (x *DocumentDigestResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_notification_v1_notification_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DocumentDigestResponse.ProtoReflect.Descriptor instead.
+func (*DocumentDigestResponse) Descriptor() ([]byte, []int) {
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DocumentDigestResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *DocumentDigestResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// RecipientRef identifies who a generic notification is addressed to
+type RecipientRef struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserUuid      string                 `protobuf:"bytes,1,opt,name=user_uuid,json=userUuid,proto3" json:"user_uuid,omitempty"` // UUID of the recipient user
+	Email         string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`                       // Recipient's email address (required for the email channel)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecipientRef) Reset() {
+	*x = RecipientRef{}
+	mi := &file_notification_v1_notification_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecipientRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecipientRef) ProtoMessage() {}
+
+func (x *RecipientRef) ProtoReflect() protoreflect.Message {
+	mi := &file_notification_v1_notification_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecipientRef.ProtoReflect.Descriptor instead.
+func (*RecipientRef) Descriptor() ([]byte, []int) {
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RecipientRef) GetUserUuid() string {
+	if x != nil {
+		return x.UserUuid
+	}
+	return ""
+}
+
+func (x *RecipientRef) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+// SendNotificationRequest is a generic, schema-validated notification
+type SendNotificationRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	EventType      string                 `protobuf:"bytes,1,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`                // Registered event type (user_created, document_expiry, etc.)
+	Recipient      *RecipientRef          `protobuf:"bytes,2,opt,name=recipient,proto3" json:"recipient,omitempty"`                                 // Recipient of the notification
+	Locale         string                 `protobuf:"bytes,3,opt,name=locale,proto3" json:"locale,omitempty"`                                       // Preferred locale for templates, e.g. en or en-US
+	Payload        *structpb.Struct       `protobuf:"bytes,4,opt,name=payload,proto3" json:"payload,omitempty"`                                     // Event data validated against the event schema
+	IdempotencyKey string                 `protobuf:"bytes,5,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"` // Optional key; repeated calls with the same key return the original result
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SendNotificationRequest) Reset() {
+	*x = SendNotificationRequest{}
+	mi := &file_notification_v1_notification_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendNotificationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendNotificationRequest) ProtoMessage() {}
+
+func (x *SendNotificationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_notification_v1_notification_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendNotificationRequest.ProtoReflect.Descriptor instead.
+func (*SendNotificationRequest) Descriptor() ([]byte, []int) {
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SendNotificationRequest) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *SendNotificationRequest) GetRecipient() *RecipientRef {
+	if x != nil {
+		return x.Recipient
+	}
+	return nil
+}
+
+func (x *SendNotificationRequest) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+func (x *SendNotificationRequest) GetPayload() *structpb.Struct {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *SendNotificationRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+// SendNotificationResponse confirms the notification was processed
+type SendNotificationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"` // Whether the notification was sent successfully
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`  // Optional message about the notification
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendNotificationResponse) Reset() {
+	*x = SendNotificationResponse{}
+	mi := &file_notification_v1_notification_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendNotificationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendNotificationResponse) ProtoMessage() {}
+
+func (x *SendNotificationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_notification_v1_notification_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendNotificationResponse.ProtoReflect.Descriptor instead.
+func (*SendNotificationResponse) Descriptor() ([]byte, []int) {
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SendNotificationResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *SendNotificationResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// ListDeadLettersRequest pages through dead-lettered notifications
+type ListDeadLettersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         int32                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`   // Maximum number of results (default 50)
+	Offset        int32                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"` // Number of results to skip
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDeadLettersRequest) Reset() {
+	*x = ListDeadLettersRequest{}
+	mi := &file_notification_v1_notification_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDeadLettersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDeadLettersRequest) ProtoMessage() {}
+
+func (x *ListDeadLettersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_notification_v1_notification_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDeadLettersRequest.ProtoReflect.Descriptor instead.
+func (*ListDeadLettersRequest) Descriptor() ([]byte, []int) {
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListDeadLettersRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListDeadLettersRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+// DeadLetter is a notification delivery that exhausted its attempts
+type DeadLetter struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`                                                    // Outbox message ID
+	NotificationType string                 `protobuf:"bytes,2,opt,name=notification_type,json=notificationType,proto3" json:"notification_type,omitempty"` // Notification type (user_created, document_expiry, etc.)
+	UserUuid         string                 `protobuf:"bytes,3,opt,name=user_uuid,json=userUuid,proto3" json:"user_uuid,omitempty"`                         // UUID of the recipient user
+	Channel          string                 `protobuf:"bytes,4,opt,name=channel,proto3" json:"channel,omitempty"`                                           // Delivery channel (email, sms, webhook, in_app)
+	Recipient        string                 `protobuf:"bytes,5,opt,name=recipient,proto3" json:"recipient,omitempty"`                                       // Channel address the notification was sent to
+	Subject          string                 `protobuf:"bytes,6,opt,name=subject,proto3" json:"subject,omitempty"`                                           // Notification subject
+	Attempts         int32                  `protobuf:"varint,7,opt,name=attempts,proto3" json:"attempts,omitempty"`                                        // Number of delivery attempts made
+	LastError        string                 `protobuf:"bytes,8,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`                      // Error returned by the last attempt
+	CreatedAt        string                 `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`                      // Time the notification was queued in ISO format
+	UpdatedAt        string                 `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`                     // Time of the last attempt in ISO format
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *DeadLetter) Reset() {
+	*x = DeadLetter{}
+	mi := &file_notification_v1_notification_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeadLetter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeadLetter) ProtoMessage() {}
+
+func (x *DeadLetter) ProtoReflect() protoreflect.Message {
+	mi := &file_notification_v1_notification_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeadLetter.ProtoReflect.Descriptor instead.
+func (*DeadLetter) Descriptor() ([]byte, []int) {
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DeadLetter) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DeadLetter) GetNotificationType() string {
+	if x != nil {
+		return x.NotificationType
+	}
+	return ""
+}
+
+func (x *DeadLetter) GetUserUuid() string {
+	if x != nil {
+		return x.UserUuid
+	}
+	return ""
+}
+
+func (x *DeadLetter) GetChannel() string {
+	if x != nil {
+		return x.Channel
+	}
+	return ""
+}
+
+func (x *DeadLetter) GetRecipient() string {
+	if x != nil {
+		return x.Recipient
+	}
+	return ""
+}
+
+func (x *DeadLetter) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *DeadLetter) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *DeadLetter) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *DeadLetter) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *DeadLetter) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+// ListDeadLettersResponse contains the requested page of dead letters
+type ListDeadLettersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeadLetters   []*DeadLetter          `protobuf:"bytes,1,rep,name=dead_letters,json=deadLetters,proto3" json:"dead_letters,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDeadLettersResponse) Reset() {
+	*x = ListDeadLettersResponse{}
+	mi := &file_notification_v1_notification_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDeadLettersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDeadLettersResponse) ProtoMessage() {}
+
+func (x *ListDeadLettersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_notification_v1_notification_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDeadLettersResponse.ProtoReflect.Descriptor instead.
+func (*ListDeadLettersResponse) Descriptor() ([]byte, []int) {
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListDeadLettersResponse) GetDeadLetters() []*DeadLetter {
+	if x != nil {
+		return x.DeadLetters
+	}
+	return nil
+}
+
+// ReplayDeadLetterRequest identifies the dead letter to re-queue
+type ReplayDeadLetterRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"` // Outbox message ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayDeadLetterRequest) Reset() {
+	*x = ReplayDeadLetterRequest{}
+	mi := &file_notification_v1_notification_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayDeadLetterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayDeadLetterRequest) ProtoMessage() {}
+
+func (x *ReplayDeadLetterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_notification_v1_notification_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayDeadLetterRequest.ProtoReflect.Descriptor instead.
+func (*ReplayDeadLetterRequest) Descriptor() ([]byte, []int) {
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ReplayDeadLetterRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+// ReplayDeadLetterResponse confirms the dead letter was re-queued
+type ReplayDeadLetterResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"` // Whether the dead letter was re-queued
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`  // Optional message about the replay
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayDeadLetterResponse) Reset() {
+	*x = ReplayDeadLetterResponse{}
+	mi := &file_notification_v1_notification_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayDeadLetterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayDeadLetterResponse) ProtoMessage() {}
+
+func (x *ReplayDeadLetterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_notification_v1_notification_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayDeadLetterResponse.ProtoReflect.Descriptor instead.
+func (*ReplayDeadLetterResponse) Descriptor() ([]byte, []int) {
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ReplayDeadLetterResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ReplayDeadLetterResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// ListNotificationHistoryRequest pages through a user's delivery attempts
+type ListNotificationHistoryRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	UserUuid         string                 `protobuf:"bytes,1,opt,name=user_uuid,json=userUuid,proto3" json:"user_uuid,omitempty"`                         // UUID of the user
+	NotificationType string                 `protobuf:"bytes,2,opt,name=notification_type,json=notificationType,proto3" json:"notification_type,omitempty"` // Optional notification type filter
+	Limit            int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`                                              // Maximum number of results (default 50)
+	Offset           int32                  `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`                                            // Number of results to skip
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ListNotificationHistoryRequest) Reset() {
+	*x = ListNotificationHistoryRequest{}
+	mi := &file_notification_v1_notification_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNotificationHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNotificationHistoryRequest) ProtoMessage() {}
+
+func (x *ListNotificationHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_notification_v1_notification_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNotificationHistoryRequest.ProtoReflect.Descriptor instead.
+func (*ListNotificationHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListNotificationHistoryRequest) GetUserUuid() string {
+	if x != nil {
+		return x.UserUuid
+	}
+	return ""
+}
+
+func (x *ListNotificationHistoryRequest) GetNotificationType() string {
+	if x != nil {
+		return x.NotificationType
+	}
+	return ""
+}
+
+func (x *ListNotificationHistoryRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListNotificationHistoryRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+// NotificationAttempt is a single attempt to deliver a notification
+type NotificationAttempt struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`                                                    // Attempt ID
+	NotificationType string                 `protobuf:"bytes,2,opt,name=notification_type,json=notificationType,proto3" json:"notification_type,omitempty"` // Notification type (user_created, document_expiry, etc.)
+	Channel          string                 `protobuf:"bytes,3,opt,name=channel,proto3" json:"channel,omitempty"`                                           // Delivery channel (email, sms, webhook, in_app)
+	Recipient        string                 `protobuf:"bytes,4,opt,name=recipient,proto3" json:"recipient,omitempty"`                                       // Channel address the notification was sent to
+	Subject          string                 `protobuf:"bytes,5,opt,name=subject,proto3" json:"subject,omitempty"`                                           // Notification subject
+	Attempt          int32                  `protobuf:"varint,6,opt,name=attempt,proto3" json:"attempt,omitempty"`                                          // Attempt number for this notification
+	Status           string                 `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`                                             // delivered or failed
+	ProviderResponse string                 `protobuf:"bytes,8,opt,name=provider_response,json=providerResponse,proto3" json:"provider_response,omitempty"` // Response or error returned by the provider
+	QueuedAt         string                 `protobuf:"bytes,9,opt,name=queued_at,json=queuedAt,proto3" json:"queued_at,omitempty"`                         // Time the notification was queued in ISO format
+	AttemptedAt      string                 `protobuf:"bytes,10,opt,name=attempted_at,json=attemptedAt,proto3" json:"attempted_at,omitempty"`               // Time of the attempt in ISO format
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *NotificationAttempt) Reset() {
+	*x = NotificationAttempt{}
+	mi := &file_notification_v1_notification_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NotificationAttempt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NotificationAttempt) ProtoMessage() {}
+
+func (x *NotificationAttempt) ProtoReflect() protoreflect.Message {
+	mi := &file_notification_v1_notification_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NotificationAttempt.ProtoReflect.Descriptor instead.
+func (*NotificationAttempt) Descriptor() ([]byte, []int) {
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *NotificationAttempt) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *NotificationAttempt) GetNotificationType() string {
+	if x != nil {
+		return x.NotificationType
+	}
+	return ""
+}
+
+func (x *NotificationAttempt) GetChannel() string {
+	if x != nil {
+		return x.Channel
+	}
+	return ""
+}
+
+func (x *NotificationAttempt) GetRecipient() string {
+	if x != nil {
+		return x.Recipient
+	}
+	return ""
+}
+
+func (x *NotificationAttempt) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *NotificationAttempt) GetAttempt() int32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+func (x *NotificationAttempt) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *NotificationAttempt) GetProviderResponse() string {
+	if x != nil {
+		return x.ProviderResponse
+	}
+	return ""
+}
+
+func (x *NotificationAttempt) GetQueuedAt() string {
+	if x != nil {
+		return x.QueuedAt
+	}
+	return ""
+}
+
+func (x *NotificationAttempt) GetAttemptedAt() string {
+	if x != nil {
+		return x.AttemptedAt
+	}
+	return ""
+}
+
+// ListNotificationHistoryResponse contains the requested page of attempts
+type ListNotificationHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Attempts      []*NotificationAttempt `protobuf:"bytes,1,rep,name=attempts,proto3" json:"attempts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNotificationHistoryResponse) Reset() {
+	*x = ListNotificationHistoryResponse{}
+	mi := &file_notification_v1_notification_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNotificationHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNotificationHistoryResponse) ProtoMessage() {}
+
+func (x *ListNotificationHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_notification_v1_notification_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNotificationHistoryResponse.ProtoReflect.Descriptor instead.
+func (*ListNotificationHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListNotificationHistoryResponse) GetAttempts() []*NotificationAttempt {
+	if x != nil {
+		return x.Attempts
+	}
+	return nil
+}
+
+// StreamEventsRequest selects which events to receive
+type StreamEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserUuid      string                 `protobuf:"bytes,1,opt,name=user_uuid,json=userUuid,proto3" json:"user_uuid,omitempty"`       // Only stream events for this user (all users if empty)
+	EventTypes    []string               `protobuf:"bytes,2,rep,name=event_types,json=eventTypes,proto3" json:"event_types,omitempty"` // Only stream these event types (all types if empty)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamEventsRequest) Reset() {
+	*x = StreamEventsRequest{}
+	mi := &file_notification_v1_notification_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamEventsRequest) ProtoMessage() {}
+
+func (x *StreamEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_notification_v1_notification_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamEventsRequest.ProtoReflect.Descriptor instead.
+func (*StreamEventsRequest) Descriptor() ([]byte, []int) {
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *StreamEventsRequest) GetUserUuid() string {
+	if x != nil {
+		return x.UserUuid
+	}
+	return ""
+}
+
+func (x *StreamEventsRequest) GetEventTypes() []string {
+	if x != nil {
+		return x.EventTypes
+	}
+	return nil
+}
+
+// NotificationEvent is a real-time update
+type NotificationEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`                            // notification.created or document.status_changed
+	UserUuid      string                 `protobuf:"bytes,2,opt,name=user_uuid,json=userUuid,proto3" json:"user_uuid,omitempty"`    // UUID of the user the event belongs to
+	Payload       *structpb.Struct       `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`                      // Event details
+	CreatedAt     string                 `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // Time the event occurred in ISO format
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NotificationEvent) Reset() {
+	*x = NotificationEvent{}
+	mi := &file_notification_v1_notification_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NotificationEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NotificationEvent) ProtoMessage() {}
+
+func (x *NotificationEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_notification_v1_notification_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NotificationEvent.ProtoReflect.Descriptor instead.
+func (*NotificationEvent) Descriptor() ([]byte, []int) {
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *NotificationEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *NotificationEvent) GetUserUuid() string {
+	if x != nil {
+		return x.UserUuid
+	}
+	return ""
+}
+
+func (x *NotificationEvent) GetPayload() *structpb.Struct {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *NotificationEvent) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+// GetProviderHealthRequest asks for the state of every notification provider
+type GetProviderHealthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProviderHealthRequest) Reset() {
+	*x = GetProviderHealthRequest{}
+	mi := &file_notification_v1_notification_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProviderHealthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProviderHealthRequest) ProtoMessage() {}
+
+func (x *GetProviderHealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_notification_v1_notification_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProviderHealthRequest.ProtoReflect.Descriptor instead.
+func (*GetProviderHealthRequest) Descriptor() ([]byte, []int) {
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{21}
+}
+
+// GetProviderHealthResponse lists providers per channel in failover order
+type GetProviderHealthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Providers     []*ProviderHealth      `protobuf:"bytes,1,rep,name=providers,proto3" json:"providers,omitempty"` // Providers in failover order, grouped by channel
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProviderHealthResponse) Reset() {
+	*x = GetProviderHealthResponse{}
+	mi := &file_notification_v1_notification_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProviderHealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProviderHealthResponse) ProtoMessage() {}
+
+func (x *GetProviderHealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_notification_v1_notification_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProviderHealthResponse.ProtoReflect.Descriptor instead.
+func (*GetProviderHealthResponse) Descriptor() ([]byte, []int) {
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetProviderHealthResponse) GetProviders() []*ProviderHealth {
+	if x != nil {
+		return x.Providers
+	}
+	return nil
+}
+
+// ProviderHealth is the circuit breaker state of one provider
+type ProviderHealth struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Channel             string                 `protobuf:"bytes,1,opt,name=channel,proto3" json:"channel,omitempty"`                                                     // Channel the provider delivers (email, sms, webhook)
+	Provider            string                 `protobuf:"bytes,2,opt,name=provider,proto3" json:"provider,omitempty"`                                                   // Provider name (email, smtp, sms, webhook, mock)
+	State               string                 `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`                                                         // Breaker state: closed, open or half_open
+	ConsecutiveFailures int32                  `protobuf:"varint,4,opt,name=consecutive_failures,json=consecutiveFailures,proto3" json:"consecutive_failures,omitempty"` // Failures since the last successful send
+	Sent                int64                  `protobuf:"varint,5,opt,name=sent,proto3" json:"sent,omitempty"`                                                          // Successful sends since startup
+	Failed              int64                  `protobuf:"varint,6,opt,name=failed,proto3" json:"failed,omitempty"`                                                      // Failed sends since startup
+	LastError           string                 `protobuf:"bytes,7,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`                                // Error from the most recent failure
+	LastFailureAt       string                 `protobuf:"bytes,8,opt,name=last_failure_at,json=lastFailureAt,proto3" json:"last_failure_at,omitempty"`                  // When the most recent failure happened (RFC3339)
+	OpenedAt            string                 `protobuf:"bytes,9,opt,name=opened_at,json=openedAt,proto3" json:"opened_at,omitempty"`                                   // When the breaker last opened (RFC3339), if not closed
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *ProviderHealth) Reset() {
+	*x = ProviderHealth{}
+	mi := &file_notification_v1_notification_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProviderHealth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProviderHealth) ProtoMessage() {}
+
+func (x *ProviderHealth) ProtoReflect() protoreflect.Message {
+	mi := &file_notification_v1_notification_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProviderHealth.ProtoReflect.Descriptor instead.
+func (*ProviderHealth) Descriptor() ([]byte, []int) {
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ProviderHealth) GetChannel() string {
+	if x != nil {
+		return x.Channel
+	}
+	return ""
+}
+
+func (x *ProviderHealth) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *ProviderHealth) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *ProviderHealth) GetConsecutiveFailures() int32 {
+	if x != nil {
+		return x.ConsecutiveFailures
+	}
+	return 0
+}
+
+func (x *ProviderHealth) GetSent() int64 {
+	if x != nil {
+		return x.Sent
+	}
+	return 0
+}
+
+func (x *ProviderHealth) GetFailed() int64 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *ProviderHealth) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *ProviderHealth) GetLastFailureAt() string {
+	if x != nil {
+		return x.LastFailureAt
+	}
+	return ""
+}
+
+func (x *ProviderHealth) GetOpenedAt() string {
+	if x != nil {
+		return x.OpenedAt
+	}
+	return ""
+}
+
+// ScheduleNotificationRequest schedules a generic notification for later delivery
+type ScheduleNotificationRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	EventType      string                 `protobuf:"bytes,1,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`                // Registered event type (user_created, document_expiry, etc.)
+	Recipient      *RecipientRef          `protobuf:"bytes,2,opt,name=recipient,proto3" json:"recipient,omitempty"`                                 // Recipient of the notification
+	Locale         string                 `protobuf:"bytes,3,opt,name=locale,proto3" json:"locale,omitempty"`                                       // Preferred locale for templates, e.g. en or en-US
+	Payload        *structpb.Struct       `protobuf:"bytes,4,opt,name=payload,proto3" json:"payload,omitempty"`                                     // Event data validated against the event schema
+	SendAt         string                 `protobuf:"bytes,5,opt,name=send_at,json=sendAt,proto3" json:"send_at,omitempty"`                         // When to send (RFC3339); takes precedence over delay_seconds
+	DelaySeconds   int64                  `protobuf:"varint,6,opt,name=delay_seconds,json=delaySeconds,proto3" json:"delay_seconds,omitempty"`      // Send this many seconds from now
+	Key            string                 `protobuf:"bytes,7,opt,name=key,proto3" json:"key,omitempty"`                                             // Optional caller key, used to cancel without the ID
+	IdempotencyKey string                 `protobuf:"bytes,8,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"` // Optional key; repeated calls with the same key return the original result
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ScheduleNotificationRequest) Reset() {
+	*x = ScheduleNotificationRequest{}
+	mi := &file_notification_v1_notification_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScheduleNotificationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduleNotificationRequest) ProtoMessage() {}
+
+func (x *ScheduleNotificationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_notification_v1_notification_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduleNotificationRequest.ProtoReflect.Descriptor instead.
+func (*ScheduleNotificationRequest) Descriptor() ([]byte, []int) {
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ScheduleNotificationRequest) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *ScheduleNotificationRequest) GetRecipient() *RecipientRef {
+	if x != nil {
+		return x.Recipient
+	}
+	return nil
+}
+
+func (x *ScheduleNotificationRequest) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+func (x *ScheduleNotificationRequest) GetPayload() *structpb.Struct {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *ScheduleNotificationRequest) GetSendAt() string {
+	if x != nil {
+		return x.SendAt
+	}
+	return ""
+}
+
+func (x *ScheduleNotificationRequest) GetDelaySeconds() int64 {
+	if x != nil {
+		return x.DelaySeconds
+	}
+	return 0
+}
+
+func (x *ScheduleNotificationRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *ScheduleNotificationRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+// ScheduleNotificationResponse identifies the scheduled notification
+type ScheduleNotificationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`                      // ID of the scheduled notification
+	SendAt        string                 `protobuf:"bytes,2,opt,name=send_at,json=sendAt,proto3" json:"send_at,omitempty"` // When it will be sent (RFC3339)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScheduleNotificationResponse) Reset() {
+	*x = ScheduleNotificationResponse{}
+	mi := &file_notification_v1_notification_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScheduleNotificationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduleNotificationResponse) ProtoMessage() {}
+
+func (x *ScheduleNotificationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_notification_v1_notification_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduleNotificationResponse.ProtoReflect.Descriptor instead.
+func (*ScheduleNotificationResponse) Descriptor() ([]byte, []int) {
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ScheduleNotificationResponse) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ScheduleNotificationResponse) GetSendAt() string {
+	if x != nil {
+		return x.SendAt
+	}
+	return ""
+}
+
+// CancelScheduledNotificationRequest selects scheduled notifications by ID, or by user and key
+type CancelScheduledNotificationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`                            // ID returned by ScheduleNotification
+	UserUuid      string                 `protobuf:"bytes,2,opt,name=user_uuid,json=userUuid,proto3" json:"user_uuid,omitempty"` // Recipient user, used with key
+	Key           string                 `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`                           // Caller key given to ScheduleNotification
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelScheduledNotificationRequest) Reset() {
+	*x = CancelScheduledNotificationRequest{}
+	mi := &file_notification_v1_notification_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelScheduledNotificationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelScheduledNotificationRequest) ProtoMessage() {}
+
+func (x *CancelScheduledNotificationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_notification_v1_notification_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelScheduledNotificationRequest.ProtoReflect.Descriptor instead.
+func (*CancelScheduledNotificationRequest) Descriptor() ([]byte, []int) {
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *CancelScheduledNotificationRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *CancelScheduledNotificationRequest) GetUserUuid() string {
+	if x != nil {
+		return x.UserUuid
+	}
+	return ""
+}
+
+func (x *CancelScheduledNotificationRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+// CancelScheduledNotificationResponse reports how many notifications were cancelled
+type CancelScheduledNotificationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cancelled     int64                  `protobuf:"varint,1,opt,name=cancelled,proto3" json:"cancelled,omitempty"` // Number of notifications cancelled
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelScheduledNotificationResponse) Reset() {
+	*x = CancelScheduledNotificationResponse{}
+	mi := &file_notification_v1_notification_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelScheduledNotificationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelScheduledNotificationResponse) ProtoMessage() {}
+
+func (x *CancelScheduledNotificationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_notification_v1_notification_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelScheduledNotificationResponse.ProtoReflect.Descriptor instead.
+func (*CancelScheduledNotificationResponse) Descriptor() ([]byte, []int) {
+	return file_notification_v1_notification_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *CancelScheduledNotificationResponse) GetCancelled() int64 {
+	if x != nil {
+		return x.Cancelled
+	}
+	return 0
+}
+
+var File_notification_v1_notification_proto protoreflect.FileDescriptor
+
+const file_notification_v1_notification_proto_rawDesc = "" +
+	"\n" +
+	"\"notification/v1/notification.proto\x12\x0fnotification.v1\x1a\x1cgoogle/protobuf/struct.proto\"\x8c\x01\n" +
+	"\x12UserCreatedRequest\x12\x1b\n" +
+	"\tuser_uuid\x18\x01 \x01(\tR\buserUuid\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\x12\x1a\n" +
+	"\busername\x18\x03 \x01(\tR\busername\x12'\n" +
+	"\x0fidempotency_key\x18\x04 \x01(\tR\x0eidempotencyKey\"I\n" +
+	"\x13UserCreatedResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"e\n" +
+	"\n" +
+	"Attachment\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12!\n" +
+	"\fcontent_type\x18\x02 \x01(\tR\vcontentType\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\fR\acontent\"\x8a\x03\n" +
+	"\x15DocumentExpiryRequest\x12\x1b\n" +
+	"\tuser_uuid\x18\x01 \x01(\tR\buserUuid\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\x12#\n" +
+	"\rdocument_name\x18\x03 \x01(\tR\fdocumentName\x12+\n" +
+	"\x11document_category\x18\x04 \x01(\tR\x10documentCategory\x12\x1f\n" +
+	"\vexpiry_date\x18\x05 \x01(\tR\n" +
+	"expiryDate\x12*\n" +
+	"\x11days_until_expiry\x18\x06 \x01(\x05R\x0fdaysUntilExpiry\x12\x1d\n" +
+	"\n" +
+	"is_expired\x18\a \x01(\bR\tisExpired\x12\x18\n" +
+	"\amessage\x18\b \x01(\tR\amessage\x12'\n" +
+	"\x0fidempotency_key\x18\t \x01(\tR\x0eidempotencyKey\x12=\n" +
+	"\vattachments\x18\n" +
+	" \x03(\v2\x1b.notification.v1.AttachmentR\vattachments\"L\n" +
+	"\x16DocumentExpiryResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x8d\x02\n" +
+	"\x0eDigestDocument\x12#\n" +
+	"\rdocument_name\x18\x01 \x01(\tR\fdocumentName\x12+\n" +
+	"\x11document_category\x18\x02 \x01(\tR\x10documentCategory\x12\x1f\n" +
+	"\vexpiry_date\x18\x03 \x01(\tR\n" +
+	"expiryDate\x12*\n" +
+	"\x11days_until_expiry\x18\x04 \x01(\x05R\x0fdaysUntilExpiry\x12\x1d\n" +
+	"\n" +
+	"is_expired\x18\x05 \x01(\bR\tisExpired\x12=\n" +
+	"\vattachments\x18\x06 \x03(\v2\x1b.notification.v1.AttachmentR\vattachments\"\xb2\x01\n" +
+	"\x15DocumentDigestRequest\x12\x1b\n" +
+	"\tuser_uuid\x18\x01 \x01(\tR\buserUuid\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\x12=\n" +
+	"\tdocuments\x18\x03 \x03(\v2\x1f.notification.v1.DigestDocumentR\tdocuments\x12'\n" +
+	"\x0fidempotency_key\x18\x04 \x01(\tR\x0eidempotencyKey\"L\n" +
+	"\x16DocumentDigestResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"A\n" +
+	"\fRecipientRef\x12\x1b\n" +
+	"\tuser_uuid\x18\x01 \x01(\tR\buserUuid\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\"\xe9\x01\n" +
+	"\x17SendNotificationRequest\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x01 \x01(\tR\teventType\x12;\n" +
+	"\trecipient\x18\x02 \x01(\v2\x1d.notification.v1.RecipientRefR\trecipient\x12\x16\n" +
+	"\x06locale\x18\x03 \x01(\tR\x06locale\x121\n" +
+	"\apayload\x18\x04 \x01(\v2\x17.google.protobuf.StructR\apayload\x12'\n" +
+	"\x0fidempotency_key\x18\x05 \x01(\tR\x0eidempotencyKey\"N\n" +
+	"\x18SendNotificationResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"F\n" +
+	"\x16ListDeadLettersRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x05R\x06offset\"\xb1\x02\n" +
+	"\n" +
+	"DeadLetter\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12+\n" +
+	"\x11notification_type\x18\x02 \x01(\tR\x10notificationType\x12\x1b\n" +
+	"\tuser_uuid\x18\x03 \x01(\tR\buserUuid\x12\x18\n" +
+	"\achannel\x18\x04 \x01(\tR\achannel\x12\x1c\n" +
+	"\trecipient\x18\x05 \x01(\tR\trecipient\x12\x18\n" +
+	"\asubject\x18\x06 \x01(\tR\asubject\x12\x1a\n" +
+	"\battempts\x18\a \x01(\x05R\battempts\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\b \x01(\tR\tlastError\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\t \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\tR\tupdatedAt\"Y\n" +
+	"\x17ListDeadLettersResponse\x12>\n" +
+	"\fdead_letters\x18\x01 \x03(\v2\x1b.notification.v1.DeadLetterR\vdeadLetters\")\n" +
+	"\x17ReplayDeadLetterRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"N\n" +
+	"\x18ReplayDeadLetterResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x98\x01\n" +
+	"\x1eListNotificationHistoryRequest\x12\x1b\n" +
+	"\tuser_uuid\x18\x01 \x01(\tR\buserUuid\x12+\n" +
+	"\x11notification_type\x18\x02 \x01(\tR\x10notificationType\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x05R\x06offset\"\xc3\x02\n" +
+	"\x13NotificationAttempt\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12+\n" +
+	"\x11notification_type\x18\x02 \x01(\tR\x10notificationType\x12\x18\n" +
+	"\achannel\x18\x03 \x01(\tR\achannel\x12\x1c\n" +
+	"\trecipient\x18\x04 \x01(\tR\trecipient\x12\x18\n" +
+	"\asubject\x18\x05 \x01(\tR\asubject\x12\x18\n" +
+	"\aattempt\x18\x06 \x01(\x05R\aattempt\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06status\x12+\n" +
+	"\x11provider_response\x18\b \x01(\tR\x10providerResponse\x12\x1b\n" +
+	"\tqueued_at\x18\t \x01(\tR\bqueuedAt\x12!\n" +
+	"\fattempted_at\x18\n" +
+	" \x01(\tR\vattemptedAt\"c\n" +
+	"\x1fListNotificationHistoryResponse\x12@\n" +
+	"\battempts\x18\x01 \x03(\v2$.notification.v1.NotificationAttemptR\battempts\"S\n" +
+	"\x13StreamEventsRequest\x12\x1b\n" +
+	"\tuser_uuid\x18\x01 \x01(\tR\buserUuid\x12\x1f\n" +
+	"\vevent_types\x18\x02 \x03(\tR\n" +
+	"eventTypes\"\x96\x01\n" +
+	"\x11NotificationEvent\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x1b\n" +
+	"\tuser_uuid\x18\x02 \x01(\tR\buserUuid\x121\n" +
+	"\apayload\x18\x03 \x01(\v2\x17.google.protobuf.StructR\apayload\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\tR\tcreatedAt\"\x1a\n" +
+	"\x18GetProviderHealthRequest\"Z\n" +
+	"\x19GetProviderHealthResponse\x12=\n" +
+	"\tproviders\x18\x01 \x03(\v2\x1f.notification.v1.ProviderHealthR\tproviders\"\x9f\x02\n" +
+	"\x0eProviderHealth\x12\x18\n" +
+	"\achannel\x18\x01 \x01(\tR\achannel\x12\x1a\n" +
+	"\bprovider\x18\x02 \x01(\tR\bprovider\x12\x14\n" +
+	"\x05state\x18\x03 \x01(\tR\x05state\x121\n" +
+	"\x14consecutive_failures\x18\x04 \x01(\x05R\x13consecutiveFailures\x12\x12\n" +
+	"\x04sent\x18\x05 \x01(\x03R\x04sent\x12\x16\n" +
+	"\x06failed\x18\x06 \x01(\x03R\x06failed\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\a \x01(\tR\tlastError\x12&\n" +
+	"\x0flast_failure_at\x18\b \x01(\tR\rlastFailureAt\x12\x1b\n" +
+	"\topened_at\x18\t \x01(\tR\bopenedAt\"\xbd\x02\n" +
+	"\x1bScheduleNotificationRequest\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x01 \x01(\tR\teventType\x12;\n" +
+	"\trecipient\x18\x02 \x01(\v2\x1d.notification.v1.RecipientRefR\trecipient\x12\x16\n" +
+	"\x06locale\x18\x03 \x01(\tR\x06locale\x121\n" +
+	"\apayload\x18\x04 \x01(\v2\x17.google.protobuf.StructR\apayload\x12\x17\n" +
+	"\asend_at\x18\x05 \x01(\tR\x06sendAt\x12#\n" +
+	"\rdelay_seconds\x18\x06 \x01(\x03R\fdelaySeconds\x12\x10\n" +
+	"\x03key\x18\a \x01(\tR\x03key\x12'\n" +
+	"\x0fidempotency_key\x18\b \x01(\tR\x0eidempotencyKey\"G\n" +
+	"\x1cScheduleNotificationResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x17\n" +
+	"\asend_at\x18\x02 \x01(\tR\x06sendAt\"c\n" +
+	"\"CancelScheduledNotificationRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1b\n" +
+	"\tuser_uuid\x18\x02 \x01(\tR\buserUuid\x12\x10\n" +
+	"\x03key\x18\x03 \x01(\tR\x03key\"C\n" +
+	"#CancelScheduledNotificationResponse\x12\x1c\n" +
+	"\tcancelled\x18\x01 \x01(\x03R\tcancelled2\xc5\t\n" +
 	"\x13NotificationService\x12^\n" +
 	"\x11NotifyUserCreated\x12#.notification.v1.UserCreatedRequest\x1a$.notification.v1.UserCreatedResponse\x12g\n" +
-	"\x14NotifyDocumentExpiry\x12&.notification.v1.DocumentExpiryRequest\x1a'.notification.v1.DocumentExpiryResponseBMZKgithub.com/johnroshan2255/core-service/proto/notification/v1;notificationv1b\x06proto3"
+	"\x14NotifyDocumentExpiry\x12&.notification.v1.DocumentExpiryRequest\x1a'.notification.v1.DocumentExpiryResponse\x12g\n" +
+	"\x14NotifyDocumentDigest\x12&.notification.v1.DocumentDigestRequest\x1a'.notification.v1.DocumentDigestResponse\x12g\n" +
+	"\x10SendNotification\x12(.notification.v1.SendNotificationRequest\x1a).notification.v1.SendNotificationResponse\x12d\n" +
+	"\x0fListDeadLetters\x12'.notification.v1.ListDeadLettersRequest\x1a(.notification.v1.ListDeadLettersResponse\x12g\n" +
+	"\x10ReplayDeadLetter\x12(.notification.v1.ReplayDeadLetterRequest\x1a).notification.v1.ReplayDeadLetterResponse\x12|\n" +
+	"\x17ListNotificationHistory\x12/.notification.v1.ListNotificationHistoryRequest\x1a0.notification.v1.ListNotificationHistoryResponse\x12Z\n" +
+	"\fStreamEvents\x12$.notification.v1.StreamEventsRequest\x1a\".notification.v1.NotificationEvent0\x01\x12j\n" +
+	"\x11GetProviderHealth\x12).notification.v1.GetProviderHealthRequest\x1a*.notification.v1.GetProviderHealthResponse\x12s\n" +
+	"\x14ScheduleNotification\x12,.notification.v1.ScheduleNotificationRequest\x1a-.notification.v1.ScheduleNotificationResponse\x12\x88\x01\n" +
+	"\x1bCancelScheduledNotification\x123.notification.v1.CancelScheduledNotificationRequest\x1a4.notification.v1.CancelScheduledNotificationResponseBMZKgithub.com/johnroshan2255/core-service/proto/notification/v1;notificationv1b\x06proto3"
 
 var (
 	file_notification_v1_notification_proto_rawDescOnce sync.Once
@@ -331,23 +2060,77 @@ func file_notification_v1_notification_proto_rawDescGZIP() []byte {
 	return file_notification_v1_notification_proto_rawDescData
 }
 
-var file_notification_v1_notification_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_notification_v1_notification_proto_msgTypes = make([]protoimpl.MessageInfo, 28)
 var file_notification_v1_notification_proto_goTypes = []any{
-	(*UserCreatedRequest)(nil),     // 0: notification.v1.UserCreatedRequest
-	(*UserCreatedResponse)(nil),    // 1: notification.v1.UserCreatedResponse
-	(*DocumentExpiryRequest)(nil),  // 2: notification.v1.DocumentExpiryRequest
-	(*DocumentExpiryResponse)(nil), // 3: notification.v1.DocumentExpiryResponse
+	(*UserCreatedRequest)(nil),                  // 0: notification.v1.UserCreatedRequest
+	(*UserCreatedResponse)(nil),                 // 1: notification.v1.UserCreatedResponse
+	(*Attachment)(nil),                          // 2: notification.v1.Attachment
+	(*DocumentExpiryRequest)(nil),               // 3: notification.v1.DocumentExpiryRequest
+	(*DocumentExpiryResponse)(nil),              // 4: notification.v1.DocumentExpiryResponse
+	(*DigestDocument)(nil),                      // 5: notification.v1.DigestDocument
+	(*DocumentDigestRequest)(nil),               // 6: notification.v1.DocumentDigestRequest
+	(*DocumentDigestResponse)(nil),              // 7: notification.v1.DocumentDigestResponse
+	(*RecipientRef)(nil),                        // 8: notification.v1.RecipientRef
+	(*SendNotificationRequest)(nil),             // 9: notification.v1.SendNotificationRequest
+	(*SendNotificationResponse)(nil),            // 10: notification.v1.SendNotificationResponse
+	(*ListDeadLettersRequest)(nil),              // 11: notification.v1.ListDeadLettersRequest
+	(*DeadLetter)(nil),                          // 12: notification.v1.DeadLetter
+	(*ListDeadLettersResponse)(nil),             // 13: notification.v1.ListDeadLettersResponse
+	(*ReplayDeadLetterRequest)(nil),             // 14: notification.v1.ReplayDeadLetterRequest
+	(*ReplayDeadLetterResponse)(nil),            // 15: notification.v1.ReplayDeadLetterResponse
+	(*ListNotificationHistoryRequest)(nil),      // 16: notification.v1.ListNotificationHistoryRequest
+	(*NotificationAttempt)(nil),                 // 17: notification.v1.NotificationAttempt
+	(*ListNotificationHistoryResponse)(nil),     // 18: notification.v1.ListNotificationHistoryResponse
+	(*StreamEventsRequest)(nil),                 // 19: notification.v1.StreamEventsRequest
+	(*NotificationEvent)(nil),                   // 20: notification.v1.NotificationEvent
+	(*GetProviderHealthRequest)(nil),            // 21: notification.v1.GetProviderHealthRequest
+	(*GetProviderHealthResponse)(nil),           // 22: notification.v1.GetProviderHealthResponse
+	(*ProviderHealth)(nil),                      // 23: notification.v1.ProviderHealth
+	(*ScheduleNotificationRequest)(nil),         // 24: notification.v1.ScheduleNotificationRequest
+	(*ScheduleNotificationResponse)(nil),        // 25: notification.v1.ScheduleNotificationResponse
+	(*CancelScheduledNotificationRequest)(nil),  // 26: notification.v1.CancelScheduledNotificationRequest
+	(*CancelScheduledNotificationResponse)(nil), // 27: notification.v1.CancelScheduledNotificationResponse
+	(*structpb.Struct)(nil),                     // 28: google.protobuf.Struct
 }
 var file_notification_v1_notification_proto_depIdxs = []int32{
-	0, // 0: notification.v1.NotificationService.NotifyUserCreated:input_type -> notification.v1.UserCreatedRequest
-	2, // 1: notification.v1.NotificationService.NotifyDocumentExpiry:input_type -> notification.v1.DocumentExpiryRequest
-	1, // 2: notification.v1.NotificationService.NotifyUserCreated:output_type -> notification.v1.UserCreatedResponse
-	3, // 3: notification.v1.NotificationService.NotifyDocumentExpiry:output_type -> notification.v1.DocumentExpiryResponse
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	2,  // 0: notification.v1.DocumentExpiryRequest.attachments:type_name -> notification.v1.Attachment
+	2,  // 1: notification.v1.DigestDocument.attachments:type_name -> notification.v1.Attachment
+	5,  // 2: notification.v1.DocumentDigestRequest.documents:type_name -> notification.v1.DigestDocument
+	8,  // 3: notification.v1.SendNotificationRequest.recipient:type_name -> notification.v1.RecipientRef
+	28, // 4: notification.v1.SendNotificationRequest.payload:type_name -> google.protobuf.Struct
+	12, // 5: notification.v1.ListDeadLettersResponse.dead_letters:type_name -> notification.v1.DeadLetter
+	17, // 6: notification.v1.ListNotificationHistoryResponse.attempts:type_name -> notification.v1.NotificationAttempt
+	28, // 7: notification.v1.NotificationEvent.payload:type_name -> google.protobuf.Struct
+	23, // 8: notification.v1.GetProviderHealthResponse.providers:type_name -> notification.v1.ProviderHealth
+	8,  // 9: notification.v1.ScheduleNotificationRequest.recipient:type_name -> notification.v1.RecipientRef
+	28, // 10: notification.v1.ScheduleNotificationRequest.payload:type_name -> google.protobuf.Struct
+	0,  // 11: notification.v1.NotificationService.NotifyUserCreated:input_type -> notification.v1.UserCreatedRequest
+	3,  // 12: notification.v1.NotificationService.NotifyDocumentExpiry:input_type -> notification.v1.DocumentExpiryRequest
+	6,  // 13: notification.v1.NotificationService.NotifyDocumentDigest:input_type -> notification.v1.DocumentDigestRequest
+	9,  // 14: notification.v1.NotificationService.SendNotification:input_type -> notification.v1.SendNotificationRequest
+	11, // 15: notification.v1.NotificationService.ListDeadLetters:input_type -> notification.v1.ListDeadLettersRequest
+	14, // 16: notification.v1.NotificationService.ReplayDeadLetter:input_type -> notification.v1.ReplayDeadLetterRequest
+	16, // 17: notification.v1.NotificationService.ListNotificationHistory:input_type -> notification.v1.ListNotificationHistoryRequest
+	19, // 18: notification.v1.NotificationService.StreamEvents:input_type -> notification.v1.StreamEventsRequest
+	21, // 19: notification.v1.NotificationService.GetProviderHealth:input_type -> notification.v1.GetProviderHealthRequest
+	24, // 20: notification.v1.NotificationService.ScheduleNotification:input_type -> notification.v1.ScheduleNotificationRequest
+	26, // 21: notification.v1.NotificationService.CancelScheduledNotification:input_type -> notification.v1.CancelScheduledNotificationRequest
+	1,  // 22: notification.v1.NotificationService.NotifyUserCreated:output_type -> notification.v1.UserCreatedResponse
+	4,  // 23: notification.v1.NotificationService.NotifyDocumentExpiry:output_type -> notification.v1.DocumentExpiryResponse
+	7,  // 24: notification.v1.NotificationService.NotifyDocumentDigest:output_type -> notification.v1.DocumentDigestResponse
+	10, // 25: notification.v1.NotificationService.SendNotification:output_type -> notification.v1.SendNotificationResponse
+	13, // 26: notification.v1.NotificationService.ListDeadLetters:output_type -> notification.v1.ListDeadLettersResponse
+	15, // 27: notification.v1.NotificationService.ReplayDeadLetter:output_type -> notification.v1.ReplayDeadLetterResponse
+	18, // 28: notification.v1.NotificationService.ListNotificationHistory:output_type -> notification.v1.ListNotificationHistoryResponse
+	20, // 29: notification.v1.NotificationService.StreamEvents:output_type -> notification.v1.NotificationEvent
+	22, // 30: notification.v1.NotificationService.GetProviderHealth:output_type -> notification.v1.GetProviderHealthResponse
+	25, // 31: notification.v1.NotificationService.ScheduleNotification:output_type -> notification.v1.ScheduleNotificationResponse
+	27, // 32: notification.v1.NotificationService.CancelScheduledNotification:output_type -> notification.v1.CancelScheduledNotificationResponse
+	22, // [22:33] is the sub-list for method output_type
+	11, // [11:22] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
 }
 
 func init() { file_notification_v1_notification_proto_init() }
@@ -361,7 +2144,7 @@ func file_notification_v1_notification_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_notification_v1_notification_proto_rawDesc), len(file_notification_v1_notification_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   28,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,8 +19,17 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	NotificationService_NotifyUserCreated_FullMethodName    = "/notification.v1.NotificationService/NotifyUserCreated"
-	NotificationService_NotifyDocumentExpiry_FullMethodName = "/notification.v1.NotificationService/NotifyDocumentExpiry"
+	NotificationService_NotifyUserCreated_FullMethodName           = "/notification.v1.NotificationService/NotifyUserCreated"
+	NotificationService_NotifyDocumentExpiry_FullMethodName        = "/notification.v1.NotificationService/NotifyDocumentExpiry"
+	NotificationService_NotifyDocumentDigest_FullMethodName        = "/notification.v1.NotificationService/NotifyDocumentDigest"
+	NotificationService_SendNotification_FullMethodName            = "/notification.v1.NotificationService/SendNotification"
+	NotificationService_ListDeadLetters_FullMethodName             = "/notification.v1.NotificationService/ListDeadLetters"
+	NotificationService_ReplayDeadLetter_FullMethodName            = "/notification.v1.NotificationService/ReplayDeadLetter"
+	NotificationService_ListNotificationHistory_FullMethodName     = "/notification.v1.NotificationService/ListNotificationHistory"
+	NotificationService_StreamEvents_FullMethodName                = "/notification.v1.NotificationService/StreamEvents"
+	NotificationService_GetProviderHealth_FullMethodName           = "/notification.v1.NotificationService/GetProviderHealth"
+	NotificationService_ScheduleNotification_FullMethodName        = "/notification.v1.NotificationService/ScheduleNotification"
+	NotificationService_CancelScheduledNotification_FullMethodName = "/notification.v1.NotificationService/CancelScheduledNotification"
 )
 
 // NotificationServiceClient is the client API for NotificationService service.
@@ -33,6 +42,24 @@ type NotificationServiceClient interface {
 	NotifyUserCreated(ctx context.Context, in *UserCreatedRequest, opts ...grpc.CallOption) (*UserCreatedResponse, error)
 	// NotifyDocumentExpiry is called when a document is about to expire or has expired
 	NotifyDocumentExpiry(ctx context.Context, in *DocumentExpiryRequest, opts ...grpc.CallOption) (*DocumentExpiryResponse, error)
+	// NotifyDocumentDigest is called once per user with all of their expiring and expired documents
+	NotifyDocumentDigest(ctx context.Context, in *DocumentDigestRequest, opts ...grpc.CallOption) (*DocumentDigestResponse, error)
+	// SendNotification sends any registered event type; the payload is validated against the event's schema
+	SendNotification(ctx context.Context, in *SendNotificationRequest, opts ...grpc.CallOption) (*SendNotificationResponse, error)
+	// ListDeadLetters returns notifications that exhausted their delivery attempts
+	ListDeadLetters(ctx context.Context, in *ListDeadLettersRequest, opts ...grpc.CallOption) (*ListDeadLettersResponse, error)
+	// ReplayDeadLetter re-queues a dead-lettered notification for delivery
+	ReplayDeadLetter(ctx context.Context, in *ReplayDeadLetterRequest, opts ...grpc.CallOption) (*ReplayDeadLetterResponse, error)
+	// ListNotificationHistory returns the delivery attempts made for a user
+	ListNotificationHistory(ctx context.Context, in *ListNotificationHistoryRequest, opts ...grpc.CallOption) (*ListNotificationHistoryResponse, error)
+	// StreamEvents pushes new in-app notifications and document status changes as they happen
+	StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[NotificationEvent], error)
+	// GetProviderHealth reports the circuit breaker state of every notification provider (admin)
+	GetProviderHealth(ctx context.Context, in *GetProviderHealthRequest, opts ...grpc.CallOption) (*GetProviderHealthResponse, error)
+	// ScheduleNotification stores a generic notification to be sent at a later time
+	ScheduleNotification(ctx context.Context, in *ScheduleNotificationRequest, opts ...grpc.CallOption) (*ScheduleNotificationResponse, error)
+	// CancelScheduledNotification cancels scheduled notifications that haven't been sent yet
+	CancelScheduledNotification(ctx context.Context, in *CancelScheduledNotificationRequest, opts ...grpc.CallOption) (*CancelScheduledNotificationResponse, error)
 }
 
 type notificationServiceClient struct {
@@ -63,6 +90,105 @@ func (c *notificationServiceClient) NotifyDocumentExpiry(ctx context.Context, in
 	return out, nil
 }
 
+func (c *notificationServiceClient) NotifyDocumentDigest(ctx context.Context, in *DocumentDigestRequest, opts ...grpc.CallOption) (*DocumentDigestResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DocumentDigestResponse)
+	err := c.cc.Invoke(ctx, NotificationService_NotifyDocumentDigest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) SendNotification(ctx context.Context, in *SendNotificationRequest, opts ...grpc.CallOption) (*SendNotificationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendNotificationResponse)
+	err := c.cc.Invoke(ctx, NotificationService_SendNotification_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) ListDeadLetters(ctx context.Context, in *ListDeadLettersRequest, opts ...grpc.CallOption) (*ListDeadLettersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDeadLettersResponse)
+	err := c.cc.Invoke(ctx, NotificationService_ListDeadLetters_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) ReplayDeadLetter(ctx context.Context, in *ReplayDeadLetterRequest, opts ...grpc.CallOption) (*ReplayDeadLetterResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReplayDeadLetterResponse)
+	err := c.cc.Invoke(ctx, NotificationService_ReplayDeadLetter_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) ListNotificationHistory(ctx context.Context, in *ListNotificationHistoryRequest, opts ...grpc.CallOption) (*ListNotificationHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListNotificationHistoryResponse)
+	err := c.cc.Invoke(ctx, NotificationService_ListNotificationHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[NotificationEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &NotificationService_ServiceDesc.Streams[0], NotificationService_StreamEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamEventsRequest, NotificationEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NotificationService_StreamEventsClient = grpc.ServerStreamingClient[NotificationEvent]
+
+func (c *notificationServiceClient) GetProviderHealth(ctx context.Context, in *GetProviderHealthRequest, opts ...grpc.CallOption) (*GetProviderHealthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetProviderHealthResponse)
+	err := c.cc.Invoke(ctx, NotificationService_GetProviderHealth_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) ScheduleNotification(ctx context.Context, in *ScheduleNotificationRequest, opts ...grpc.CallOption) (*ScheduleNotificationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScheduleNotificationResponse)
+	err := c.cc.Invoke(ctx, NotificationService_ScheduleNotification_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *notificationServiceClient) CancelScheduledNotification(ctx context.Context, in *CancelScheduledNotificationRequest, opts ...grpc.CallOption) (*CancelScheduledNotificationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelScheduledNotificationResponse)
+	err := c.cc.Invoke(ctx, NotificationService_CancelScheduledNotification_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // NotificationServiceServer is the server API for NotificationService service.
 // All implementations must embed UnimplementedNotificationServiceServer
 // for forward compatibility.
@@ -73,6 +199,24 @@ type NotificationServiceServer interface {
 	NotifyUserCreated(context.Context, *UserCreatedRequest) (*UserCreatedResponse, error)
 	// NotifyDocumentExpiry is called when a document is about to expire or has expired
 	NotifyDocumentExpiry(context.Context, *DocumentExpiryRequest) (*DocumentExpiryResponse, error)
+	// NotifyDocumentDigest is called once per user with all of their expiring and expired documents
+	NotifyDocumentDigest(context.Context, *DocumentDigestRequest) (*DocumentDigestResponse, error)
+	// SendNotification sends any registered event type; the payload is validated against the event's schema
+	SendNotification(context.Context, *SendNotificationRequest) (*SendNotificationResponse, error)
+	// ListDeadLetters returns notifications that exhausted their delivery attempts
+	ListDeadLetters(context.Context, *ListDeadLettersRequest) (*ListDeadLettersResponse, error)
+	// ReplayDeadLetter re-queues a dead-lettered notification for delivery
+	ReplayDeadLetter(context.Context, *ReplayDeadLetterRequest) (*ReplayDeadLetterResponse, error)
+	// ListNotificationHistory returns the delivery attempts made for a user
+	ListNotificationHistory(context.Context, *ListNotificationHistoryRequest) (*ListNotificationHistoryResponse, error)
+	// StreamEvents pushes new in-app notifications and document status changes as they happen
+	StreamEvents(*StreamEventsRequest, grpc.ServerStreamingServer[NotificationEvent]) error
+	// GetProviderHealth reports the circuit breaker state of every notification provider (admin)
+	GetProviderHealth(context.Context, *GetProviderHealthRequest) (*GetProviderHealthResponse, error)
+	// ScheduleNotification stores a generic notification to be sent at a later time
+	ScheduleNotification(context.Context, *ScheduleNotificationRequest) (*ScheduleNotificationResponse, error)
+	// CancelScheduledNotification cancels scheduled notifications that haven't been sent yet
+	CancelScheduledNotification(context.Context, *CancelScheduledNotificationRequest) (*CancelScheduledNotificationResponse, error)
 	mustEmbedUnimplementedNotificationServiceServer()
 }
 
@@ -89,6 +233,33 @@ func (UnimplementedNotificationServiceServer) NotifyUserCreated(context.Context,
 func (UnimplementedNotificationServiceServer) NotifyDocumentExpiry(context.Context, *DocumentExpiryRequest) (*DocumentExpiryResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method NotifyDocumentExpiry not implemented")
 }
+func (UnimplementedNotificationServiceServer) NotifyDocumentDigest(context.Context, *DocumentDigestRequest) (*DocumentDigestResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method NotifyDocumentDigest not implemented")
+}
+func (UnimplementedNotificationServiceServer) SendNotification(context.Context, *SendNotificationRequest) (*SendNotificationResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SendNotification not implemented")
+}
+func (UnimplementedNotificationServiceServer) ListDeadLetters(context.Context, *ListDeadLettersRequest) (*ListDeadLettersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListDeadLetters not implemented")
+}
+func (UnimplementedNotificationServiceServer) ReplayDeadLetter(context.Context, *ReplayDeadLetterRequest) (*ReplayDeadLetterResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReplayDeadLetter not implemented")
+}
+func (UnimplementedNotificationServiceServer) ListNotificationHistory(context.Context, *ListNotificationHistoryRequest) (*ListNotificationHistoryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListNotificationHistory not implemented")
+}
+func (UnimplementedNotificationServiceServer) StreamEvents(*StreamEventsRequest, grpc.ServerStreamingServer[NotificationEvent]) error {
+	return status.Error(codes.Unimplemented, "method StreamEvents not implemented")
+}
+func (UnimplementedNotificationServiceServer) GetProviderHealth(context.Context, *GetProviderHealthRequest) (*GetProviderHealthResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetProviderHealth not implemented")
+}
+func (UnimplementedNotificationServiceServer) ScheduleNotification(context.Context, *ScheduleNotificationRequest) (*ScheduleNotificationResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ScheduleNotification not implemented")
+}
+func (UnimplementedNotificationServiceServer) CancelScheduledNotification(context.Context, *CancelScheduledNotificationRequest) (*CancelScheduledNotificationResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CancelScheduledNotification not implemented")
+}
 func (UnimplementedNotificationServiceServer) mustEmbedUnimplementedNotificationServiceServer() {}
 func (UnimplementedNotificationServiceServer) testEmbeddedByValue()                             {}
 
@@ -146,6 +317,161 @@ func _NotificationService_NotifyDocumentExpiry_Handler(srv interface{}, ctx cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _NotificationService_NotifyDocumentDigest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DocumentDigestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).NotifyDocumentDigest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_NotifyDocumentDigest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).NotifyDocumentDigest(ctx, req.(*DocumentDigestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_SendNotification_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendNotificationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).SendNotification(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_SendNotification_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).SendNotification(ctx, req.(*SendNotificationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_ListDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeadLettersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).ListDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_ListDeadLetters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).ListDeadLetters(ctx, req.(*ListDeadLettersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_ReplayDeadLetter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplayDeadLetterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).ReplayDeadLetter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_ReplayDeadLetter_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).ReplayDeadLetter(ctx, req.(*ReplayDeadLetterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_ListNotificationHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListNotificationHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).ListNotificationHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_ListNotificationHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).ListNotificationHistory(ctx, req.(*ListNotificationHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_StreamEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NotificationServiceServer).StreamEvents(m, &grpc.GenericServerStream[StreamEventsRequest, NotificationEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NotificationService_StreamEventsServer = grpc.ServerStreamingServer[NotificationEvent]
+
+func _NotificationService_GetProviderHealth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProviderHealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).GetProviderHealth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_GetProviderHealth_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).GetProviderHealth(ctx, req.(*GetProviderHealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_ScheduleNotification_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScheduleNotificationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).ScheduleNotification(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_ScheduleNotification_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).ScheduleNotification(ctx, req.(*ScheduleNotificationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NotificationService_CancelScheduledNotification_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelScheduledNotificationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NotificationServiceServer).CancelScheduledNotification(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NotificationService_CancelScheduledNotification_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NotificationServiceServer).CancelScheduledNotification(ctx, req.(*CancelScheduledNotificationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // NotificationService_ServiceDesc is the grpc.ServiceDesc for NotificationService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -161,7 +487,45 @@ var NotificationService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "NotifyDocumentExpiry",
 			Handler:    _NotificationService_NotifyDocumentExpiry_Handler,
 		},
+		{
+			MethodName: "NotifyDocumentDigest",
+			Handler:    _NotificationService_NotifyDocumentDigest_Handler,
+		},
+		{
+			MethodName: "SendNotification",
+			Handler:    _NotificationService_SendNotification_Handler,
+		},
+		{
+			MethodName: "ListDeadLetters",
+			Handler:    _NotificationService_ListDeadLetters_Handler,
+		},
+		{
+			MethodName: "ReplayDeadLetter",
+			Handler:    _NotificationService_ReplayDeadLetter_Handler,
+		},
+		{
+			MethodName: "ListNotificationHistory",
+			Handler:    _NotificationService_ListNotificationHistory_Handler,
+		},
+		{
+			MethodName: "GetProviderHealth",
+			Handler:    _NotificationService_GetProviderHealth_Handler,
+		},
+		{
+			MethodName: "ScheduleNotification",
+			Handler:    _NotificationService_ScheduleNotification_Handler,
+		},
+		{
+			MethodName: "CancelScheduledNotification",
+			Handler:    _NotificationService_CancelScheduledNotification_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamEvents",
+			Handler:       _NotificationService_StreamEvents_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "notification/v1/notification.proto",
 }